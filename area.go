@@ -0,0 +1,227 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"math"
+	"strings"
+
+	"kreklow.us/go/t38c/internal/num"
+)
+
+// minPolygonPoints is the minimum number of distinct points in a
+// polygon.
+const minPolygonPoints = 3
+
+// Area is a region used by spatial commands such as TEST. AreaArgs
+// validates the area and returns it as command arguments, for example
+// BOUNDS minlat minlon maxlat maxlon.
+type Area interface {
+	AreaArgs() ([]string, error)
+}
+
+// AreaArgs implements Area.
+func (p Point) AreaArgs() ([]string, error) {
+	return p.ObjectArgs()
+}
+
+// AreaArgs implements Area.
+func (b Bounds) AreaArgs() ([]string, error) {
+	return b.ObjectArgs()
+}
+
+// AreaArgs implements Area.
+func (h Hash) AreaArgs() ([]string, error) {
+	return h.ObjectArgs()
+}
+
+// AreaArgs implements Area.
+func (g GeoJSON) AreaArgs() ([]string, error) {
+	return g.ObjectArgs()
+}
+
+// Circle is the area within a distance in meters of a point.
+type Circle struct {
+	Center Point
+	Meters float64
+}
+
+// AreaArgs implements Area.
+func (c Circle) AreaArgs() ([]string, error) {
+	args, err := c.Center.ObjectArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	if math.IsNaN(c.Meters) || c.Meters < 0 {
+		return nil, newError(nil, "invalid area: circle radius must not be negative")
+	}
+
+	return []string{"CIRCLE", args[1], args[2], num.Format(c.Meters)}, nil
+}
+
+// Ref is the area of an object already stored in the database.
+type Ref struct {
+	Key string
+	ID  string
+}
+
+// AreaArgs implements Area.
+func (r Ref) AreaArgs() ([]string, error) {
+	if r.Key == "" || r.ID == "" {
+		return nil, newError(nil, "invalid area: key and id must not be empty")
+	}
+
+	return []string{"GET", r.Key, r.ID}, nil
+}
+
+// Polygon is a simple polygon defined by the points of its boundary.
+// The last point may repeat the first to close the ring.
+type Polygon []Point
+
+// ObjectArgs implements Object.
+func (p Polygon) ObjectArgs() ([]string, error) {
+	err := p.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{"OBJECT", p.geoJSON()}, nil
+}
+
+// AreaArgs implements Area.
+func (p Polygon) AreaArgs() ([]string, error) {
+	return p.ObjectArgs()
+}
+
+// check validates the points of the polygon and ensures its edges do
+// not cross each other.
+func (p Polygon) check() error {
+	ring := p.ring()
+	if len(ring) < minPolygonPoints {
+		return newError(nil, "invalid object: polygon requires at least 3 points")
+	}
+
+	for _, pt := range ring {
+		err := checkLatLon(pt.Lat, pt.Lon)
+		if err != nil {
+			return err
+		}
+	}
+
+	n := len(ring)
+	for i := range n {
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue // adjacent through the closing edge
+			}
+
+			if segmentsIntersect(ring[i], ring[(i+1)%n], ring[j], ring[(j+1)%n]) {
+				return newError(nil, "invalid object: polygon edges intersect")
+			}
+		}
+	}
+
+	return nil
+}
+
+// ring returns the points of the polygon without a closing point.
+func (p Polygon) ring() []Point {
+	if len(p) > 1 && p[0] == p[len(p)-1] {
+		return p[:len(p)-1]
+	}
+
+	return p
+}
+
+// geoJSON returns the polygon as a closed GeoJSON Polygon.
+func (p Polygon) geoJSON() string {
+	ring := p.ring()
+
+	var b strings.Builder
+
+	b.WriteString(`{"type":"Polygon","coordinates":[[`)
+
+	for i := range len(ring) + 1 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		pt := ring[i%len(ring)]
+
+		b.WriteByte('[')
+		b.WriteString(num.Format(pt.Lon))
+		b.WriteByte(',')
+		b.WriteString(num.Format(pt.Lat))
+		b.WriteByte(']')
+	}
+
+	b.WriteString(`]]}`)
+
+	return b.String()
+}
+
+// TestIntersects uses the TEST command to report whether area a
+// intersects area b.
+func (db *Database) TestIntersects(a Area, b Area) (ok bool, err error) {
+	return db.test(a, "INTERSECTS", b)
+}
+
+// TestWithin uses the TEST command to report whether area a is fully
+// contained within area b.
+func (db *Database) TestWithin(a Area, b Area) (ok bool, err error) {
+	return db.test(a, "WITHIN", b)
+}
+
+// test runs the TEST command.
+func (db *Database) test(a Area, op string, b Area) (ok bool, err error) {
+	if db.tr == nil {
+		return false, errUninitialized
+	}
+
+	if a == nil || b == nil {
+		return false, errArgs
+	}
+
+	aargs, err := a.AreaArgs()
+	if err != nil {
+		return false, err
+	}
+
+	bargs, err := b.AreaArgs()
+	if err != nil {
+		return false, err
+	}
+
+	r, err := db.runcmd("TEST", append(append(aargs, op), bargs...)...)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.DecodeResult(&ok)
+	if err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/resp"
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// testSquare is a 10 by 10 degree square polygon.
+//
+//nolint:gochecknoglobals // shared test fixture
+var testSquare = t38c.Polygon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}, {Lat: 10, Lon: 10}, {Lat: 10, Lon: 0}}
+
+// TestAreaArgs tests rendering valid areas.
+func TestAreaArgs(t *testing.T) {
+	tests := map[string]struct {
+		area t38c.Area
+		exp  string
+	}{
+		"Point":   {t38c.Point{Lat: 1, Lon: 2}, "POINT 1 2"},
+		"Bounds":  {t38c.Bounds{MinLat: 1, MinLon: 2, MaxLat: 3, MaxLon: 4}, "BOUNDS 1 2 3 4"},
+		"Hash":    {t38c.Hash("9t"), "HASH 9t"},
+		"GeoJSON": {t38c.GeoJSON(`{"type":"Point","coordinates":[2,1]}`), `OBJECT {"type":"Point","coordinates":[2,1]}`},
+		"Circle":  {t38c.Circle{Center: t38c.Point{Lat: 1, Lon: 2}, Meters: 500}, "CIRCLE 1 2 500"},
+		"Ref":     {t38c.Ref{Key: "fences", ID: "f1"}, "GET fences f1"},
+		"Polygon": {testSquare, `OBJECT {"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}`},
+		"Closed": {
+			append(testSquare[:4:4], t38c.Point{}),
+			`OBJECT {"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}`,
+		},
+	}
+
+	for name, tc := range tests {
+		args, err := tc.area.AreaArgs()
+		if err != nil {
+			tErrorStr(t, name, "nil", err)
+
+			continue
+		}
+
+		act := strings.Join(args, " ")
+		if act != tc.exp {
+			tErrorStr(t, name, tc.exp, act)
+		}
+	}
+}
+
+// TestAreaErrors tests validating invalid areas.
+func TestAreaErrors(t *testing.T) {
+	tests := map[string]struct {
+		area t38c.Area
+		exp  string
+	}{
+		"Circle Center": {t38c.Circle{Center: t38c.Point{Lat: 91}}, "invalid object: latitude 91 out of range"},
+		"Circle Radius": {t38c.Circle{Meters: -1}, "invalid area: circle radius must not be negative"},
+		"Ref":           {t38c.Ref{Key: "fences"}, "invalid area: key and id must not be empty"},
+		"Polygon Short": {t38c.Polygon{{}, {Lat: 1}, {}}, "invalid object: polygon requires at least 3 points"},
+		"Polygon Range": {t38c.Polygon{{}, {Lat: 1}, {Lon: 200}}, "invalid object: longitude 200 out of range"},
+		"Polygon Cross": {
+			t38c.Polygon{{Lat: 0, Lon: 0}, {Lat: 10, Lon: 10}, {Lat: 0, Lon: 10}, {Lat: 10, Lon: 0}},
+			"invalid object: polygon edges intersect",
+		},
+	}
+
+	for name, tc := range tests {
+		_, err := tc.area.AreaArgs()
+		if err == nil {
+			tErrorStr(t, name, "error", "nil")
+
+			continue
+		}
+
+		if err.Error() != tc.exp {
+			tErrorStr(t, name, tc.exp, err)
+		}
+	}
+}
+
+// TestAreaTest tests the TEST command.
+func TestAreaTest(t *testing.T) {
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	srv.HandleFunc("TEST", func(c *resp.Conn, _ []resp.Value) bool {
+		return c.WriteSimpleString(`{"ok":true,"result":true}`) == nil
+	})
+
+	pt := t38c.Point{Lat: 5, Lon: 5}
+
+	for op, f := range map[string]func(a, b t38c.Area) (bool, error){
+		"INTERSECTS": db.TestIntersects,
+		"WITHIN":     db.TestWithin,
+	} {
+		srv.ResetCommands()
+
+		ok, err := f(pt, t38c.Ref{Key: "fences", ID: "f1"})
+		if err != nil {
+			tErrorStr(t, op, "nil", err)
+		}
+
+		if !ok {
+			tErrorVal(t, op, true, ok)
+		}
+
+		tCommands(t, op, "TEST POINT 5 5 "+op+" GET fences f1")
+	}
+
+	errs := map[string]struct {
+		a, b t38c.Area
+		exp  string
+	}{
+		"Nil":    {nil, pt, "invalid arguments"},
+		"A":      {t38c.Point{Lat: 91}, pt, "invalid object: latitude 91 out of range"},
+		"B":      {pt, t38c.Ref{}, "invalid area: key and id must not be empty"},
+		"Server": {pt, pt, "received error: " + mock.TestOkFalse},
+	}
+
+	srv.HandleFunc("TEST", srv.ReturnOkFalse)
+
+	for name, tc := range errs {
+		_, err := db.TestIntersects(tc.a, tc.b)
+		if err == nil || err.Error() != tc.exp {
+			tErrorStr(t, name, tc.exp, err)
+		}
+	}
+
+	srv.HandleFunc("TEST", srv.ReturnOkTrue)
+
+	_, err = db.TestWithin(pt, pt)
+	if err == nil || err.Error() != "error decoding result: no result" {
+		tErrorStr(t, "No Result", "error decoding result: no result", err)
+	}
+
+	_, err = new(t38c.Database).TestWithin(pt, pt)
+	if err == nil || err.Error() != "database not initialized" {
+		tErrorStr(t, "Uninitialized", "database not initialized", err)
+	}
+}
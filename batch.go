@@ -0,0 +1,194 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"errors"
+	"time"
+)
+
+// Pipeliner is an optional interface implemented by a Transport which
+// can send several commands in a single round trip. Each element of
+// cmds holds a command name followed by its arguments, and the reply to
+// cmds[i] is unmarshaled into rs[i].
+type Pipeliner interface {
+	Pipeline(rs []*Response, cmds [][]string) error
+}
+
+// SetItem is an object saved by SetMany.
+type SetItem struct {
+	ID      string
+	Object  Object
+	Options *SetOptions
+}
+
+// SetMany saves several objects to the database. The SET commands are
+// sent in a single pipeline if the Transport implements Pipeliner, and
+// one at a time otherwise. The batch is retried as a whole according to
+// the RetryPolicy and is reported to any Instrument as one SET command.
+// NX and XX options are not supported.
+//
+// If an error is returned, some of the objects may have been saved.
+func (db *Database) SetMany(key string, items []SetItem) (err error) {
+	if db.tr == nil {
+		return errUninitialized
+	}
+
+	if len(items) == 0 {
+		return errArgs
+	}
+
+	cmds := make([][]string, len(items))
+
+	for i, it := range items {
+		if it.Object == nil {
+			return errArgs
+		}
+
+		opts := it.Options
+		if opts == nil {
+			opts = new(SetOptions)
+		}
+
+		if opts.NX || opts.XX {
+			return newError(nil, "invalid options: NX and XX are not supported by SetMany")
+		}
+
+		cmdargs, err := opts.args(key, it.ID)
+		if err != nil {
+			return err
+		}
+
+		objargs, err := it.Object.ObjectArgs()
+		if err != nil {
+			return newErrorf(err, "error setting %q", it.ID)
+		}
+
+		cmds[i] = append(append([]string{"SET"}, cmdargs...), objargs...)
+	}
+
+	return db.pipeline(key, cmds, func(rs []*Response) error {
+		for i, r := range rs {
+			if !r.Ok {
+				return newErrorf(responseError(r.Err), "error setting %q", items[i].ID)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TTLMany returns the timeouts of several objects in key, or NoExpiry
+// for objects which do not expire. Objects which do not exist, such as
+// those which have expired since they were listed, are left out of the
+// map. The TTL commands are sent in a single pipeline if the Transport
+// implements Pipeliner, and one at a time otherwise, and are reported
+// to any Instrument as one TTL command.
+func (db *Database) TTLMany(key string, ids []string) (map[string]time.Duration, error) {
+	if db.tr == nil {
+		return nil, errUninitialized
+	}
+
+	if len(ids) == 0 {
+		return nil, errArgs
+	}
+
+	cmds := make([][]string, len(ids))
+	for i, id := range ids {
+		cmds[i] = []string{"TTL", key, id}
+	}
+
+	var ttls map[string]time.Duration
+
+	err := db.pipeline(key, cmds, func(rs []*Response) error {
+		ttls = make(map[string]time.Duration, len(rs))
+
+		for i, r := range rs {
+			if r.Ok {
+				ttls[ids[i]] = r.TTLDuration()
+
+				continue
+			}
+
+			err := responseError(r.Err)
+			if !errors.Is(err, ErrIDNotFound) && !errors.Is(err, ErrKeyNotFound) {
+				return newErrorf(err, "error reading ttl of %q", ids[i])
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ttls, nil
+}
+
+// pipeline runs a batch of commands of the same kind, retrying
+// according to the configured RetryPolicy and subject to the circuit
+// breaker. check is called with the replies of each attempt which
+// reached the server.
+func (db *Database) pipeline(key string, cmds [][]string, check func([]*Response) error) error {
+	e := &CommandEvent{
+		Command: cmds[0][0],
+		Key:     key,
+		Start:   time.Now(),
+	}
+
+	return db.attempt(e, cmds[0][1:], func() error {
+		rs, err := db.dopipeline(cmds)
+		if err != nil {
+			return err
+		}
+
+		return check(rs)
+	})
+}
+
+// dopipeline sends a single batch of commands to the database and
+// returns the replies.
+func (db *Database) dopipeline(cmds [][]string) ([]*Response, error) {
+	rs := make([]*Response, len(cmds))
+	for i := range rs {
+		rs[i] = new(Response)
+	}
+
+	if p, ok := db.tr.(Pipeliner); ok {
+		err := p.Pipeline(rs, cmds)
+		if err != nil {
+			return nil, newError(err, "database error")
+		}
+
+		return rs, nil
+	}
+
+	for i, c := range cmds {
+		err := db.tr.Do(rs[i], c[0], c[1:]...)
+		if err != nil {
+			return nil, newError(err, "database error")
+		}
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// testItems are the objects saved by the SetMany tests.
+var testItems = []t38c.SetItem{ //nolint:gochecknoglobals // shared test data
+	{ID: "a", Object: t38c.Point{Lat: 1, Lon: 2}},
+	{ID: "b", Object: t38c.String("x"), Options: &t38c.SetOptions{
+		Fields: []t38c.Field{{Name: "speed", Value: 5}},
+		Expire: time.Minute,
+	}},
+}
+
+// TestSetMany tests saving objects in a pipeline.
+func TestSetMany(t *testing.T) {
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	srv.HandleFunc("SET", srv.ReturnOkTrue)
+	srv.ResetCommands()
+
+	err = db.SetMany("fleet", testItems)
+	if err != nil {
+		tFatalErr(t, "SetMany", err)
+	}
+
+	tCommands(t, "SetMany", "SET fleet a POINT 1 2", "SET fleet b FIELD speed 5 EX 60 STRING x")
+
+	cmds := srv.Commands()
+	if len(cmds) == 2 && cmds[0].Conn != cmds[1].Conn {
+		tErrorVal(t, "Conn", cmds[0].Conn, cmds[1].Conn)
+	}
+
+	srv.HandleFunc("SET", srv.ReturnOkFalse)
+
+	errs := map[string][]t38c.SetItem{
+		`error setting "a": received error: ` + mock.TestOkFalse: testItems,
+		"invalid arguments": {{ID: "a"}},
+		"invalid options: NX and XX are not supported by SetMany":      {{ID: "a", Object: t38c.String("x"), Options: &t38c.SetOptions{NX: true}}},
+		`error setting "a": invalid object: latitude 100 out of range`: {{ID: "a", Object: t38c.Point{Lat: 100}}},
+		`invalid options: invalid field ""`:                            {{ID: "a", Object: t38c.String("x"), Options: &t38c.SetOptions{Fields: []t38c.Field{{}}}}},
+	}
+
+	for exp, items := range errs {
+		err = db.SetMany("fleet", items)
+		if err == nil || err.Error() != exp {
+			tErrorStr(t, "SetMany", exp, err)
+		}
+	}
+
+	err = db.SetMany("fleet", nil)
+	if err == nil || err.Error() != "invalid arguments" {
+		tErrorStr(t, "Empty", "invalid arguments", err)
+	}
+
+	err = new(t38c.Database).SetMany("fleet", testItems)
+	if err == nil || err.Error() != "database not initialized" {
+		tErrorStr(t, "Uninitialized", "database not initialized", err)
+	}
+}
+
+// TestSetManyTransport tests SetMany with a Transport which does not
+// support pipelining.
+func TestSetManyTransport(t *testing.T) {
+	var cmds []string
+
+	stub := t38c.TransportFunc(func(r *t38c.Response, cmd string, args ...string) error {
+		cmds = append(cmds, cmd+" "+strings.Join(args, " "))
+		if len(cmds) == 2 {
+			return io.EOF
+		}
+
+		return r.UnmarshalText([]byte(`{"ok":true}`))
+	})
+
+	db, err := t38c.NewDatabase(stub, t38c.WithRetry(retryPolicy()))
+	if err != nil {
+		tFatalErr(t, "NewDatabase", err)
+	}
+
+	err = db.SetMany("fleet", testItems)
+	if err != nil {
+		tFatalErr(t, "SetMany", err)
+	}
+
+	if len(cmds) != 4 {
+		tErrorVal(t, "Commands", 4, len(cmds))
+	}
+}
+
+// TestTTLMany tests reading timeouts in a pipeline.
+func TestTTLMany(t *testing.T) {
+	ssrv := streamServer(t, 0)
+
+	db, err := t38c.Connect(ssrv.Addr, ssrv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	err = db.Set("fleet", "a", "EX", "60", "POINT", "1", "2")
+	if err != nil {
+		tFatalErr(t, "Set", err)
+	}
+
+	err = db.Set("fleet", "b", "STRING", "x")
+	if err != nil {
+		tFatalErr(t, "Set", err)
+	}
+
+	ttls, err := db.TTLMany("fleet", []string{"a", "b", "missing"})
+	if err != nil {
+		tFatalErr(t, "TTLMany", err)
+	}
+
+	if len(ttls) != 2 || ttls["a"] <= 59*time.Second || ttls["b"] != t38c.NoExpiry {
+		tErrorStr(t, "TTLMany", "a about 60s and b NoExpiry", fmt.Sprint(ttls))
+	}
+
+	ttls, err = db.TTLMany("dropped", []string{"a"})
+	if err != nil || len(ttls) != 0 {
+		tErrorStr(t, "Dropped", "no timeouts", fmt.Sprint(ttls, err))
+	}
+
+	_, err = db.TTL("fleet", "missing")
+	if !errors.Is(err, t38c.ErrIDNotFound) {
+		tErrorStr(t, "TTL", t38c.ErrIDNotFound, err)
+	}
+
+	_, err = db.TTL("dropped", "a")
+	if !errors.Is(err, t38c.ErrKeyNotFound) {
+		tErrorStr(t, "TTL", t38c.ErrKeyNotFound, err)
+	}
+
+	stub := t38c.TransportFunc(func(r *t38c.Response, _ string, _ ...string) error {
+		return r.UnmarshalText([]byte(`{"ok":false,"err":"boom"}`))
+	})
+
+	db, err = t38c.NewDatabase(stub)
+	if err != nil {
+		tFatalErr(t, "NewDatabase", err)
+	}
+
+	_, err = db.TTLMany("fleet", []string{"a"})
+
+	exp := `error reading ttl of "a": received error: boom`
+	if err == nil || err.Error() != exp {
+		tErrorStr(t, "TTLMany", exp, err)
+	}
+
+	_, err = db.TTLMany("fleet", nil)
+	if err == nil || err.Error() != "invalid arguments" {
+		tErrorStr(t, "Empty", "invalid arguments", err)
+	}
+}
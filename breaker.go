@@ -0,0 +1,146 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the server while the
+// circuit breaker is open.
+var ErrCircuitOpen = newError(nil, "circuit breaker open")
+
+// Circuit breaker states.
+const (
+	breakerClosed = iota
+	breakerOpen
+	breakerProbing
+)
+
+// breaker is a circuit breaker which stops sending commands to the
+// server after a number of consecutive transient failures.
+type breaker struct {
+	mu       sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+
+	threshold int
+	cooldown  time.Duration
+	probe     func() error
+}
+
+// WithCircuitBreaker returns an Option enabling a circuit breaker. The
+// breaker opens after threshold consecutive network failures, causing
+// commands to fail immediately with ErrCircuitOpen. Once cooldown has
+// passed, the next command sends a PING to the server and the breaker
+// closes again if it succeeds. Failures are classified by the
+// Retryable function of the RetryPolicy set with WithRetry, or by
+// IsRetryable if there is none. Connect and NewDatabase return an
+// error if threshold is less than 1.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(db *Database) {
+		db.breaker = &breaker{
+			threshold: threshold,
+			cooldown:  cooldown,
+			probe: func() error {
+				return db.tr.Do(nil, "PING")
+			},
+		}
+	}
+}
+
+// check validates the breaker's configuration.
+func (b *breaker) check() error {
+	if b != nil && b.threshold < 1 {
+		return newError(nil, "invalid circuit breaker threshold")
+	}
+
+	return nil
+}
+
+// allow returns ErrCircuitOpen if a command should not be sent to the
+// server. When the cooldown has expired, allow probes the server and
+// closes the breaker if the probe succeeds.
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+
+	switch {
+	case b.state == breakerClosed:
+		b.mu.Unlock()
+
+		return nil
+	case b.state == breakerProbing, time.Since(b.openedAt) < b.cooldown:
+		b.mu.Unlock()
+
+		return ErrCircuitOpen
+	}
+
+	b.state = breakerProbing
+	b.mu.Unlock()
+
+	err := b.probe()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+
+		return ErrCircuitOpen
+	}
+
+	b.state = breakerClosed
+	b.failures = 0
+
+	return nil
+}
+
+// record updates the breaker with the result of a command, counting it
+// as a failure if it failed with a transient error.
+func (b *breaker) record(transient bool) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !transient {
+		b.failures = 0
+
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold && b.state == breakerClosed {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
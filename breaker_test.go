@@ -0,0 +1,170 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tidwall/resp"
+	"kreklow.us/go/t38c"
+)
+
+// TestBreaker tests the circuit breaker.
+func TestBreaker(t *testing.T) {
+	var calls atomic.Int64
+
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1,
+		t38c.WithCircuitBreaker(2, 50*time.Millisecond))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	srv.HandleFunc("GET", func(c *resp.Conn, args []resp.Value) bool {
+		calls.Add(1)
+
+		return srv.ReturnDrop(c, args)
+	})
+
+	for range 2 {
+		_, err = db.Get("test", "obj1")
+		if err == nil || errors.Is(err, t38c.ErrCircuitOpen) {
+			tErrorStr(t, "Get", "network error", err)
+		}
+	}
+
+	_, err = db.Get("test", "obj1")
+	if !errors.Is(err, t38c.ErrCircuitOpen) {
+		tErrorStr(t, "Open", t38c.ErrCircuitOpen, err)
+	}
+
+	if calls.Load() != 2 {
+		tErrorVal(t, "Calls", 2, calls.Load())
+	}
+
+	srv.HandleFunc("PING", srv.ReturnDrop)
+	time.Sleep(60 * time.Millisecond)
+
+	_, err = db.Get("test", "obj1")
+	if !errors.Is(err, t38c.ErrCircuitOpen) {
+		tErrorStr(t, "Failed Probe", t38c.ErrCircuitOpen, err)
+	}
+
+	srv.HandleFunc("PING", nil)
+	srv.HandleFunc("GET", srv.ReturnOkTrue)
+	time.Sleep(60 * time.Millisecond)
+
+	_, err = db.Get("test", "obj1")
+	if err != nil {
+		tFatalErr(t, "Closed", err)
+	}
+
+	if calls.Load() != 2 {
+		tErrorVal(t, "Calls", 2, calls.Load())
+	}
+}
+
+// TestBreakerThreshold tests rejecting an invalid threshold.
+func TestBreakerThreshold(t *testing.T) {
+	tr := t38c.TransportFunc(func(_ *t38c.Response, _ string, _ ...string) error { return nil })
+
+	for _, n := range []int{0, -1} {
+		_, err := t38c.NewDatabase(tr, t38c.WithCircuitBreaker(n, time.Minute))
+		if err == nil || err.Error() != "invalid circuit breaker threshold" {
+			tErrorStr(t, "NewDatabase", "invalid circuit breaker threshold", err)
+		}
+	}
+}
+
+// TestBreakerClassify tests that failures are classified by the retry
+// policy and that errors from an ObjectFunc are not counted.
+func TestBreakerClassify(t *testing.T) {
+	errBusy := errors.New("busy")
+
+	var calls atomic.Int64
+
+	tr := t38c.TransportFunc(func(r *t38c.Response, cmd string, _ ...string) error {
+		calls.Add(1)
+
+		switch cmd {
+		case "SCAN":
+			return r.UnmarshalText([]byte(`{"ok":true,"objects":[{"id":"a","object":"x"}]}`))
+		case "GET":
+			return errBusy
+		default:
+			return io.EOF
+		}
+	})
+
+	db, err := t38c.NewDatabase(tr,
+		t38c.WithRetry(t38c.RetryPolicy{MaxAttempts: 1, Retryable: func(err error) bool { return errors.Is(err, errBusy) }}),
+		t38c.WithCircuitBreaker(2, time.Minute))
+	if err != nil {
+		tFatalErr(t, "NewDatabase", err)
+	}
+
+	// io.EOF is not transient under the policy, so it never opens
+	for range 3 {
+		_, err = db.Search("fleet")
+		if !errors.Is(err, io.EOF) {
+			tErrorStr(t, "Search", io.EOF, err)
+		}
+	}
+
+	errStop := errors.New("stop")
+
+	_, err = db.Get("fleet", "a")
+	if !errors.Is(err, errBusy) {
+		tErrorStr(t, "Get", errBusy, err)
+	}
+
+	_, err = db.ScanStream("fleet", nil, func(_ *t38c.Response, _ []byte) error {
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		tErrorStr(t, "ScanStream", errStop, err)
+	}
+
+	_, err = db.Get("fleet", "a")
+	if !errors.Is(err, errBusy) {
+		tErrorStr(t, "Get", errBusy, err)
+	}
+
+	calls.Store(0)
+
+	_, err = db.Get("fleet", "a")
+	if !errors.Is(err, t38c.ErrCircuitOpen) {
+		tErrorStr(t, "Open", t38c.ErrCircuitOpen, err)
+	}
+
+	if calls.Load() != 0 {
+		tErrorVal(t, "Calls", 0, calls.Load())
+	}
+}
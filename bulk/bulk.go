@@ -0,0 +1,271 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package bulk loads GeoJSON, newline-delimited GeoJSON and CSV data
+// into a Tile38 collection, and exports collections as GeoJSON.
+//
+// Records are streamed from the input and saved in batches with
+// Database.SetMany, so the input does not need to fit in memory. An
+// interrupted load can be resumed by setting Loader.Skip to the number
+// of records reported by the last Progress.
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"kreklow.us/go/t38c"
+)
+
+// Format is the format of the input to a Loader.
+type Format int
+
+// Input formats.
+const (
+	// GeoJSON is a GeoJSON FeatureCollection.
+	GeoJSON Format = iota
+
+	// NDJSON is a stream of GeoJSON Features, one per line.
+	NDJSON
+
+	// CSV is comma-separated values with a header row, holding points
+	// in latitude and longitude columns.
+	CSV
+)
+
+// defaultBatchSize is the number of objects saved per batch when
+// Loader.BatchSize is not set.
+const defaultBatchSize = 100
+
+// Errors returned when reading records.
+var (
+	errNoID       = errors.New("missing id")
+	errNoGeometry = errors.New("missing geometry")
+)
+
+// ParseFormat returns the Format named by s, which is one of "geojson",
+// "ndjson" or "csv" in any case.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "geojson", "json":
+		return GeoJSON, nil
+	case "ndjson", "geojsonl", "jsonl":
+		return NDJSON, nil
+	case "csv":
+		return CSV, nil
+	}
+
+	return 0, fmt.Errorf("unknown format %q", s)
+}
+
+// Setter saves batches of objects. It is implemented by *t38c.Database.
+type Setter interface {
+	SetMany(key string, items []t38c.SetItem) error
+}
+
+// Progress reports the state of a load.
+type Progress struct {
+	// Records is the number of input records processed, including
+	// those skipped. Passing it as Loader.Skip resumes the load after
+	// the last saved batch.
+	Records int64
+
+	// Loaded is the number of objects saved by this load.
+	Loaded int64
+}
+
+// Loader loads records from an input into a collection.
+type Loader struct {
+	// DB saves the objects.
+	DB Setter
+
+	// Key is the collection the objects are saved to.
+	Key string
+
+	// Format is the format of the input.
+	Format Format
+
+	// IDField is the property (GeoJSON) or column (CSV) holding the
+	// object id. If empty, the id member of each GeoJSON Feature or the
+	// "id" CSV column is used.
+	IDField string
+
+	// LatField and LonField are the CSV columns holding coordinates.
+	// They default to "lat" and "lon".
+	LatField string
+	LonField string
+
+	// Fields are the properties or columns saved as fields. If nil,
+	// every numeric property or column is saved.
+	Fields []string
+
+	// Expire sets a timeout on each object when greater than zero.
+	Expire time.Duration
+
+	// BatchSize is the number of objects saved per batch, 100 if not
+	// set.
+	BatchSize int
+
+	// Skip is the number of input records to skip before loading.
+	Skip int64
+
+	// Progress, if not nil, is called after each batch is saved.
+	Progress func(Progress)
+}
+
+// record is an object read from the input.
+type record struct {
+	id     string
+	obj    t38c.Object
+	fields []t38c.Field
+}
+
+// recordReader reads records from an input.
+type recordReader interface {
+	// start reads anything preceding the first record.
+	start() error
+
+	// next returns the next record, or io.EOF at the end of the input.
+	next() (*record, error)
+}
+
+// Load reads records from r and saves them to the collection. It
+// returns the progress made, which on error reflects the last batch
+// successfully saved.
+func (l *Loader) Load(r io.Reader) (Progress, error) {
+	var (
+		p  Progress
+		rd recordReader
+	)
+
+	if l.DB == nil || l.Key == "" {
+		return p, errors.New("loader requires DB and Key")
+	}
+
+	switch l.Format {
+	case GeoJSON:
+		rd = newFeatureReader(l, r, false)
+	case NDJSON:
+		rd = newFeatureReader(l, r, true)
+	case CSV:
+		rd = newCSVReader(l, r)
+	default:
+		return p, fmt.Errorf("unknown format %d", l.Format)
+	}
+
+	err := rd.start()
+	if err != nil {
+		return p, err
+	}
+
+	size := l.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	batch := make([]t38c.SetItem, 0, size)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := l.DB.SetMany(l.Key, batch)
+		if err != nil {
+			return fmt.Errorf("saving records %d to %d: %w",
+				p.Records+1, p.Records+int64(len(batch)), err)
+		}
+
+		p.Records += int64(len(batch))
+		p.Loaded += int64(len(batch))
+		batch = batch[:0]
+
+		if l.Progress != nil {
+			l.Progress(p)
+		}
+
+		return nil
+	}
+
+	for {
+		rec, err := rd.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return p, fmt.Errorf("record %d: %w", p.Records+int64(len(batch))+1, err)
+		}
+
+		if p.Records < l.Skip {
+			p.Records++
+
+			continue
+		}
+
+		opts := &t38c.SetOptions{
+			Fields: rec.fields,
+			Expire: l.Expire,
+		}
+
+		batch = append(batch, t38c.SetItem{ID: rec.id, Object: rec.obj, Options: opts})
+
+		if len(batch) >= size {
+			err = flush()
+			if err != nil {
+				return p, err
+			}
+		}
+	}
+
+	return p, flush()
+}
+
+// wantField reports whether the named property or column is saved as
+// a field.
+func (l *Loader) wantField(name string) bool {
+	if name == l.IDField {
+		return false
+	}
+
+	if l.Fields == nil {
+		return true
+	}
+
+	for _, f := range l.Fields {
+		if f == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// finite reports whether v is neither NaN nor infinite, as required of
+// a field value.
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,244 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bulk_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/bulk"
+	"kreklow.us/go/t38c/t38ctest"
+)
+
+const testCollection = `{
+  "type": "FeatureCollection",
+  "name": "trucks",
+  "features": [
+    {"type": "Feature", "id": "t1", "properties": {"speed": 10, "name": "one"},
+     "geometry": {"type": "Point", "coordinates": [-112.1, 33.1]}},
+    {"type": "Feature", "id": 2, "properties": {"speed": 20, "code": "t2"},
+     "geometry": {"type": "Point", "coordinates": [-112.2, 33.2]}},
+    {"type": "Feature", "id": "t3", "properties": {"speed": 30},
+     "geometry": {"type": "Point", "coordinates": [-112.3, 33.3]}}
+  ],
+  "crs": {}
+}`
+
+const testNDJSON = `{"type":"Feature","properties":{"id":"a","speed":1},"geometry":{"type":"Point","coordinates":[1,2]}}
+{"type":"Feature","properties":{"id":"b","speed":2},"geometry":{"type":"Point","coordinates":[3,4]}}
+`
+
+const testCSV = `id,lat,lon,speed,name
+a,33.1,-112.1,10,one
+b,33.2,-112.2,x,two
+c,33.3,-112.3,NaN,three
+d,33.4,-112.4,inf,four
+`
+
+// TestLoad tests loading each format into the emulator.
+func TestLoad(t *testing.T) {
+	tests := map[string]struct {
+		l     bulk.Loader
+		input string
+		ids   []string
+		speed []float64
+	}{
+		"GeoJSON": {bulk.Loader{Format: bulk.GeoJSON, BatchSize: 2}, testCollection,
+			[]string{"2", "t1", "t3"}, []float64{20, 10, 30}},
+		"NDJSON": {bulk.Loader{Format: bulk.NDJSON, IDField: "id"}, testNDJSON,
+			[]string{"a", "b"}, []float64{1, 2}},
+		"CSV": {bulk.Loader{Format: bulk.CSV, Fields: []string{"speed"}}, testCSV,
+			[]string{"a", "b", "c", "d"}, []float64{10, 0, 0, 0}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := t38ctest.NewDatabase(t)
+
+			var calls int
+
+			l := tc.l
+			l.DB = db
+			l.Key = "fleet"
+			l.Expire = time.Hour
+			l.Progress = func(bulk.Progress) { calls++ }
+
+			p, err := l.Load(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			n := int64(len(tc.ids))
+			if p.Records != n || p.Loaded != n || calls == 0 {
+				t.Errorf("Load: unexpected progress %+v after %d calls", p, calls)
+			}
+
+			r, err := db.Scan("fleet", "IDS")
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+
+			if strings.Join(r.IDs, ",") != strings.Join(tc.ids, ",") {
+				t.Errorf("Scan: expected %v, received %v", tc.ids, r.IDs)
+			}
+
+			for i, id := range tc.ids {
+				r, err := db.Get("fleet", id, "WITHFIELDS")
+				if err != nil {
+					t.Fatalf("Get: %v", err)
+				}
+
+				var speed float64
+				if idx, ok := r.FieldNames["speed"]; ok {
+					speed = r.FieldValues[idx]
+				}
+
+				if speed != tc.speed[i] {
+					t.Errorf("%s: expected speed %v, received %v", id, tc.speed[i], speed)
+				}
+
+				if len(r.FieldNames) > 1 {
+					t.Errorf("%s: unexpected fields %v", id, r.FieldNames)
+				}
+			}
+
+			ttl, err := db.TTL("fleet", tc.ids[0])
+			if err != nil || ttl <= 0 {
+				t.Errorf("TTL: expected expiration, received %v, %v", ttl, err)
+			}
+		})
+	}
+}
+
+// failSetter fails the nth call to SetMany.
+type failSetter struct {
+	db    *t38c.Database
+	n     int
+	calls int
+}
+
+// SetMany implements bulk.Setter.
+func (f *failSetter) SetMany(key string, items []t38c.SetItem) error {
+	f.calls++
+	if f.calls == f.n {
+		return errors.New("test failure")
+	}
+
+	return f.db.SetMany(key, items)
+}
+
+// TestResume tests resuming a failed load.
+func TestResume(t *testing.T) {
+	db := t38ctest.NewDatabase(t)
+
+	l := bulk.Loader{
+		DB:        &failSetter{db: db, n: 2},
+		Key:       "fleet",
+		BatchSize: 1,
+	}
+
+	p, err := l.Load(strings.NewReader(testCollection))
+
+	exp := "saving records 2 to 2: test failure"
+	if err == nil || err.Error() != exp {
+		t.Errorf("Load: expected %s, received %v", exp, err)
+	}
+
+	if p.Records != 1 || p.Loaded != 1 {
+		t.Errorf("Load: unexpected progress %+v", p)
+	}
+
+	l.Skip = p.Records
+
+	p, err = l.Load(strings.NewReader(testCollection))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if p.Records != 3 || p.Loaded != 2 {
+		t.Errorf("Load: unexpected progress %+v", p)
+	}
+
+	r, err := db.Scan("fleet", "COUNT")
+	if err != nil || r.Count != 3 {
+		t.Errorf("Scan: expected 3, received %v, %v", r, err)
+	}
+}
+
+// TestLoadErrors tests invalid input.
+func TestLoadErrors(t *testing.T) {
+	db := t38ctest.NewDatabase(t)
+
+	tests := map[string]struct {
+		f     bulk.Format
+		input string
+	}{
+		"input is not a FeatureCollection":      {bulk.GeoJSON, `[]`},
+		"input has no features":                 {bulk.GeoJSON, `{"type":"FeatureCollection"}`},
+		"features is not an array":              {bulk.GeoJSON, `{"features":{}}`},
+		`record 1: type "Point" is not Feature`: {bulk.NDJSON, `{"type":"Point"}`},
+		"record 1: missing geometry":            {bulk.NDJSON, `{"type":"Feature","id":"a","geometry":null}`},
+		"record 2: missing id":                  {bulk.NDJSON, testNDJSON[:strings.Index(testNDJSON, "\n")] + "\n" + `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]}}`},
+		`missing column "lon"`:                  {bulk.CSV, "id,lat\n"},
+		`record 1: invalid latitude "x"`:        {bulk.CSV, "id,lat,lon\na,x,1\n"},
+		`record 1: invalid longitude "x"`:       {bulk.CSV, "id,lat,lon\na,1,x\n"},
+		`record 1: invalid latitude "NaN"`:      {bulk.CSV, "id,lat,lon\na,NaN,1\n"},
+		"record 1: missing id":                  {bulk.CSV, "id,lat,lon\n,1,1\n"},
+		"unknown format 9":                      {9, ""},
+	}
+
+	for exp, tc := range tests {
+		l := bulk.Loader{DB: db, Key: "fleet", Format: tc.f, IDField: ""}
+		if tc.f == bulk.NDJSON && strings.Contains(tc.input, "properties") {
+			l.IDField = "id"
+		}
+
+		_, err := l.Load(strings.NewReader(tc.input))
+		if err == nil || err.Error() != exp {
+			t.Errorf("expected %s, received %v", exp, err)
+		}
+	}
+
+	_, err := new(bulk.Loader).Load(strings.NewReader(""))
+	if err == nil {
+		t.Error("expected error, received nil")
+	}
+}
+
+// TestParseFormat tests parsing format names.
+func TestParseFormat(t *testing.T) {
+	for s, exp := range map[string]bulk.Format{"GeoJSON": bulk.GeoJSON, "ndjson": bulk.NDJSON, "csv": bulk.CSV} {
+		f, err := bulk.ParseFormat(s)
+		if err != nil || f != exp {
+			t.Errorf("%s: expected %v, received %v, %v", s, exp, f, err)
+		}
+	}
+
+	_, err := bulk.ParseFormat("xml")
+	if err == nil {
+		t.Error("expected error, received nil")
+	}
+}
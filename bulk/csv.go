@@ -0,0 +1,144 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bulk
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"kreklow.us/go/t38c"
+)
+
+// csvReader reads points from CSV records.
+type csvReader struct {
+	l      *Loader
+	r      *csv.Reader
+	header []string
+	id     int
+	lat    int
+	lon    int
+}
+
+// newCSVReader returns a csvReader.
+func newCSVReader(l *Loader, r io.Reader) *csvReader {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	return &csvReader{
+		l: l,
+		r: cr,
+	}
+}
+
+// next implements recordReader.
+func (c *csvReader) next() (*record, error) {
+	row, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if row[c.id] == "" {
+		return nil, errNoID
+	}
+
+	lat, ok := parseNumber(row[c.lat])
+	if !ok {
+		return nil, fmt.Errorf("invalid latitude %q", row[c.lat])
+	}
+
+	lon, ok := parseNumber(row[c.lon])
+	if !ok {
+		return nil, fmt.Errorf("invalid longitude %q", row[c.lon])
+	}
+
+	rec := &record{
+		id:  row[c.id],
+		obj: t38c.Point{Lat: lat, Lon: lon},
+	}
+
+	for i, name := range c.header {
+		if i == c.id || i == c.lat || i == c.lon || !c.l.wantField(name) {
+			continue
+		}
+
+		v, ok := parseNumber(row[i])
+		if ok {
+			rec.fields = append(rec.fields, t38c.Field{Name: name, Value: v})
+		}
+	}
+
+	return rec, nil
+}
+
+// parseNumber parses a finite number. Values such as "NaN" and "Inf"
+// are accepted by strconv.ParseFloat but are not valid coordinates or
+// fields, so they are treated as non-numeric.
+func parseNumber(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(s, 64)
+
+	return v, err == nil && finite(v)
+}
+
+// start implements recordReader by reading the header row and locating
+// the id and coordinate columns.
+func (c *csvReader) start() error {
+	row, err := c.r.Read()
+	if err != nil {
+		return err
+	}
+
+	c.header = append([]string(nil), row...)
+
+	cols := map[string]*int{
+		withDefault(c.l.IDField, "id"):   &c.id,
+		withDefault(c.l.LatField, "lat"): &c.lat,
+		withDefault(c.l.LonField, "lon"): &c.lon,
+	}
+
+	for name, idx := range cols {
+		*idx = -1
+
+		for i, h := range c.header {
+			if h == name {
+				*idx = i
+			}
+		}
+
+		if *idx < 0 {
+			return fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	return nil
+}
+
+// withDefault returns s, or def if s is empty.
+func withDefault(s string, def string) string {
+	if s == "" {
+		return def
+	}
+
+	return s
+}
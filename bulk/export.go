@@ -0,0 +1,307 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bulk
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"kreklow.us/go/t38c"
+)
+
+// Scanner reads objects from a collection. It is implemented by
+// *t38c.Database.
+type Scanner interface {
+	ScanQuery(key string, q *t38c.Query) (*t38c.Response, error)
+	TTLMany(key string, ids []string) (map[string]time.Duration, error)
+}
+
+// StreamScanner is a Scanner which can pass the objects of a SCAN to a
+// function as they are read. It is implemented by *t38c.Database.
+type StreamScanner interface {
+	Scanner
+	ScanStream(key string, q *t38c.Query, fn t38c.ObjectFunc) (*t38c.Response, error)
+}
+
+// Exporter writes the objects in a collection as GeoJSON Features. If
+// DB is a StreamScanner and TTLProperty is empty, objects are written
+// as they are read rather than a batch at a time.
+//
+// Each Feature has the object id as its id and the object's fields as
+// numeric properties, omitting fields with a zero value, which Tile38
+// does not distinguish from unset fields. Objects stored as strings are
+// written with a null geometry and the string in the "value" property.
+// Objects stored as a Feature are written as they are, with the fields
+// added to their properties, and objects stored as a FeatureCollection
+// are written as each of its features in the same way. Features of a
+// collection keep their own id if they have one.
+type Exporter struct {
+	// DB reads the objects.
+	DB Scanner
+
+	// Key is the collection to export.
+	Key string
+
+	// Format is GeoJSON for a FeatureCollection or NDJSON for one
+	// Feature per line.
+	Format Format
+
+	// Query, if not nil, filters the objects exported, for example with
+	// Match or Where. Its cursor, limit and output options are replaced.
+	Query *t38c.Query
+
+	// TTLProperty, if not empty, is the property holding the remaining
+	// time to live in seconds of objects which expire. The TTLs of each
+	// batch are read in a single pipeline, and objects which expire
+	// before their TTL is read are left out.
+	TTLProperty string
+
+	// BatchSize is the number of objects read per SCAN, 100 if not set.
+	BatchSize int
+}
+
+// Export writes the objects to w and returns the number written.
+func (e *Exporter) Export(w io.Writer) (n int64, err error) {
+	if e.DB == nil || e.Key == "" {
+		return 0, errors.New("exporter requires DB and Key")
+	}
+
+	if e.Format != GeoJSON && e.Format != NDJSON {
+		return 0, fmt.Errorf("unsupported export format %d", e.Format)
+	}
+
+	size := e.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	q := t38c.NewQuery()
+	if e.Query != nil {
+		c := *e.Query
+		q = &c
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if e.Format == GeoJSON {
+		bw.WriteString(`{"type":"FeatureCollection","features":[`)
+	}
+
+	write := func(r *t38c.Response, obj gjson.Result, ttl time.Duration) error {
+		fs, err := e.features(r, obj, ttl)
+		if err != nil {
+			return err
+		}
+
+		for _, b := range fs {
+			switch {
+			case e.Format == NDJSON:
+				bw.Write(b)
+				bw.WriteByte('\n')
+			case n > 0:
+				bw.WriteByte(',')
+				bw.Write(b)
+			default:
+				bw.Write(b)
+			}
+
+			n++
+		}
+
+		return nil
+	}
+
+	for cursor := 0; ; {
+		r, err := e.scan(q.Cursor(cursor).Limit(size).Output(t38c.OutputObjects), write)
+		if err != nil {
+			return n, err
+		}
+
+		if r.Cursor == 0 {
+			break
+		}
+
+		cursor = int(r.Cursor)
+	}
+
+	if e.Format == GeoJSON {
+		bw.WriteString("]}\n")
+	}
+
+	return n, bw.Flush()
+}
+
+// scan runs a SCAN with q, calling fn with each object and its TTL if
+// TTLProperty is set. TTLs are read after each batch, so objects are
+// only streamed without them, to avoid needing a second connection
+// while the SCAN reply is read.
+func (e *Exporter) scan(q *t38c.Query, fn func(*t38c.Response, gjson.Result, time.Duration) error) (*t38c.Response, error) {
+	if s, ok := e.DB.(StreamScanner); ok && e.TTLProperty == "" {
+		return s.ScanStream(e.Key, q, func(r *t38c.Response, obj []byte) error {
+			return fn(r, gjson.ParseBytes(obj), 0)
+		})
+	}
+
+	r, err := e.DB.ScanQuery(e.Key, q)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]gjson.Result, len(r.Objects))
+	ids := make([]string, len(r.Objects))
+
+	for i, raw := range r.Objects {
+		objs[i] = gjson.Parse(raw)
+		ids[i] = objs[i].Get("id").String()
+	}
+
+	var ttls map[string]time.Duration
+
+	if e.TTLProperty != "" && len(ids) > 0 {
+		ttls, err = e.DB.TTLMany(e.Key, ids)
+		if err != nil {
+			return nil, fmt.Errorf("reading ttls: %w", err)
+		}
+	}
+
+	for i, obj := range objs {
+		ttl, ok := ttls[ids[i]]
+		if e.TTLProperty != "" && !ok {
+			continue // expired or deleted since the SCAN
+		}
+
+		err = fn(r, obj, ttl)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// features returns the GeoJSON Features for an element of the objects
+// array of a SCAN response: one for most objects, or one for each
+// feature of a stored FeatureCollection.
+func (e *Exporter) features(r *t38c.Response, obj gjson.Result, ttl time.Duration) ([][]byte, error) {
+	id := obj.Get("id").String()
+	props := make(map[string]any)
+
+	values := obj.Get("fields").Array()
+
+	for name, i := range r.FieldNames {
+		if int(i) < len(values) && values[i].Num != 0 {
+			props[name] = values[i].Num
+		}
+	}
+
+	if e.TTLProperty != "" && ttl > 0 {
+		props[e.TTLProperty] = ttl.Seconds()
+	}
+
+	o := obj.Get("object")
+
+	switch o.Get("type").String() {
+	case "Feature":
+		b, err := mergeFeature(o, id, props)
+		if err != nil {
+			return nil, err
+		}
+
+		return [][]byte{b}, nil
+	case "FeatureCollection":
+		var fs [][]byte
+
+		for _, f := range o.Get("features").Array() {
+			fid := id
+			if f.Get("id").Exists() {
+				fid = ""
+			}
+
+			b, err := mergeFeature(f, fid, props)
+			if err != nil {
+				return nil, err
+			}
+
+			fs = append(fs, b)
+		}
+
+		return fs, nil
+	}
+
+	geom := json.RawMessage("null")
+
+	if o.IsObject() {
+		geom = json.RawMessage(o.Raw)
+	} else {
+		props["value"] = o.String()
+	}
+
+	b, err := json.Marshal(struct {
+		Type       string          `json:"type"`
+		ID         string          `json:"id"`
+		Geometry   json.RawMessage `json:"geometry"`
+		Properties map[string]any  `json:"properties"`
+	}{"Feature", id, geom, props})
+
+	return [][]byte{b}, err
+}
+
+// mergeFeature returns a stored Feature with props added to its
+// properties, replacing any of the same name, and its id set to id
+// unless id is empty.
+func mergeFeature(f gjson.Result, id string, props map[string]any) ([]byte, error) {
+	var m map[string]json.RawMessage
+
+	err := json.Unmarshal([]byte(f.Raw), &m)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feature: %w", err)
+	}
+
+	var merged map[string]json.RawMessage
+
+	if json.Unmarshal(m["properties"], &merged) != nil || merged == nil {
+		merged = make(map[string]json.RawMessage)
+	}
+
+	for name, v := range props {
+		merged[name], _ = json.Marshal(v) //nolint:errchkjson // numbers and strings cannot fail
+	}
+
+	p, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	m["properties"] = p
+
+	if id != "" {
+		m["id"], _ = json.Marshal(id) //nolint:errchkjson // marshaling a string cannot fail
+	}
+
+	return json.Marshal(m)
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bulk_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/bulk"
+	"kreklow.us/go/t38c/t38ctest"
+)
+
+// exportDB returns a database holding a small collection.
+func exportDB(t *testing.T) *t38c.Database {
+	t.Helper()
+
+	srv := t38ctest.NewServer(t)
+	srv.SetClock(func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) })
+
+	for _, cmd := range [][]string{
+		{"SET", "fleet", "t1", "FIELD", "speed", "10", "POINT", "33.1", "-112.1"},
+		{"SET", "fleet", "t2", "EX", "30", "POINT", "33.2", "-112.2"},
+		{"SET", "fleet", "t3", "FIELD", "speed", "30", "FIELD", "age", "2", "POINT", "33.3", "-112.3"},
+		{"SET", "fleet", "note", "STRING", "hello"},
+	} {
+		srv.Do(cmd...)
+	}
+
+	return srv.Connect(t)
+}
+
+// TestExport tests exporting a collection.
+func TestExport(t *testing.T) {
+	db := exportDB(t)
+
+	var buf bytes.Buffer
+
+	e := bulk.Exporter{DB: db, Key: "fleet", TTLProperty: "ttl", BatchSize: 1}
+
+	n, err := e.Export(&buf)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	exp := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","id":"note","geometry":null,"properties":{"value":"hello"}},` +
+		`{"type":"Feature","id":"t1","geometry":{"type":"Point","coordinates":[-112.1,33.1]},"properties":{"speed":10}},` +
+		`{"type":"Feature","id":"t2","geometry":{"type":"Point","coordinates":[-112.2,33.2]},"properties":{"ttl":30}},` +
+		`{"type":"Feature","id":"t3","geometry":{"type":"Point","coordinates":[-112.3,33.3]},"properties":{"age":2,"speed":30}}` +
+		"]}\n"
+
+	if n != 4 || buf.String() != exp {
+		t.Errorf("Export: expected %d objects\n%s\nreceived %d objects\n%s", 4, exp, n, buf.String())
+	}
+
+	buf.Reset()
+
+	e = bulk.Exporter{
+		DB:     db,
+		Key:    "fleet",
+		Format: bulk.NDJSON,
+		Query:  t38c.NewQuery().Match("t*").Where("speed", 20, 40),
+	}
+
+	n, err = e.Export(&buf)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	exp = `{"type":"Feature","id":"t3","geometry":{"type":"Point","coordinates":[-112.3,33.3]},"properties":{"age":2,"speed":30}}` + "\n"
+	if n != 1 || buf.String() != exp {
+		t.Errorf("Export: expected\n%s\nreceived\n%s", exp, buf.String())
+	}
+
+	l := bulk.Loader{DB: db, Key: "copy", Format: bulk.NDJSON}
+
+	_, err = l.Load(&buf)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	r, err := db.Get("copy", "t3", "WITHFIELDS")
+	if err != nil || r == nil || len(r.FieldValues) != 2 {
+		t.Errorf("Get: unexpected %v, %v", r, err)
+	}
+}
+
+// TestExportFeatures tests exporting objects stored as a Feature or a
+// FeatureCollection.
+func TestExportFeatures(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+	srv.Do("SET", "zones", "a", "FIELD", "speed", "5", "OBJECT",
+		`{"type":"Feature","id":"x","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"n","speed":1}}`)
+	srv.Do("SET", "zones", "b", "OBJECT",
+		`{"type":"FeatureCollection","features":[`+
+			`{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":null},`+
+			`{"type":"Feature","id":7,"geometry":{"type":"Point","coordinates":[5,6]}}]}`)
+
+	var buf bytes.Buffer
+
+	e := bulk.Exporter{DB: srv.Connect(t), Key: "zones", Format: bulk.NDJSON}
+
+	n, err := e.Export(&buf)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	exp := `{"geometry":{"type":"Point","coordinates":[1,2]},"id":"a","properties":{"name":"n","speed":5},"type":"Feature"}` + "\n" +
+		`{"geometry":{"type":"Point","coordinates":[3,4]},"id":"b","properties":{},"type":"Feature"}` + "\n" +
+		`{"geometry":{"type":"Point","coordinates":[5,6]},"id":7,"properties":{},"type":"Feature"}` + "\n"
+
+	if n != 3 || buf.String() != exp {
+		t.Errorf("Export: expected %d features\n%s\nreceived %d features\n%s", 3, exp, n, buf.String())
+	}
+}
+
+// vanishing wraps a Scanner, reporting that t1 no longer exists when
+// its TTL is read.
+type vanishing struct {
+	bulk.Scanner
+}
+
+// TTLMany implements bulk.Scanner.
+func (v vanishing) TTLMany(key string, ids []string) (map[string]time.Duration, error) {
+	ttls, err := v.Scanner.TTLMany(key, ids)
+	delete(ttls, "t1")
+
+	return ttls, err
+}
+
+// TestExportVanished tests skipping objects removed while exporting.
+func TestExportVanished(t *testing.T) {
+	var buf bytes.Buffer
+
+	e := bulk.Exporter{DB: vanishing{exportDB(t)}, Key: "fleet", Format: bulk.NDJSON, TTLProperty: "ttl"}
+
+	n, err := e.Export(&buf)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	if n != 3 || strings.Contains(buf.String(), `"t1"`) {
+		t.Errorf("Export: expected 3 objects without t1, received %d\n%s", n, buf.String())
+	}
+}
+
+// errScanner fails every call.
+type errScanner struct{}
+
+// ScanQuery implements bulk.Scanner.
+func (errScanner) ScanQuery(string, *t38c.Query) (*t38c.Response, error) {
+	return &t38c.Response{Objects: []string{`{"id":"a","object":"x"}`}, Cursor: 1}, nil
+}
+
+// TTLMany implements bulk.Scanner.
+func (errScanner) TTLMany(string, []string) (map[string]time.Duration, error) {
+	return nil, errors.New("test failure")
+}
+
+// TestExportErrors tests export failures.
+func TestExportErrors(t *testing.T) {
+	db := exportDB(t)
+
+	tests := map[string]bulk.Exporter{
+		"exporter requires DB and Key": {Key: "fleet"},
+		"unsupported export format 2":  {DB: db, Key: "fleet", Format: bulk.CSV},
+		"invalid query":                {DB: db, Key: "fleet", Query: t38c.NewQuery().Sparse(20)},
+		"reading ttls: test failure":   {DB: errScanner{}, Key: "fleet", TTLProperty: "ttl"},
+	}
+
+	for exp, e := range tests {
+		_, err := e.Export(new(bytes.Buffer))
+		if err == nil || !strings.Contains(err.Error(), exp) {
+			t.Errorf("expected %s, received %v", exp, err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bulk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"kreklow.us/go/t38c"
+)
+
+// feature is a GeoJSON Feature.
+type feature struct {
+	Type       string                     `json:"type"`
+	ID         json.RawMessage            `json:"id"`
+	Geometry   json.RawMessage            `json:"geometry"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+// featureReader reads Features from a FeatureCollection or from a
+// stream of Features.
+type featureReader struct {
+	l      *Loader
+	dec    *json.Decoder
+	stream bool
+}
+
+// newFeatureReader returns a featureReader. If stream is true, the
+// input is a sequence of Features rather than a FeatureCollection.
+func newFeatureReader(l *Loader, r io.Reader, stream bool) *featureReader {
+	return &featureReader{
+		l:      l,
+		dec:    json.NewDecoder(r),
+		stream: stream,
+	}
+}
+
+// next implements recordReader.
+func (f *featureReader) next() (*record, error) {
+	if !f.stream && !f.dec.More() {
+		return nil, io.EOF
+	}
+
+	var ft feature
+
+	err := f.dec.Decode(&ft)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.record(&ft)
+}
+
+// start implements recordReader. For a FeatureCollection, it advances
+// the decoder to the first element of the features array.
+func (f *featureReader) start() error {
+	if f.stream {
+		return nil
+	}
+
+	tok, err := f.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok != json.Delim('{') {
+		return errors.New("input is not a FeatureCollection")
+	}
+
+	for f.dec.More() {
+		tok, err = f.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if tok == "features" {
+			tok, err = f.dec.Token()
+			if err != nil {
+				return err
+			}
+
+			if tok != json.Delim('[') {
+				return errors.New("features is not an array")
+			}
+
+			return nil
+		}
+
+		var skip json.RawMessage
+
+		err = f.dec.Decode(&skip)
+		if err != nil {
+			return err
+		}
+	}
+
+	return errors.New("input has no features")
+}
+
+// record converts a Feature to a record.
+func (f *featureReader) record(ft *feature) (*record, error) {
+	if ft.Type != "Feature" {
+		return nil, fmt.Errorf("type %q is not Feature", ft.Type)
+	}
+
+	if len(ft.Geometry) == 0 || string(ft.Geometry) == "null" {
+		return nil, errNoGeometry
+	}
+
+	idval := ft.ID
+	if f.l.IDField != "" {
+		idval = ft.Properties[f.l.IDField]
+	}
+
+	id, err := jsonID(idval)
+	if err != nil {
+		return nil, err
+	}
+
+	var geom bytes.Buffer
+
+	err = json.Compact(&geom, ft.Geometry)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &record{
+		id:  id,
+		obj: t38c.GeoJSON(geom.String()),
+	}
+
+	names := make([]string, 0, len(ft.Properties))
+	for name := range ft.Properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !f.l.wantField(name) {
+			continue
+		}
+
+		var v float64
+
+		if json.Unmarshal(ft.Properties[name], &v) == nil && finite(v) {
+			rec.fields = append(rec.fields, t38c.Field{Name: name, Value: v})
+		}
+	}
+
+	return rec, nil
+}
+
+// jsonID returns an id from a JSON string or number.
+func jsonID(raw json.RawMessage) (string, error) {
+	var s string
+
+	if json.Unmarshal(raw, &s) == nil && s != "" {
+		return s, nil
+	}
+
+	var n json.Number
+
+	if json.Unmarshal(raw, &n) == nil {
+		_, err := strconv.ParseFloat(n.String(), 64)
+		if err == nil {
+			return n.String(), nil
+		}
+	}
+
+	return "", errNoID
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kreklow.us/go/t38c"
+)
+
+// errNotFound is returned by get when the object does not exist.
+var errNotFound = errors.New("id not found")
+
+// command is a CLI command mapped to a Database method.
+type command struct {
+	args string // argument synopsis
+	min  int    // minimum number of arguments
+	run  func(db *t38c.Database, args []string) (*t38c.Response, error)
+}
+
+// commands are the available commands by name.
+var commands = map[string]command{ //nolint:gochecknoglobals // command table
+	"set": {"key id [options] type value...", 3, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		return ok(db.Set(a[0], a[1], a[2:]...))
+	}},
+	"get": {"key id [WITHFIELDS] [type]", 2, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		r, err := db.Get(a[0], a[1], a[2:]...)
+		if err == nil && r == nil {
+			err = errNotFound
+		}
+
+		return r, err
+	}},
+	"keys": {"pattern", 1, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		keys, err := db.Keys(a[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return &t38c.Response{Ok: true, Keys: keys}, nil
+	}},
+	"scan": {"key [options]", 1, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		return db.Scan(a[0], a[1:]...)
+	}},
+	"search": {"key [options]", 1, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		return db.Search(a[0], a[1:]...)
+	}},
+	"nearby": {"key [options] area...", 2, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		return db.Nearby(a[0], a[1:]...)
+	}},
+	"within": {"key [options] area...", 2, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		return db.Within(a[0], a[1:]...)
+	}},
+	"intersects": {"key [options] area...", 2, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		return db.Intersects(a[0], a[1:]...)
+	}},
+	"del": {"key id", 2, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		return ok(db.Del(a[0], a[1]))
+	}},
+	"pdel": {"key pattern", 2, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		return ok(db.PDel(a[0], a[1]))
+	}},
+	"expire": {"key id seconds|duration", 3, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		d, err := parseExpire(a[2])
+		if err != nil {
+			return nil, err
+		}
+
+		return ok(db.ExpireDuration(a[0], a[1], d))
+	}},
+	"persist": {"key id", 2, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		return ok(db.Persist(a[0], a[1]))
+	}},
+	"ttl": {"key id", 2, func(db *t38c.Database, a []string) (*t38c.Response, error) {
+		ttl, err := db.TTL(a[0], a[1])
+		if err != nil {
+			return nil, err
+		}
+
+		return &t38c.Response{Ok: true, TTL: ttl}, nil
+	}},
+}
+
+// ok returns a successful Response for methods which only return an
+// error.
+func ok(err error) (*t38c.Response, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return &t38c.Response{Ok: true}, nil
+}
+
+// parseExpire parses a timeout given either in seconds, such as "1.5",
+// or as a duration, such as "90s".
+func parseExpire(s string) (time.Duration, error) {
+	secs, err := strconv.ParseFloat(s, 64)
+	if err == nil && secs >= 0 {
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+
+	return d, nil
+}
+
+// execute runs a command and prints the response.
+func execute(db *t38c.Database, p printer, args []string, w io.Writer) error {
+	name := strings.ToLower(args[0])
+
+	cmd, found := commands[name]
+	if !found {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	if len(args)-1 < cmd.min {
+		return fmt.Errorf("usage: %s %s", name, cmd.args)
+	}
+
+	r, err := cmd.run(db, args[1:])
+	if err != nil {
+		return err
+	}
+
+	return p(w, r)
+}
+
+// printCommands writes the command synopses to w.
+func printCommands(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s %s\n", name, commands[name].args)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Command t38c is a command-line client for Tile38 built on the t38c
+// library.
+//
+// Usage:
+//
+//	t38c [flags] COMMAND [ARGS...]
+//	t38c [flags]
+//
+// Commands mirror the methods of t38c.Database and take arguments in
+// the same form as the Tile38 CLI, for example:
+//
+//	t38c set fleet truck1 POINT 33.5123 -112.2693
+//	t38c -o table scan fleet WHERE speed 50 +inf
+//
+// Without a command, t38c reads commands from standard input, one per
+// line, prompting for them when run interactively.
+//
+// The server address, password and output format default to the
+// T38C_SERVER, T38C_PORT, T38C_PASSWORD and T38C_OUTPUT environment
+// variables. The password is sent with AUTH over RESP connections;
+// with -http or -https, commands are sent without authentication, and
+// TLS is only available with -https.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"kreklow.us/go/t38c"
+)
+
+func main() {
+	fi, err := os.Stdin.Stat()
+	interactive := err == nil && fi.Mode()&os.ModeCharDevice != 0
+
+	err = run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, interactive)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "t38c:", err)
+		}
+
+		os.Exit(1)
+	}
+}
+
+// run parses the flags and runs a single command, or the REPL if no
+// command is given.
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, interactive bool) error {
+	fs := flag.NewFlagSet("t38c", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var (
+		server   = fs.String("server", env("T38C_SERVER", "localhost"), "Tile38 server `host`")
+		port     = fs.String("port", env("T38C_PORT", "9851"), "Tile38 server `port`")
+		useHTTP  = fs.Bool("http", false, "connect using the HTTP interface")
+		useHTTPS = fs.Bool("https", false, "connect using the HTTP interface over TLS")
+		caFile   = fs.String("cacert", "", "PEM `file` of CA certificates to trust with -https")
+		password = fs.String("password", "", "server `password`, sent with AUTH over RESP connections (default $T38C_PASSWORD)")
+		output   = fs.String("o", env("T38C_OUTPUT", "json"), "output `format`: json, table or geojson")
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: t38c [flags] [COMMAND [ARGS...]]")
+		fs.PrintDefaults()
+		fmt.Fprintln(stderr, "\nTLS is only supported with -https, and -password only without -http or -https.")
+		fmt.Fprintln(stderr, "\ncommands:")
+		printCommands(stderr)
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if !isSet(fs, "password") {
+		*password = os.Getenv("T38C_PASSWORD")
+	}
+
+	p, err := newPrinter(*output)
+	if err != nil {
+		return err
+	}
+
+	var opts []t38c.Option
+
+	if *password != "" {
+		if *useHTTP || *useHTTPS {
+			return errors.New("-password is not supported with -http or -https")
+		}
+
+		opts = append(opts, t38c.WithPassword(*password))
+	}
+
+	switch {
+	case *useHTTPS:
+		client, err := httpsClient(*caFile)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, t38c.WithHTTPS(client))
+	case *useHTTP:
+		opts = append(opts, t38c.WithHTTP(nil))
+	}
+
+	db, err := t38c.Connect(*server, *port, 1, opts...)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	if fs.NArg() == 0 {
+		return repl(db, p, stdin, stdout, stderr, interactive)
+	}
+
+	return execute(db, p, fs.Args(), stdout)
+}
+
+// httpsClient returns an HTTP client trusting the CA certificates in
+// caFile, or nil to use the system roots if caFile is empty.
+func httpsClient(caFile string) (*http.Client, error) {
+	if caFile == "" {
+		return nil, nil //nolint:nilnil // nil selects a client using the system roots
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		},
+	}, nil
+}
+
+// isSet reports whether the named flag was given on the command line.
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// env returns the value of an environment variable, or def if it is not
+// set.
+func env(name string, def string) string {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return def
+	}
+
+	return v
+}
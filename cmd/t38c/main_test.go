@@ -0,0 +1,206 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/t38c/t38ctest"
+)
+
+// TestRun tests running single commands in each output format.
+func TestRun(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+	conn := []string{"-server", srv.Addr, "-port", srv.Port}
+
+	tests := []struct {
+		args []string
+		exp  string
+	}{
+		{[]string{"set", "fleet", "truck1", "FIELD", "speed", "55", "POINT", "33", "-112"}, `"ok": true`},
+		{[]string{"-http", "set", "fleet", "truck2", "STRING", "parked"}, `"ok": true`},
+		{[]string{"-password", "secret", "get", "fleet", "truck2"}, `"object": "parked"`},
+		{[]string{"get", "fleet", "truck1"}, `"coordinates": [`},
+		{[]string{"-o", "table", "scan", "fleet"}, "truck2  parked"},
+		{[]string{"-o", "table", "scan", "fleet", "IDS"}, "ID\ntruck1\ntruck2\n"},
+		{[]string{"-o", "table", "scan", "fleet", "COUNT"}, "count  2"},
+		{[]string{"-o", "geojson", "scan", "fleet"}, `"type": "FeatureCollection"`},
+		{[]string{"-o", "geojson", "get", "fleet", "truck2"}, `"value": "parked"`},
+		{[]string{"-o", "geojson", "nearby", "fleet", "POINT", "33", "-112", "1000"}, `"speed": 55`},
+		{[]string{"within", "fleet", "BOUNDS", "32", "-113", "34", "-111"}, `"id": "truck1"`},
+		{[]string{"keys", "*"}, `"keys": [`},
+		{[]string{"-o", "table", "keys", "f*"}, "KEY\nfleet\n"},
+		{[]string{"expire", "fleet", "truck2", "1m0.5s"}, `"ok": true`},
+		{[]string{"expire", "fleet", "truck2", "60"}, `"ok": true`},
+		{[]string{"-o", "table", "ttl", "fleet", "truck2"}, "ttl  59."},
+		{[]string{"del", "fleet", "truck2"}, `"ok": true`},
+	}
+
+	for _, tc := range tests {
+		var stdout, stderr bytes.Buffer
+
+		err := run(append(conn, tc.args...), nil, &stdout, &stderr, false)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+
+		if !strings.Contains(stdout.String(), tc.exp) {
+			t.Errorf("%v: expected output containing %q, received:\n%s", tc.args, tc.exp, stdout.String())
+		}
+	}
+}
+
+// TestRunErrors tests invalid flags and failing commands.
+func TestRunErrors(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+	conn := []string{"-server", srv.Addr, "-port", srv.Port}
+
+	tests := map[string][]string{
+		`unknown output format "xml"`: {"-o", "xml", "scan", "fleet"},
+		`unknown command "fly"`:       {"fly", "fleet"},
+		"usage: get key id":           {"get", "fleet"},
+		`invalid timeout "soon"`:      {"expire", "fleet", "a", "soon"},
+		`invalid timeout "-1"`:        {"expire", "fleet", "a", "-1"},
+		"id not found":                {"get", "fleet", "missing"},
+		"no such file":                {"-https", "-cacert", "/nonexistent.pem", "scan", "fleet"},
+		"-password is not supported":  {"-http", "-password", "secret", "scan", "fleet"},
+	}
+
+	srv.Do("SET", "fleet", "a", "POINT", "1", "2")
+
+	for exp, args := range tests {
+		var stdout, stderr bytes.Buffer
+
+		err := run(append(conn, args...), nil, &stdout, &stderr, false)
+		if err == nil || !strings.Contains(err.Error(), exp) {
+			t.Errorf("%v: expected error containing %q, received %v", args, exp, err)
+		}
+	}
+}
+
+// TestPassword tests reading the password from the environment without
+// printing it in the usage text.
+func TestPassword(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+	conn := []string{"-server", srv.Addr, "-port", srv.Port}
+
+	t.Setenv("T38C_PASSWORD", "hunter2")
+
+	var stdout, stderr bytes.Buffer
+
+	err := run([]string{"-badflag"}, nil, &stdout, &stderr, false)
+	if err == nil {
+		t.Fatal("-badflag: expected error")
+	}
+
+	if strings.Contains(stderr.String(), "hunter2") {
+		t.Errorf("expected usage without the password, received:\n%s", stderr.String())
+	}
+
+	err = run(append(conn, "-http", "scan", "fleet"), nil, &stdout, &stderr, false)
+	if err == nil || !strings.Contains(err.Error(), "-password is not supported") {
+		t.Errorf("expected error for T38C_PASSWORD with -http, received %v", err)
+	}
+
+	err = run(append(conn, "-http", "-password", "", "scan", "fleet"), nil, &stdout, &stderr, false)
+	if err != nil {
+		t.Errorf("expected -password to override T38C_PASSWORD, received %v", err)
+	}
+
+	err = run(append(conn, "scan", "fleet"), nil, &stdout, &stderr, false)
+	if err != nil {
+		t.Errorf("scan with T38C_PASSWORD: %v", err)
+	}
+}
+
+// TestREPL tests reading commands from standard input.
+func TestREPL(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+
+	in := strings.NewReader(strings.Join([]string{
+		`set fleet truck1 OBJECT '{"type":"Point","coordinates":[-112,33]}'`,
+		`set fleet truck2 STRING "two words"`,
+		``,
+		`output table`,
+		`scan fleet`,
+		`get fleet`,
+		`output xml`,
+		`help`,
+		`quit`,
+		`scan fleet`,
+	}, "\n"))
+
+	var stdout, stderr bytes.Buffer
+
+	err := run([]string{"-server", srv.Addr, "-port", srv.Port}, in, &stdout, &stderr, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, exp := range []string{prompt, `{"type":"Point","coordinates":[-112,33]}`, "truck2  two words", "pdel key pattern"} {
+		if !strings.Contains(stdout.String(), exp) {
+			t.Errorf("expected output containing %q, received:\n%s", exp, stdout.String())
+		}
+	}
+
+	if strings.Count(stdout.String(), "ID  ") != 1 {
+		t.Errorf("expected commands after quit to be ignored, received:\n%s", stdout.String())
+	}
+
+	exp := "error: usage: get key id [WITHFIELDS] [type]\nerror: unknown output format \"xml\"\n"
+	if stderr.String() != exp {
+		t.Errorf("expected errors %q, received %q", exp, stderr.String())
+	}
+}
+
+// TestSplitArgs tests splitting REPL lines into arguments.
+func TestSplitArgs(t *testing.T) {
+	tests := map[string][]string{
+		"":                      nil,
+		"  scan\tfleet  ":       {"scan", "fleet"},
+		`set k id STRING "a b"`: {"set", "k", "id", "STRING", "a b"},
+		`a 'b "c" d' e`:         {"a", `b "c" d`, "e"},
+		`"esc \" \\" x""y ''`:   {`esc " \`, "xy", ""},
+	}
+
+	for line, exp := range tests {
+		args, err := splitArgs(line)
+		if err != nil {
+			t.Errorf("%q: %v", line, err)
+		}
+
+		if !reflect.DeepEqual(args, exp) {
+			t.Errorf("%q: expected %q, received %q", line, exp, args)
+		}
+	}
+
+	for _, line := range []string{`"open`, `'open`, `"esc \`} {
+		_, err := splitArgs(line)
+		if err != errQuote {
+			t.Errorf("%q: expected %v, received %v", line, errQuote, err)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tidwall/gjson"
+	"kreklow.us/go/t38c"
+)
+
+// printer writes a Response to w in an output format.
+type printer func(w io.Writer, r *t38c.Response) error
+
+// newPrinter returns the printer for the named output format.
+func newPrinter(format string) (printer, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		return printJSON, nil
+	case "table":
+		return printTable, nil
+	case "geojson":
+		return printGeoJSON, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// entry is a single object from a Response.
+type entry struct {
+	ID     string             `json:"id,omitempty"`
+	Object json.RawMessage    `json:"object,omitempty"`
+	Fields map[string]float64 `json:"fields,omitempty"`
+}
+
+// entries returns the objects contained in r, or nil if it has none.
+// Fields with a zero value are left out, matching how Tile38 treats
+// them as unset.
+func entries(r *t38c.Response) []entry {
+	if r.Objects != nil {
+		es := make([]entry, len(r.Objects))
+
+		for i, raw := range r.Objects {
+			obj := gjson.Parse(raw)
+			values := obj.Get("fields").Array()
+
+			es[i].ID = obj.Get("id").String()
+			es[i].Object = json.RawMessage(obj.Get("object").Raw)
+			es[i].Fields = fields(r.FieldNames, func(j int64) float64 {
+				if int(j) < len(values) {
+					return values[j].Num
+				}
+
+				return 0
+			})
+		}
+
+		return es
+	}
+
+	if r.Object != "" {
+		return []entry{{
+			ID:     r.ID,
+			Object: rawObject(r.Object),
+			Fields: fields(r.FieldNames, func(j int64) float64 {
+				if int(j) < len(r.FieldValues) {
+					return r.FieldValues[j]
+				}
+
+				return 0
+			}),
+		}}
+	}
+
+	return nil
+}
+
+// fields maps field names to their non-zero values.
+func fields(names map[string]int64, value func(int64) float64) map[string]float64 {
+	var m map[string]float64
+
+	for name, i := range names {
+		v := value(i)
+		if v == 0 {
+			continue
+		}
+
+		if m == nil {
+			m = make(map[string]float64)
+		}
+
+		m[name] = v
+	}
+
+	return m
+}
+
+// rawObject returns the JSON form of an object, quoting it if it is a
+// STRING object rather than GeoJSON.
+func rawObject(s string) json.RawMessage {
+	if strings.HasPrefix(s, "{") && gjson.Valid(s) {
+		return json.RawMessage(s)
+	}
+
+	b, _ := json.Marshal(s) //nolint:errchkjson // marshaling a string cannot fail
+
+	return b
+}
+
+// printJSON writes r as indented JSON.
+func printJSON(w io.Writer, r *t38c.Response) error {
+	out := struct {
+		Ok      bool              `json:"ok"`
+		IDs     []string          `json:"ids,omitempty"`
+		Keys    []string          `json:"keys,omitempty"`
+		Objects []entry           `json:"objects,omitempty"`
+		Points  []json.RawMessage `json:"points,omitempty"`
+		Bounds  []json.RawMessage `json:"bounds,omitempty"`
+		Hashes  []json.RawMessage `json:"hashes,omitempty"`
+		Count   int64             `json:"count,omitempty"`
+		Cursor  int64             `json:"cursor,omitempty"`
+		TTL     float64           `json:"ttl,omitempty"`
+		Result  json.RawMessage   `json:"result,omitempty"`
+		Elapsed string            `json:"elapsed,omitempty"`
+	}{
+		Ok:      r.Ok,
+		IDs:     r.IDs,
+		Keys:    r.Keys,
+		Objects: entries(r),
+		Points:  rawList(r.Points),
+		Bounds:  rawList(r.Bounds),
+		Hashes:  rawList(r.Hashes),
+		Count:   r.Count,
+		Cursor:  r.Cursor,
+		TTL:     r.TTL,
+		Elapsed: r.Elapsed,
+	}
+
+	if r.Result != "" {
+		out.Result = json.RawMessage(r.Result)
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", b)
+
+	return err
+}
+
+// rawList returns the raw JSON elements of a query result.
+func rawList(raw []string) []json.RawMessage {
+	if raw == nil {
+		return nil
+	}
+
+	l := make([]json.RawMessage, len(raw))
+	for i, e := range raw {
+		l[i] = json.RawMessage(e)
+	}
+
+	return l
+}
+
+// printTable writes the objects or ids in r as an aligned table, or
+// the remaining values as name and value rows if it has neither.
+func printTable(w io.Writer, r *t38c.Response) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+
+	es := entries(r)
+
+	switch {
+	case es != nil:
+		names := make([]string, 0, len(r.FieldNames))
+		for name := range r.FieldNames {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		fmt.Fprintf(tw, "ID\tOBJECT")
+
+		for _, name := range names {
+			fmt.Fprintf(tw, "\t%s", strings.ToUpper(name))
+		}
+
+		fmt.Fprintln(tw)
+
+		for _, e := range es {
+			fmt.Fprintf(tw, "%s\t%s", e.ID, tableObject(e.Object))
+
+			for _, name := range names {
+				v, ok := e.Fields[name]
+				if ok {
+					fmt.Fprintf(tw, "\t%s", strconv.FormatFloat(v, 'f', -1, 64))
+				} else {
+					fmt.Fprint(tw, "\t")
+				}
+			}
+
+			fmt.Fprintln(tw)
+		}
+	case r.IDs != nil:
+		fmt.Fprintln(tw, "ID")
+
+		for _, id := range r.IDs {
+			fmt.Fprintln(tw, id)
+		}
+	case r.Keys != nil:
+		fmt.Fprintln(tw, "KEY")
+
+		for _, key := range r.Keys {
+			fmt.Fprintln(tw, key)
+		}
+	default:
+		fmt.Fprintf(tw, "ok\t%t\n", r.Ok)
+
+		if r.Count != 0 {
+			fmt.Fprintf(tw, "count\t%d\n", r.Count)
+		}
+
+		if r.TTL != 0 {
+			fmt.Fprintf(tw, "ttl\t%s\n", strconv.FormatFloat(r.TTL, 'f', -1, 64))
+		}
+
+		if r.Result != "" {
+			fmt.Fprintf(tw, "result\t%s\n", r.Result)
+		}
+	}
+
+	if es != nil || r.IDs != nil {
+		if r.Cursor != 0 {
+			fmt.Fprintf(tw, "\ncursor\t%d\n", r.Cursor)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// tableObject returns an object as a single table cell, unquoting
+// STRING objects.
+func tableObject(raw json.RawMessage) string {
+	v := gjson.ParseBytes(raw)
+	if v.Type == gjson.String {
+		return v.Str
+	}
+
+	return v.Raw
+}
+
+// printGeoJSON writes the objects in r as an indented GeoJSON Feature
+// or FeatureCollection, with fields as properties. Responses without
+// objects are written as JSON.
+func printGeoJSON(w io.Writer, r *t38c.Response) error {
+	es := entries(r)
+	if es == nil {
+		return printJSON(w, r)
+	}
+
+	type feature struct {
+		Type       string          `json:"type"`
+		ID         string          `json:"id,omitempty"`
+		Geometry   json.RawMessage `json:"geometry"`
+		Properties map[string]any  `json:"properties"`
+	}
+
+	fs := make([]feature, len(es))
+
+	for i, e := range es {
+		props := make(map[string]any, len(e.Fields))
+		geom := json.RawMessage("null")
+
+		if gjson.ParseBytes(e.Object).IsObject() {
+			geom = e.Object
+		} else {
+			props["value"] = tableObject(e.Object)
+		}
+
+		for name, v := range e.Fields {
+			props[name] = v
+		}
+
+		fs[i] = feature{"Feature", e.ID, geom, props}
+	}
+
+	var v any = fs[0]
+
+	if r.Object == "" {
+		v = struct {
+			Type     string    `json:"type"`
+			Features []feature `json:"features"`
+		}{"FeatureCollection", fs}
+	}
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", b)
+
+	return err
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"kreklow.us/go/t38c"
+)
+
+// prompt is written before each command in an interactive session.
+const prompt = "t38c> "
+
+// repl reads and runs commands from r until it reaches the end of
+// input or a quit command. Errors from individual commands are written
+// to stderr and do not end the session.
+func repl(db *t38c.Database, p printer, r io.Reader, stdout io.Writer, stderr io.Writer, interactive bool) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20) //nolint:mnd // allow large GeoJSON objects
+
+	for {
+		if interactive {
+			fmt.Fprint(stdout, prompt)
+		}
+
+		if !sc.Scan() {
+			break
+		}
+
+		args, err := splitArgs(sc.Text())
+		if err != nil {
+			fmt.Fprintln(stderr, "error:", err)
+
+			continue
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		switch strings.ToLower(args[0]) {
+		case "quit", "exit":
+			return nil
+		case "help":
+			printCommands(stdout)
+
+			continue
+		case "output":
+			if len(args) != 2 { //nolint:mnd // output FORMAT
+				fmt.Fprintln(stderr, "error: usage: output json|table|geojson")
+
+				continue
+			}
+
+			np, err := newPrinter(args[1])
+			if err != nil {
+				fmt.Fprintln(stderr, "error:", err)
+
+				continue
+			}
+
+			p = np
+
+			continue
+		}
+
+		err = execute(db, p, args, stdout)
+		if err != nil {
+			fmt.Fprintln(stderr, "error:", err)
+		}
+	}
+
+	if interactive {
+		fmt.Fprintln(stdout)
+	}
+
+	return sc.Err()
+}
+
+// errQuote is returned by splitArgs for an unterminated quote.
+var errQuote = errors.New("unterminated quote")
+
+// splitArgs splits a line into arguments on whitespace. Single and
+// double quotes group words into one argument, and within double
+// quotes a backslash escapes the next character.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args  []string
+		cur   strings.Builder
+		quote rune
+		inArg bool
+		esc   bool
+	)
+
+	for _, c := range line {
+		switch {
+		case esc:
+			cur.WriteRune(c)
+			esc = false
+		case quote == '"' && c == '\\':
+			esc = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(c)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || esc {
+		return nil, errQuote
+	}
+
+	if inArg {
+		args = append(args, cur.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Command t38load loads GeoJSON, newline-delimited GeoJSON or CSV files
+// into a Tile38 collection.
+//
+// Usage:
+//
+//	t38load [flags] -key KEY [FILE]
+//
+// The input is read from FILE, or from standard input if FILE is
+// omitted or "-". If -format is not given, it is chosen from the file
+// extension. The server address and password default to the
+// T38C_SERVER, T38C_PORT and T38C_PASSWORD environment variables.
+//
+// If a load fails, the number of records processed is printed and the
+// load can be resumed by passing it to -skip.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/bulk"
+)
+
+// progressInterval is the minimum time between progress reports.
+const progressInterval = time.Second
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stderr)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "t38load:", err)
+		}
+
+		os.Exit(1)
+	}
+}
+
+// run parses the arguments and performs the load.
+func run(args []string, stdin io.Reader, stderr io.Writer) error {
+	fs := flag.NewFlagSet("t38load", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var (
+		server  = fs.String("server", env("T38C_SERVER", "localhost"), "Tile38 server `host`")
+		port    = fs.String("port", env("T38C_PORT", "9851"), "Tile38 server `port`")
+		useHTTP = fs.Bool("http", false, "connect using the HTTP interface")
+		passwd  = fs.String("password", "", "server `password`, sent with AUTH over RESP connections (default $T38C_PASSWORD)")
+		key     = fs.String("key", "", "collection `key` to load into")
+		format  = fs.String("format", "", "input `format`: geojson, ndjson or csv")
+		id      = fs.String("id", "", "`property` or column holding the object id")
+		lat     = fs.String("lat", "lat", "CSV `column` holding the latitude")
+		lon     = fs.String("lon", "lon", "CSV `column` holding the longitude")
+		fields  = fs.String("fields", "", "comma-separated `list` of fields to save, all numeric values if empty")
+		expire  = fs.Duration("ex", 0, "expiration `duration` for each object")
+		batch   = fs.Int("batch", 100, "`number` of objects per batch") //nolint:mnd // default batch size
+		skip    = fs.Int64("skip", 0, "`number` of records to skip, to resume a load")
+	)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if !isSet(fs, "password") {
+		*passwd = os.Getenv("T38C_PASSWORD")
+	}
+
+	if *key == "" || fs.NArg() > 1 {
+		fs.Usage()
+
+		return errors.New("a key and at most one file are required")
+	}
+
+	in, name, err := open(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	defer in.Close()
+
+	l := &bulk.Loader{
+		Key:       *key,
+		IDField:   *id,
+		LatField:  *lat,
+		LonField:  *lon,
+		Expire:    *expire,
+		BatchSize: *batch,
+		Skip:      *skip,
+	}
+
+	l.Format, err = inputFormat(*format, name)
+	if err != nil {
+		return err
+	}
+
+	if *fields != "" {
+		l.Fields = strings.Split(*fields, ",")
+	}
+
+	var opts []t38c.Option
+
+	switch {
+	case *passwd != "" && *useHTTP:
+		return errors.New("-password is not supported with -http")
+	case *passwd != "":
+		opts = append(opts, t38c.WithPassword(*passwd))
+	case *useHTTP:
+		opts = append(opts, t38c.WithHTTP(nil))
+	}
+
+	db, err := t38c.Connect(*server, *port, 1, opts...)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	l.DB = db
+
+	var last time.Time
+
+	l.Progress = func(p bulk.Progress) {
+		if time.Since(last) >= progressInterval {
+			fmt.Fprintf(stderr, "%d records, %d loaded\n", p.Records, p.Loaded)
+
+			last = time.Now()
+		}
+	}
+
+	p, err := l.Load(in)
+	if err != nil {
+		return fmt.Errorf("%w\nresume with -skip %d", err, p.Records)
+	}
+
+	fmt.Fprintf(stderr, "done: %d records, %d loaded\n", p.Records, p.Loaded)
+
+	return nil
+}
+
+// open returns the named input file, or stdin if name is empty or "-".
+func open(name string, stdin io.Reader) (io.ReadCloser, string, error) {
+	if name == "" || name == "-" {
+		return io.NopCloser(stdin), "", nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return f, name, nil
+}
+
+// inputFormat returns the named format, or the format matching the
+// file extension if name is empty.
+func inputFormat(name string, file string) (bulk.Format, error) {
+	if name != "" {
+		return bulk.ParseFormat(name)
+	}
+
+	f, err := bulk.ParseFormat(strings.TrimPrefix(filepath.Ext(file), "."))
+	if err != nil {
+		return bulk.GeoJSON, nil //nolint:nilerr // GeoJSON is the default format
+	}
+
+	return f, nil
+}
+
+// isSet reports whether the named flag was given on the command line.
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// env returns the value of an environment variable, or def if it is not
+// set.
+func env(name string, def string) string {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return def
+	}
+
+	return v
+}
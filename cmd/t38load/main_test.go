@@ -0,0 +1,101 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/t38c/t38ctest"
+)
+
+// TestRun tests loading files and standard input.
+func TestRun(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+	db := srv.Connect(t)
+
+	file := filepath.Join(t.TempDir(), "trucks.csv")
+
+	err := os.WriteFile(file, []byte("name,lat,lon,speed\na,33.1,-112.1,10\nb,33.2,-112.2,20\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr bytes.Buffer
+
+	err = run([]string{"-server", srv.Addr, "-port", srv.Port, "-password", "secret", "-key", "csv", "-id", "name", file}, nil, &stderr)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !strings.Contains(stderr.String(), "done: 2 records, 2 loaded") {
+		t.Errorf("unexpected output: %s", stderr.String())
+	}
+
+	in := strings.NewReader(`{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[1,2]}}` + "\n")
+
+	err = run([]string{"-server", srv.Addr, "-port", srv.Port, "-http", "-key", "nd", "-format", "ndjson", "-"}, in, &stderr)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	for key, exp := range map[string]int64{"csv": 2, "nd": 1} {
+		r, err := db.Scan(key, "COUNT")
+		if err != nil || r.Count != exp {
+			t.Errorf("%s: expected %d objects, received %v, %v", key, exp, r, err)
+		}
+	}
+}
+
+// TestRunErrors tests invalid arguments and failed loads.
+func TestRunErrors(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+
+	tests := map[string][]string{
+		"a key and at most one file are required": {"-server", srv.Addr},
+		"no such file":               {"-key", "k", filepath.Join(t.TempDir(), "missing.json")},
+		`unknown format "xml"`:       {"-key", "k", "-format", "xml"},
+		"error connecting to server": {"-key", "k", "-server", srv.Addr, "-port", "1"},
+		"resume with -skip 0":        {"-key", "k", "-server", srv.Addr, "-port", srv.Port},
+		"-password is not supported": {"-key", "k", "-http", "-password", "secret"},
+	}
+
+	for exp, args := range tests {
+		err := run(args, strings.NewReader("{}"), new(bytes.Buffer))
+		if err == nil || !strings.Contains(err.Error(), exp) {
+			t.Errorf("expected %s, received %v", exp, err)
+		}
+	}
+
+	t.Setenv("T38C_PASSWORD", "hunter2")
+
+	var stderr bytes.Buffer
+
+	err := run([]string{"-badflag"}, nil, &stderr)
+	if err == nil || strings.Contains(stderr.String(), "hunter2") {
+		t.Errorf("expected usage without the password, received %v:\n%s", err, stderr.String())
+	}
+}
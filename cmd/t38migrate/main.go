@@ -0,0 +1,225 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Command t38migrate copies Tile38 collections between keys or between
+// servers.
+//
+// Usage:
+//
+//	t38migrate [flags] -dst HOST:PORT PATTERN
+//
+// Every key matching PATTERN on the source server is copied to the
+// destination, keeping object fields and remaining expirations. The
+// source address and password default to the T38C_SERVER, T38C_PORT and
+// T38C_PASSWORD environment variables. With -rename OLD=NEW, keys beginning with OLD are copied to
+// keys beginning with NEW instead, which also allows copying within a
+// single server.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/migrate"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stderr)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "t38migrate:", err)
+		}
+
+		os.Exit(1)
+	}
+}
+
+// run parses the arguments and performs the migration.
+func run(args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet("t38migrate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	defSrc := net.JoinHostPort(env("T38C_SERVER", "localhost"), env("T38C_PORT", "9851"))
+
+	var (
+		src     = fs.String("src", defSrc, "source server `host:port`")
+		dst     = fs.String("dst", "", "destination server `host:port`, the source if empty")
+		srcHTTP = fs.Bool("src-http", false, "connect to the source using the HTTP interface")
+		dstHTTP = fs.Bool("dst-http", false, "connect to the destination using the HTTP interface")
+		srcPass = fs.String("src-password", "", "source server `password`, sent with AUTH over RESP connections (default $T38C_PASSWORD)")
+		dstPass = fs.String("dst-password", "", "destination server `password`, sent with AUTH over RESP connections")
+		rename  = fs.String("rename", "", "replace the key prefix `OLD=NEW` in the destination")
+		dryRun  = fs.Bool("dry-run", false, "read the source without writing to the destination")
+		rate    = fs.Float64("rate", 0, "maximum `objects` copied per second, unlimited if 0")
+		batch   = fs.Int("batch", 100, "`number` of objects per batch") //nolint:mnd // default batch size
+		verify  = fs.Bool("verify", false, "compare object counts and hashes after copying each key")
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: t38migrate [flags] PATTERN")
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if !isSet(fs, "src-password") {
+		*srcPass = os.Getenv("T38C_PASSWORD")
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+
+		return errors.New("a key pattern is required")
+	}
+
+	m := &migrate.Migrator{
+		Pattern:   fs.Arg(0),
+		DryRun:    *dryRun,
+		Rate:      *rate,
+		BatchSize: *batch,
+		Verify:    *verify,
+	}
+
+	if *rename != "" {
+		old, repl, ok := strings.Cut(*rename, "=")
+		if !ok || old == "" {
+			return fmt.Errorf("invalid -rename %q, expected OLD=NEW", *rename)
+		}
+
+		m.Rename = func(key string) string {
+			if strings.HasPrefix(key, old) {
+				return repl + key[len(old):]
+			}
+
+			return key
+		}
+	}
+
+	if *dst == "" && m.Rename == nil && !m.DryRun {
+		return errors.New("-dst or -rename is required to copy within the source")
+	}
+
+	srcDB, err := connect(*src, *srcHTTP, *srcPass)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+
+	defer srcDB.Close()
+
+	m.Src = srcDB
+	m.Dst = srcDB
+
+	if *dst != "" {
+		dstDB, err := connect(*dst, *dstHTTP, *dstPass)
+		if err != nil {
+			return fmt.Errorf("destination: %w", err)
+		}
+
+		defer dstDB.Close()
+
+		m.Dst = dstDB
+	}
+
+	results, err := m.Migrate()
+
+	for _, res := range results {
+		line := fmt.Sprintf("%s -> %s: %d objects", res.Key, res.DstKey, res.Objects)
+
+		if res.Expired > 0 {
+			line += fmt.Sprintf(", %d expired", res.Expired)
+		}
+
+		if m.Verify && !m.DryRun {
+			line += fmt.Sprintf(", verified %.12s", res.Source.Hash)
+		}
+
+		fmt.Fprintln(stderr, line)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	verb := "copied"
+	if m.DryRun {
+		verb = "would copy"
+	}
+
+	fmt.Fprintf(stderr, "done: %s %d keys\n", verb, len(results))
+
+	return nil
+}
+
+// connect connects to a server given as host:port, authenticating with
+// password if it is not empty.
+func connect(addr string, useHTTP bool, password string) (*t38c.Database, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []t38c.Option
+
+	switch {
+	case password != "" && useHTTP:
+		return nil, errors.New("a password is not supported with the HTTP interface")
+	case password != "":
+		opts = append(opts, t38c.WithPassword(password))
+	case useHTTP:
+		opts = append(opts, t38c.WithHTTP(nil))
+	}
+
+	return t38c.Connect(host, port, 1, opts...)
+}
+
+// isSet reports whether the named flag was given on the command line.
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// env returns the value of an environment variable, or def if it is not
+// set.
+func env(name string, def string) string {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return def
+	}
+
+	return v
+}
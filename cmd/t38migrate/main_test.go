@@ -0,0 +1,111 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/t38c/t38ctest"
+)
+
+// TestRun tests copying between servers and within a server.
+func TestRun(t *testing.T) {
+	src := t38ctest.NewServer(t)
+	dst := t38ctest.NewServer(t)
+
+	src.Do("SET", "tenant1:fleet", "a", "FIELD", "speed", "10", "POINT", "1", "2")
+	src.Do("SET", "tenant1:fleet", "b", "EX", "60", "POINT", "3", "4")
+
+	srcAddr := net.JoinHostPort(src.Addr, src.Port)
+	dstAddr := net.JoinHostPort(dst.Addr, dst.Port)
+
+	tests := []struct {
+		args []string
+		exp  string
+	}{
+		{[]string{"-src", srcAddr, "-src-password", "secret", "-dry-run", "tenant1:*"}, "tenant1:fleet -> tenant1:fleet: 2 objects\ndone: would copy 1 keys\n"},
+		{[]string{"-src", srcAddr, "-dst", dstAddr, "-dst-http", "-verify", "tenant1:*"}, ", verified "},
+		{[]string{"-src", srcAddr, "-rename", "tenant1:=tenant2:", "-rate", "1000", "*"}, "tenant1:fleet -> tenant2:fleet: 2 objects\n"},
+	}
+
+	for _, tc := range tests {
+		var stderr bytes.Buffer
+
+		err := run(tc.args, &stderr)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+
+		if !strings.Contains(stderr.String(), tc.exp) {
+			t.Errorf("%v: expected output containing %q, received:\n%s", tc.args, tc.exp, stderr.String())
+		}
+	}
+
+	for srv, exp := range map[*t38ctest.Server]string{
+		dst: `"keys":["tenant1:fleet"]`,
+		src: `"keys":["tenant1:fleet","tenant2:fleet"]`,
+	} {
+		if out := srv.Do("KEYS", "*"); !strings.Contains(out, exp) {
+			t.Errorf("KEYS: expected %s, received %s", exp, out)
+		}
+	}
+}
+
+// TestRunErrors tests invalid arguments and failed migrations.
+func TestRunErrors(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+	other := t38ctest.NewServer(t)
+	addr := net.JoinHostPort(srv.Addr, srv.Port)
+
+	srv.Do("SET", "fleet", "a", "POINT", "1", "2")
+	other.Do("SET", "fleet", "b", "POINT", "1", "2")
+
+	tests := map[string][]string{
+		"a key pattern is required":               {"-src", addr},
+		`invalid -rename "fleet"`:                 {"-src", addr, "-rename", "fleet", "*"},
+		"-dst or -rename is required":             {"-src", addr, "*"},
+		"source: address localhost: missing port": {"-src", "localhost", "-dst", addr, "*"},
+		"destination: error connecting to server": {"-src", addr, "-dst", net.JoinHostPort(srv.Addr, "1"), "*"},
+		"verification failed":                     {"-src", addr, "-dst", net.JoinHostPort(other.Addr, other.Port), "-verify", "*"},
+		"password is not supported":               {"-src", addr, "-src-http", "-src-password", "secret", "-dry-run", "*"},
+	}
+
+	for exp, args := range tests {
+		err := run(args, new(bytes.Buffer))
+		if err == nil || !strings.Contains(err.Error(), exp) {
+			t.Errorf("%v: expected %s, received %v", args, exp, err)
+		}
+	}
+
+	t.Setenv("T38C_PASSWORD", "hunter2")
+
+	var stderr bytes.Buffer
+
+	err := run([]string{"-badflag"}, &stderr)
+	if err == nil || strings.Contains(stderr.String(), "hunter2") {
+		t.Errorf("expected usage without the password, received %v:\n%s", err, stderr.String())
+	}
+}
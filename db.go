@@ -23,13 +23,32 @@
 package t38c
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"net"
 	"strconv"
+	"time"
 
-	"github.com/mediocregopher/radix/v3"
+	"kreklow.us/go/t38c/internal/num"
 )
 
+// ErrTimeout is returned when a command exceeds the server-side timeout
+// set with Query.Timeout.
+var ErrTimeout = newError(nil, "timeout")
+
+// ErrKeyNotFound and ErrIDNotFound are returned when a command refers to
+// a collection or object which does not exist, such as one which has
+// expired or been deleted.
+var (
+	ErrKeyNotFound = newError(nil, "key not found")
+	ErrIDNotFound  = newError(nil, "id not found")
+)
+
+// NoExpiry is the TTL of an object which does not expire.
+const NoExpiry time.Duration = -1
+
 // Database errors.
 var (
 	errUninitialized = newError(nil, "database not initialized")
@@ -38,25 +57,52 @@ var (
 )
 
 // Database is the primary object for interacting with the database.
-// Database should not be created directly, instead use Connect() to
-// retrieve a fully-initialized Database ready to be used.
+// Database should not be created directly, instead use Connect() or
+// NewDatabase() to retrieve a fully-initialized Database ready to be
+// used.
 //
 // Functions other than Close() accept arguments in the same form as the
 // Tile38 CLI. See https://tile38.com/commands/ for further information.
 type Database struct {
-	pool *radix.Pool
+	tr          Transport
+	retry       *RetryPolicy
+	breaker     *breaker
+	instruments []Instrument
+	log         *slog.Logger
+	slow        time.Duration
+	password    string
 }
 
-// Connect establishes a connection and returns a Database object.
-func Connect(server string, port string, poolsize int) (db *Database, err error) {
+// Option configures optional behavior of a Database. Options are passed
+// to Connect().
+type Option func(*Database)
+
+// Connect establishes a connection and returns a Database object. By
+// default, commands are sent over a pool of poolsize RESP connections.
+// With the WithHTTP or WithHTTPS options, commands are sent to Tile38's
+// HTTP interface instead and poolsize is ignored.
+func Connect(server string, port string, poolsize int, opts ...Option) (db *Database, err error) {
 	db = new(Database)
 
-	db.pool, err = radix.NewPool(
-		"tcp",
-		net.JoinHostPort(server, port),
-		poolsize,
-		radix.PoolConnFunc(connectJSON),
-	)
+	for _, opt := range opts {
+		opt(db)
+	}
+
+	err = db.breaker.check()
+	if err != nil {
+		return nil, err
+	}
+
+	if ht, ok := db.tr.(*httpTransport); ok {
+		err = ht.connect(db, server, port)
+		if err != nil {
+			return nil, newError(err, "error connecting to server")
+		}
+
+		return db, nil
+	}
+
+	db.tr, err = newPoolTransport(db, net.JoinHostPort(server, port), poolsize)
 	if err != nil {
 		return nil, newError(err, "error connecting to server")
 	}
@@ -64,13 +110,37 @@ func Connect(server string, port string, poolsize int) (db *Database, err error)
 	return db, nil
 }
 
+// NewDatabase returns a Database which sends commands using the
+// supplied Transport. Options which select a transport, such as
+// WithHTTP, are ignored.
+func NewDatabase(t Transport, opts ...Option) (*Database, error) {
+	if t == nil {
+		return nil, errArgs
+	}
+
+	db := new(Database)
+
+	for _, opt := range opts {
+		opt(db)
+	}
+
+	err := db.breaker.check()
+	if err != nil {
+		return nil, err
+	}
+
+	db.tr = t
+
+	return db, nil
+}
+
 // Close closes the database connection.
 func (db *Database) Close() error {
-	if db.pool == nil {
+	if db.tr == nil {
 		return errUninitialized
 	}
 
-	err := db.pool.Close()
+	err := db.tr.Close()
 	if err != nil {
 		err = newError(err, "error closing database connection")
 	}
@@ -80,7 +150,7 @@ func (db *Database) Close() error {
 
 // Set saves an object to the database.
 func (db *Database) Set(key string, id string, args ...string) (err error) {
-	if db.pool == nil {
+	if db.tr == nil {
 		return errUninitialized
 	}
 
@@ -98,10 +168,71 @@ func (db *Database) Set(key string, id string, args ...string) (err error) {
 	return nil
 }
 
+// SetOptions holds the optional arguments of SetObject.
+type SetOptions struct {
+	// Fields are numeric values stored with the object.
+	Fields []Field
+
+	// Expire sets a timeout on the object when greater than zero.
+	Expire time.Duration
+
+	// NX only saves the object if it does not already exist.
+	NX bool
+
+	// XX only saves the object if it already exists.
+	XX bool
+}
+
+// Field is a named numeric value stored with an object.
+type Field struct {
+	Name  string
+	Value float64
+}
+
+// SetObject saves an object to the database with the supplied options,
+// which may be nil. It reports whether the object was written, which is
+// false when an NX or XX condition was not met.
+func (db *Database) SetObject(key string, id string, obj Object, opts *SetOptions) (ok bool, err error) {
+	if db.tr == nil {
+		return false, errUninitialized
+	}
+
+	if obj == nil {
+		return false, errArgs
+	}
+
+	if opts == nil {
+		opts = new(SetOptions)
+	}
+
+	cmdargs, err := opts.args(key, id)
+	if err != nil {
+		return false, err
+	}
+
+	objargs, err := obj.ObjectArgs()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = db.runcmd("SET", append(cmdargs, objargs...)...)
+	if err != nil {
+		switch {
+		case opts.NX && err.Error() == "received error: id already exists",
+			opts.XX && errors.Is(err, ErrIDNotFound):
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
 // Get returns the requested entry as a response object, or nil if the
 // object is not found.
 func (db *Database) Get(key string, id string, args ...string) (r *Response, err error) {
-	if db.pool == nil {
+	if db.tr == nil {
 		return nil, errUninitialized
 	}
 
@@ -113,7 +244,7 @@ func (db *Database) Get(key string, id string, args ...string) (r *Response, err
 
 	r, err = db.runcmd("GET", cmdargs...)
 	if err != nil {
-		if err.Error() == "received error: id not found" {
+		if errors.Is(err, ErrIDNotFound) {
 			return nil, nil //nolint:nilnil // nil, nil expected when not found
 		}
 
@@ -125,7 +256,7 @@ func (db *Database) Get(key string, id string, args ...string) (r *Response, err
 
 // Scan iterates through a key returning a set of results.
 func (db *Database) Scan(key string, args ...string) (r *Response, err error) {
-	if db.pool == nil {
+	if db.tr == nil {
 		return nil, errUninitialized
 	}
 
@@ -146,7 +277,7 @@ func (db *Database) Scan(key string, args ...string) (r *Response, err error) {
 // Search iterates through the string values of a key returning a set of
 // results.
 func (db *Database) Search(key string, args ...string) (r *Response, err error) {
-	if db.pool == nil {
+	if db.tr == nil {
 		return nil, errUninitialized
 	}
 
@@ -164,9 +295,110 @@ func (db *Database) Search(key string, args ...string) (r *Response, err error)
 	return r, nil
 }
 
+// Nearby returns the objects in a key nearest to a point.
+func (db *Database) Nearby(key string, args ...string) (r *Response, err error) {
+	return db.spatial("NEARBY", key, args...)
+}
+
+// Within returns the objects in a key which are fully contained within
+// an area.
+func (db *Database) Within(key string, args ...string) (r *Response, err error) {
+	return db.spatial("WITHIN", key, args...)
+}
+
+// Intersects returns the objects in a key which intersect an area.
+func (db *Database) Intersects(key string, args ...string) (r *Response, err error) {
+	return db.spatial("INTERSECTS", key, args...)
+}
+
+// ScanQuery iterates through a key using the options in q.
+func (db *Database) ScanQuery(key string, q *Query) (r *Response, err error) {
+	return db.query("SCAN", key, q)
+}
+
+// ScanStream iterates through a key using the options in q like
+// ScanQuery, calling fn with each object as it is read from the
+// connection rather than collecting them in Response.Objects, so the
+// memory used does not grow with the size of the reply. The returned
+// Response holds the other members of the reply, such as the cursor.
+//
+// The obj slice passed to fn is only valid until fn returns. If fn
+// returns an error, iteration stops and ScanStream returns that error.
+// A command is not retried once fn has been called.
+func (db *Database) ScanStream(key string, q *Query, fn ObjectFunc) (r *Response, err error) {
+	if fn == nil {
+		return nil, errArgs
+	}
+
+	return db.stream(fn, "SCAN", key, q)
+}
+
+// SearchQuery iterates through the string values of a key using the
+// options in q.
+func (db *Database) SearchQuery(key string, q *Query) (r *Response, err error) {
+	return db.query("SEARCH", key, q)
+}
+
+// NearbyQuery returns the objects in a key nearest to a point using the
+// options in q. The area arguments follow the options, for example
+// "POINT", "33.5", "-112.2".
+func (db *Database) NearbyQuery(key string, q *Query, area ...string) (r *Response, err error) {
+	if area == nil {
+		return nil, errArgs
+	}
+
+	return db.query("NEARBY", key, q, area...)
+}
+
+// WithinQuery returns the objects in a key which are fully contained
+// within an area using the options in q.
+func (db *Database) WithinQuery(key string, q *Query, area ...string) (r *Response, err error) {
+	if area == nil {
+		return nil, errArgs
+	}
+
+	return db.query("WITHIN", key, q, area...)
+}
+
+// IntersectsQuery returns the objects in a key which intersect an area
+// using the options in q.
+func (db *Database) IntersectsQuery(key string, q *Query, area ...string) (r *Response, err error) {
+	if area == nil {
+		return nil, errArgs
+	}
+
+	return db.query("INTERSECTS", key, q, area...)
+}
+
+// WithinArea returns the objects in a key which are fully contained
+// within an Area using the options in q, which may be nil.
+func (db *Database) WithinArea(key string, q *Query, a Area) (r *Response, err error) {
+	return db.areaQuery("WITHIN", key, q, a)
+}
+
+// IntersectsArea returns the objects in a key which intersect an Area
+// using the options in q, which may be nil.
+func (db *Database) IntersectsArea(key string, q *Query, a Area) (r *Response, err error) {
+	return db.areaQuery("INTERSECTS", key, q, a)
+}
+
+// areaQuery runs a query command with the arguments of an Area.
+func (db *Database) areaQuery(cmd string, key string, q *Query, a Area) (r *Response, err error) {
+	if a == nil {
+		return nil, errArgs
+	}
+
+	area, err := a.AreaArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.query(cmd, key, q, area...)
+}
+
 // Del deletes the requested entry.
 func (db *Database) Del(key string, id string) (err error) {
-	if db.pool == nil {
+	if db.tr == nil {
 		return errUninitialized
 	}
 
@@ -180,7 +412,7 @@ func (db *Database) Del(key string, id string) (err error) {
 
 // PDel deletes any entries matching the supplied pattern.
 func (db *Database) PDel(key string, pattern string) (err error) {
-	if db.pool == nil {
+	if db.tr == nil {
 		return errUninitialized
 	}
 
@@ -194,7 +426,7 @@ func (db *Database) PDel(key string, pattern string) (err error) {
 
 // Expire sets or resets the timeout value on the requested entry.
 func (db *Database) Expire(key string, id string, seconds int) (err error) {
-	if db.pool == nil {
+	if db.tr == nil {
 		return errUninitialized
 	}
 
@@ -206,9 +438,28 @@ func (db *Database) Expire(key string, id string, seconds int) (err error) {
 	return nil
 }
 
+// ExpireDuration sets or resets the timeout value on the requested
+// entry with sub-second precision.
+func (db *Database) ExpireDuration(key string, id string, d time.Duration) (err error) {
+	if db.tr == nil {
+		return errUninitialized
+	}
+
+	if d < 0 {
+		return errArgs
+	}
+
+	_, err = db.runcmd("EXPIRE", key, id, num.Format(d.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Persist removes the timeout value on the requested entry.
 func (db *Database) Persist(key string, id string) (err error) {
-	if db.pool == nil {
+	if db.tr == nil {
 		return errUninitialized
 	}
 
@@ -222,7 +473,7 @@ func (db *Database) Persist(key string, id string) (err error) {
 
 // TTL returns the timeout value on the requested entry.
 func (db *Database) TTL(key string, id string) (ttl float64, err error) {
-	if db.pool == nil {
+	if db.tr == nil {
 		return 0, errUninitialized
 	}
 
@@ -234,47 +485,293 @@ func (db *Database) TTL(key string, id string) (ttl float64, err error) {
 	return r.TTL, nil
 }
 
-// runcmd runs a command against the database.
+// TTLDuration returns the timeout value on the requested entry as a
+// time.Duration, or NoExpiry if the entry does not expire.
+func (db *Database) TTLDuration(key string, id string) (ttl time.Duration, err error) {
+	if db.tr == nil {
+		return 0, errUninitialized
+	}
+
+	r, err := db.runcmd("TTL", key, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.TTLDuration(), nil
+}
+
+// args validates the options and returns them as arguments following
+// the key and id.
+func (o *SetOptions) args(key string, id string) ([]string, error) {
+	if o.NX && o.XX {
+		return nil, newError(nil, "invalid options: NX and XX are exclusive")
+	}
+
+	cmdargs := []string{key, id}
+
+	for _, f := range o.Fields {
+		if f.Name == "" || math.IsNaN(f.Value) {
+			return nil, newErrorf(nil, "invalid options: invalid field %q", f.Name)
+		}
+
+		cmdargs = append(cmdargs, "FIELD", f.Name, num.Format(f.Value))
+	}
+
+	if o.Expire > 0 {
+		cmdargs = append(cmdargs, "EX", num.Format(o.Expire.Seconds()))
+	}
+
+	switch {
+	case o.NX:
+		cmdargs = append(cmdargs, "NX")
+	case o.XX:
+		cmdargs = append(cmdargs, "XX")
+	}
+
+	return cmdargs, nil
+}
+
+// spatial runs a spatial query with raw arguments.
+func (db *Database) spatial(cmd string, key string, args ...string) (r *Response, err error) {
+	if db.tr == nil {
+		return nil, errUninitialized
+	}
+
+	if args == nil {
+		return nil, errArgs
+	}
+
+	r, err = db.runcmd(cmd, append([]string{key}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// query runs a query command with the options from q followed by the
+// optional area arguments.
+func (db *Database) query(cmd string, key string, q *Query, area ...string) (r *Response, err error) {
+	return db.stream(nil, cmd, key, q, area...)
+}
+
+// stream runs a query command like query, calling fn with each object
+// as it is decoded if fn is not nil.
+func (db *Database) stream(fn ObjectFunc, cmd string, key string, q *Query, area ...string) (r *Response, err error) {
+	if db.tr == nil {
+		return nil, errUninitialized
+	}
+
+	opts, err := q.Args(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdargs := append(append([]string{key}, opts...), area...)
+
+	if q != nil && q.timeout > 0 {
+		cmdargs = append([]string{num.Format(q.timeout.Seconds()), cmd}, cmdargs...)
+		cmd = "TIMEOUT"
+	}
+
+	r, err = db.run(fn, cmd, cmdargs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// runcmd runs a command against the database, retrying according to
+// the configured RetryPolicy and subject to the circuit breaker. The
+// result is reported to any configured Instrument.
 func (db *Database) runcmd(cmd string, args ...string) (r *Response, err error) {
+	return db.run(nil, cmd, args...)
+}
+
+// run runs a command like runcmd, calling fn with each object as it is
+// decoded if fn is not nil. A command is not retried once fn has been
+// called.
+func (db *Database) run(fn ObjectFunc, cmd string, args ...string) (r *Response, err error) {
 	if args == nil {
 		return nil, errArgs
 	}
 
+	name, nameargs := unwrapTimeout(cmd, args)
+
+	e := &CommandEvent{
+		Command: name,
+		Key:     commandKey(name, nameargs),
+		Start:   time.Now(),
+	}
+
+	var streamed, failed bool
+
+	if fn != nil {
+		f := fn
+		fn = func(r *Response, obj []byte) error {
+			streamed = true
+
+			err := f(r, obj)
+			failed = err != nil
+
+			return err
+		}
+	}
+
+	err = db.attempt(e, nameargs, func() error {
+		r, err = db.do(fn, cmd, args...)
+		if err != nil && streamed {
+			return &finalError{err: err, caller: failed}
+		}
+
+		if r != nil {
+			e.Elapsed, _ = time.ParseDuration(r.Elapsed)
+		}
+
+		return err
+	})
+
+	return r, err
+}
+
+// finalError wraps an error from an attempt which must not be retried.
+// caller is set if the error was returned by the caller's ObjectFunc
+// rather than the server.
+type finalError struct {
+	err    error
+	caller bool
+}
+
+// Error returns the string value of the wrapped error.
+func (e *finalError) Error() string {
+	return e.err.Error()
+}
+
+// attempt calls try, retrying according to the configured RetryPolicy
+// and subject to the circuit breaker, then reports e to any configured
+// Instrument and Logger. args are the arguments of e.Command, used to
+// decide whether it may be retried.
+func (db *Database) attempt(e *CommandEvent, args []string, try func() error) (err error) {
+	for e.Attempts = 1; ; e.Attempts++ {
+		final, caller := false, false
+
+		err = db.breaker.allow()
+		if err == nil {
+			err = try()
+
+			var ferr *finalError
+			if errors.As(err, &ferr) {
+				err, final, caller = ferr.err, true, ferr.caller
+			}
+
+			// an error from the caller says nothing about the server
+			if !caller {
+				db.breaker.record(db.retry.retryable(err))
+			}
+		}
+
+		if err == nil || final || !db.retry.allowed(e.Command, args, e.Attempts, err) {
+			break
+		}
+
+		time.Sleep(db.retry.backoff(e.Attempts))
+	}
+
+	e.Err = err
+	e.Duration = time.Since(e.Start)
+	e.Class = Classify(err)
+
+	db.observe(e)
+	db.logCommand(e)
+
+	return err
+}
+
+// do sends a single command to the database, streaming the objects in
+// the reply to fn if it is not nil. Errors returned by fn are returned
+// unchanged.
+func (db *Database) do(fn ObjectFunc, cmd string, args ...string) (r *Response, err error) {
 	r = new(Response)
 
-	err = db.pool.Do(radix.Cmd(r, cmd, args...))
+	switch st, ok := db.tr.(Streamer); {
+	case fn == nil:
+		err = db.tr.Do(r, cmd, args...)
+	case ok:
+		err = st.Stream(r, fn, cmd, args...)
+	default:
+		err = db.tr.Do(r, cmd, args...)
+		if err == nil && r.Ok {
+			err = streamObjects(r, fn)
+		}
+	}
+
+	var ferr *objectFuncError
+	if errors.As(err, &ferr) {
+		return nil, ferr.err
+	}
+
 	if err != nil {
 		return nil, newError(err, "database error")
 	}
 
 	if !r.Ok {
-		return nil, fmt.Errorf("%w: %s", errResponse, r.Err)
+		return nil, responseError(r.Err)
 	}
 
 	return r, nil
 }
 
-// connectJSON creates a connection and sets the output mode to JSON.
-func connectJSON(net, addr string) (conn radix.Conn, err error) { //nolint:ireturn // radix.Conn is passed through
-	conn, err = radix.Dial(net, addr)
-	if err != nil {
-		return nil, newError(err, "error connecting to database")
+// responseError returns the error for a reply with ok set to false,
+// wrapping ErrTimeout, ErrKeyNotFound or ErrIDNotFound if msg matches.
+func responseError(msg string) error {
+	for _, e := range []*t38cError{ErrTimeout, ErrKeyNotFound, ErrIDNotFound} {
+		if msg == e.msg {
+			return fmt.Errorf("%w: %w", errResponse, e)
+		}
 	}
 
-	resp := new(Response)
+	return fmt.Errorf("%w: %s", errResponse, msg)
+}
 
-	err = conn.Do(radix.Cmd(resp, "OUTPUT", "json"))
-	if err != nil {
-		conn.Close() //nolint:errcheck // Close() in error path
+// unwrapTimeout returns the command and arguments wrapped by a TIMEOUT
+// prefix, or cmd and args unchanged if there is no prefix.
+func unwrapTimeout(cmd string, args []string) (string, []string) {
+	if cmd == "TIMEOUT" && len(args) > 2 { //nolint:mnd // seconds, command and key
+		return args[1], args[2:]
+	}
 
-		return nil, newError(err, "error setting output to JSON")
+	return cmd, args
+}
+
+// commandKey returns the collection key used by a command, or an empty
+// string if the command does not use one.
+func commandKey(cmd string, args []string) string {
+	switch cmd {
+	case "KEYS", "SCRIPT", "TEST":
+		return ""
+	case "EVAL", "EVALSHA", "EVALRO", "EVALROSHA", "EVALNA", "EVALNASHA":
+		if len(args) > 2 && args[1] != "0" {
+			return args[2]
+		}
+
+		return ""
+	default:
+		return args[0]
 	}
+}
 
-	if !resp.Ok {
-		conn.Close() //nolint:errcheck // Close() in error path
+// Keys returns the keys matching the supplied pattern, such as "*" for
+// all keys.
+func (db *Database) Keys(pattern string) (keys []string, err error) {
+	if db.tr == nil {
+		return nil, errUninitialized
+	}
 
-		return nil, fmt.Errorf("%w: %s", errResponse, resp.Err)
+	r, err := db.runcmd("KEYS", pattern)
+	if err != nil {
+		return nil, err
 	}
 
-	return conn, nil
+	return r.Keys, nil
 }
@@ -23,11 +23,12 @@
 package t38c_test
 
 import (
-	"bytes"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/tidwall/resp"
 	"kreklow.us/go/t38c"
@@ -47,9 +48,12 @@ var (
 	}
 
 	testRespErrFuncs = map[string][]any{
-		"Get":    {"test", "obj1"},
-		"Scan":   {"test"},
-		"Search": {"test"},
+		"Get":        {"test", "obj1"},
+		"Scan":       {"test"},
+		"Search":     {"test"},
+		"Nearby":     {"test", "POINT", "33.5", "-112.2"},
+		"Within":     {"test", "BOUNDS", "33", "-113", "34", "-112"},
+		"Intersects": {"test", "HASH", "9tbq"},
 	}
 )
 
@@ -79,9 +83,9 @@ func testConnectFail(t *testing.T) {
 // Test Connect with error from server.
 func testConnectError(t *testing.T) {
 	srv.HandleFunc("OUTPUT", srv.ReturnErr)
-	srv.DataIn.Reset()
+	srv.ResetCommands()
 
-	db, err := t38c.Connect("127.0.0.1", "9876", 1)
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
 	if err == nil {
 		tFatalNoErr(t, "Connect")
 	}
@@ -95,18 +99,15 @@ func testConnectError(t *testing.T) {
 		tErrorStr(t, "DB", "nil", "not nil")
 	}
 
-	expData := []byte("OUTPUT json")
-	if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-		tErrorStr(t, "Data", expData, srv.DataIn.Bytes())
-	}
+	tCommands(t, "Data", "OUTPUT json")
 }
 
 // Test Connect with false response from server.
 func testConnectFalse(t *testing.T) {
 	srv.HandleFunc("OUTPUT", srv.ReturnOkFalse)
-	srv.DataIn.Reset()
+	srv.ResetCommands()
 
-	db, err := t38c.Connect("127.0.0.1", "9876", 1)
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
 	if err == nil {
 		tFatalNoErr(t, "Connect")
 	}
@@ -120,10 +121,52 @@ func testConnectFalse(t *testing.T) {
 		tErrorStr(t, "DB", "nil", "not nil")
 	}
 
-	expData := []byte("OUTPUT json")
-	if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-		tErrorStr(t, "Data", expData, srv.DataIn.Bytes())
+	tCommands(t, "Data", "OUTPUT json")
+}
+
+// Test Connect with a password.
+func TestConnectPassword(t *testing.T) {
+	t.Run("Ok", testConnectPasswordOk)
+	t.Run("False", testConnectPasswordFalse)
+}
+
+// Test Connect sending AUTH before OUTPUT.
+func testConnectPasswordOk(t *testing.T) {
+	srv.HandleFunc("AUTH", srv.ReturnOkTrue)
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+	srv.ResetCommands()
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1, t38c.WithPassword("secret"))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
 	}
+
+	defer db.Close()
+
+	tCommands(t, "Data", "AUTH secret", "OUTPUT json")
+}
+
+// Test Connect with a rejected password.
+func testConnectPasswordFalse(t *testing.T) {
+	srv.HandleFunc("AUTH", srv.ReturnOkFalse)
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+	srv.ResetCommands()
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1, t38c.WithPassword("secret"))
+	if err == nil {
+		tFatalNoErr(t, "Connect")
+	}
+
+	expErr := "error connecting to server: received error: " + mock.TestOkFalse
+	if err.Error() != expErr {
+		tErrorStr(t, "Connect", expErr, err)
+	}
+
+	if db != nil {
+		tErrorStr(t, "DB", "nil", "not nil")
+	}
+
+	tCommands(t, "Data", "AUTH secret")
 }
 
 // Test uninitialized errors.
@@ -211,9 +254,9 @@ func testCommandErr(t *testing.T, hf func(*resp.Conn, []resp.Value) bool, expErr
 	t.Helper()
 
 	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
-	srv.DataIn.Reset()
+	srv.ResetCommands()
 
-	db, err := t38c.Connect("127.0.0.1", "9876", 1)
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
 	if err != nil {
 		tFatalErr(t, "Connect", err)
 	}
@@ -222,10 +265,7 @@ func testCommandErr(t *testing.T, hf func(*resp.Conn, []resp.Value) bool, expErr
 		t.Fatal("Connect: no db returned")
 	}
 
-	expData := []byte("OUTPUT json")
-	if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-		tErrorStr(t, "Data", expData, srv.DataIn.Bytes())
-	}
+	tCommands(t, "Data", "OUTPUT json")
 
 	for f, args := range testErrFuncs {
 		cmd := strings.ToUpper(f)
@@ -236,7 +276,7 @@ func testCommandErr(t *testing.T, hf func(*resp.Conn, []resp.Value) bool, expErr
 		}
 
 		srv.HandleFunc(cmd, hf)
-		srv.DataIn.Reset()
+		srv.ResetCommands()
 
 		ret := reflectMethod(db, f, args...)
 		if len(ret) != 1 {
@@ -250,10 +290,7 @@ func testCommandErr(t *testing.T, hf func(*resp.Conn, []resp.Value) bool, expErr
 			tErrorStr(t, f, expErr, err)
 		}
 
-		expData = []byte(strings.Join(txtargs, " "))
-		if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-			tErrorStr(t, f, expData, srv.DataIn.Bytes())
-		}
+		tCommands(t, f, strings.Join(txtargs, " "))
 	}
 
 	for f, args := range testRespErrFuncs {
@@ -265,7 +302,7 @@ func testCommandErr(t *testing.T, hf func(*resp.Conn, []resp.Value) bool, expErr
 		}
 
 		srv.HandleFunc(cmd, hf)
-		srv.DataIn.Reset()
+		srv.ResetCommands()
 
 		ret := reflectMethod(db, f, args...)
 		if len(ret) != 2 {
@@ -286,14 +323,11 @@ func testCommandErr(t *testing.T, hf func(*resp.Conn, []resp.Value) bool, expErr
 			t.Errorf("%s: received non-nil response: %v", f, resp)
 		}
 
-		expData = []byte(strings.Join(txtargs, " "))
-		if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-			tErrorStr(t, f, expData, srv.DataIn.Bytes())
-		}
+		tCommands(t, f, strings.Join(txtargs, " "))
 	}
 
 	srv.HandleFunc("TTL", hf)
-	srv.DataIn.Reset()
+	srv.ResetCommands()
 
 	ttl, err := db.TTL("test", "obj1")
 	if err == nil {
@@ -306,10 +340,7 @@ func testCommandErr(t *testing.T, hf func(*resp.Conn, []resp.Value) bool, expErr
 		tErrorVal(t, "TTL", 0, ttl)
 	}
 
-	expData = []byte("TTL test obj1")
-	if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-		tErrorStr(t, "TTL", expData, srv.DataIn.Bytes())
-	}
+	tCommands(t, "TTL", "TTL test obj1")
 
 	err = db.Close()
 	if err != nil {
@@ -320,9 +351,9 @@ func testCommandErr(t *testing.T, hf func(*resp.Conn, []resp.Value) bool, expErr
 // Test Set with no arguments.
 func testCommandSetNoArgs(t *testing.T) {
 	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
-	srv.DataIn.Reset()
+	srv.ResetCommands()
 
-	db, err := t38c.Connect("127.0.0.1", "9876", 1)
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
 	if err != nil {
 		tFatalErr(t, "Connect", err)
 	}
@@ -331,13 +362,10 @@ func testCommandSetNoArgs(t *testing.T) {
 		t.Fatal("Connect: no db returned")
 	}
 
-	expData := []byte("OUTPUT json")
-	if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-		tErrorStr(t, "Data", expData, srv.DataIn.Bytes())
-	}
+	tCommands(t, "Data", "OUTPUT json")
 
 	srv.HandleFunc("SET", srv.ReturnOkTrue)
-	srv.DataIn.Reset()
+	srv.ResetCommands()
 
 	expErr := "invalid arguments"
 
@@ -348,10 +376,7 @@ func testCommandSetNoArgs(t *testing.T) {
 		tErrorStr(t, "Set", expErr, err)
 	}
 
-	expData = []byte{}
-	if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-		tErrorStr(t, "Set", expData, srv.DataIn.Bytes())
-	}
+	tCommands(t, "Set")
 
 	err = db.Close()
 	if err != nil {
@@ -362,9 +387,9 @@ func testCommandSetNoArgs(t *testing.T) {
 // Test Get not found error.
 func testCommandGetNotFound(t *testing.T) {
 	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
-	srv.DataIn.Reset()
+	srv.ResetCommands()
 
-	db, err := t38c.Connect("127.0.0.1", "9876", 1)
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
 	if err != nil {
 		tFatalErr(t, "Connect", err)
 	}
@@ -373,13 +398,10 @@ func testCommandGetNotFound(t *testing.T) {
 		t.Fatal("Connect: no db returned")
 	}
 
-	expData := []byte("OUTPUT json")
-	if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-		tErrorStr(t, "Data", expData, srv.DataIn.Bytes())
-	}
+	tCommands(t, "Data", "OUTPUT json")
 
 	srv.HandleFunc("GET", srv.ReturnOkNotFound)
-	srv.DataIn.Reset()
+	srv.ResetCommands()
 
 	r, err := db.Get("test", "value1")
 	if err != nil {
@@ -390,10 +412,7 @@ func testCommandGetNotFound(t *testing.T) {
 		tErrorVal(t, "Get", "nil", r)
 	}
 
-	expData = []byte("GET test value1")
-	if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-		tErrorStr(t, "Get", expData, srv.DataIn.Bytes())
-	}
+	tCommands(t, "Get", "GET test value1")
 
 	err = db.Close()
 	if err != nil {
@@ -404,9 +423,9 @@ func testCommandGetNotFound(t *testing.T) {
 // Run test commands expecting success.
 func testCommandSuccess(t *testing.T) {
 	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
-	srv.DataIn.Reset()
+	srv.ResetCommands()
 
-	db, err := t38c.Connect("127.0.0.1", "9876", 1)
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
 	if err != nil {
 		tFatalErr(t, "Connect", err)
 	}
@@ -415,10 +434,7 @@ func testCommandSuccess(t *testing.T) {
 		t.Fatal("Connect: no db returned")
 	}
 
-	expData := []byte("OUTPUT json")
-	if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-		tErrorStr(t, "Data", expData, srv.DataIn.Bytes())
-	}
+	tCommands(t, "Data", "OUTPUT json")
 
 	expErr := "nope"
 
@@ -431,7 +447,7 @@ func testCommandSuccess(t *testing.T) {
 		}
 
 		srv.HandleFunc(cmd, srv.ReturnOkTrue)
-		srv.DataIn.Reset()
+		srv.ResetCommands()
 
 		ret := reflectMethod(db, f, args...)
 		if len(ret) != 1 {
@@ -445,10 +461,7 @@ func testCommandSuccess(t *testing.T) {
 			tFatalErr(t, f, err)
 		}
 
-		expData = []byte(strings.Join(txtargs, " "))
-		if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-			tErrorStr(t, f, expData, srv.DataIn.Bytes())
-		}
+		tCommands(t, f, strings.Join(txtargs, " "))
 	}
 
 	for f, args := range testRespErrFuncs {
@@ -460,7 +473,7 @@ func testCommandSuccess(t *testing.T) {
 		}
 
 		srv.HandleFunc(cmd, srv.ReturnOkTrue)
-		srv.DataIn.Reset()
+		srv.ResetCommands()
 
 		ret := reflectMethod(db, f, args...)
 		if len(ret) != 2 {
@@ -485,14 +498,11 @@ func testCommandSuccess(t *testing.T) {
 			tErrorStr(t, f, mock.TestObject, resp.Object)
 		}
 
-		expData = []byte(strings.Join(txtargs, " "))
-		if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-			tErrorStr(t, f, expData, srv.DataIn.Bytes())
-		}
+		tCommands(t, f, strings.Join(txtargs, " "))
 	}
 
 	srv.HandleFunc("TTL", srv.ReturnOkTrue)
-	srv.DataIn.Reset()
+	srv.ResetCommands()
 
 	ttl, err := db.TTL("test", "obj1")
 	if err != nil {
@@ -503,10 +513,7 @@ func testCommandSuccess(t *testing.T) {
 		tErrorVal(t, "TTL", mock.TestTTL, ttl)
 	}
 
-	expData = []byte("TTL test obj1")
-	if !bytes.Equal(expData, srv.DataIn.Bytes()) {
-		tErrorStr(t, "TTL", expData, srv.DataIn.Bytes())
-	}
+	tCommands(t, "TTL", "TTL test obj1")
 
 	err = db.Close()
 	if err != nil {
@@ -514,6 +521,91 @@ func testCommandSuccess(t *testing.T) {
 	}
 }
 
+// Test SetObject with the mock server.
+func TestSetObject(t *testing.T) {
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+	srv.ResetCommands()
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	pt := t38c.Point{Lat: 33.5, Lon: -112.25}
+
+	tests := map[string]struct {
+		opts *t38c.SetOptions
+		hf   func(*resp.Conn, []resp.Value) bool
+		ok   bool
+		exp  string
+	}{
+		"Nil Options": {nil, srv.ReturnOkTrue, true, "SET test obj1 POINT 33.5 -112.25"},
+		"All Options": {
+			&t38c.SetOptions{
+				Fields: []t38c.Field{{Name: "speed", Value: 90}, {Name: "age", Value: 1.5}},
+				Expire: 1500 * time.Millisecond,
+				NX:     true,
+			},
+			srv.ReturnOkTrue, true,
+			"SET test obj1 FIELD speed 90 FIELD age 1.5 EX 1.5 NX POINT 33.5 -112.25",
+		},
+		"NX Exists":    {&t38c.SetOptions{NX: true}, srv.ReturnOkExists, false, "SET test obj1 NX POINT 33.5 -112.25"},
+		"XX Not Found": {&t38c.SetOptions{XX: true}, srv.ReturnOkNotFound, false, "SET test obj1 XX POINT 33.5 -112.25"},
+	}
+
+	for name, tc := range tests {
+		srv.HandleFunc("SET", tc.hf)
+		srv.ResetCommands()
+
+		ok, err := db.SetObject("test", "obj1", pt, tc.opts)
+		if err != nil {
+			tErrorStr(t, name, "nil", err)
+		}
+
+		if ok != tc.ok {
+			tErrorVal(t, name, tc.ok, ok)
+		}
+
+		tCommands(t, name, tc.exp)
+	}
+
+	errs := map[string]struct {
+		obj  t38c.Object
+		opts *t38c.SetOptions
+		hf   func(*resp.Conn, []resp.Value) bool
+		exp  string
+	}{
+		"Nil Object": {nil, nil, srv.ReturnOkTrue, "invalid arguments"},
+		"Exclusive":  {pt, &t38c.SetOptions{NX: true, XX: true}, srv.ReturnOkTrue, "invalid options: NX and XX are exclusive"},
+		"Field":      {pt, &t38c.SetOptions{Fields: []t38c.Field{{}}}, srv.ReturnOkTrue, `invalid options: invalid field ""`},
+		"Object":     {t38c.Point{Lat: 100}, nil, srv.ReturnOkTrue, "invalid object: latitude 100 out of range"},
+		"Exists":     {pt, nil, srv.ReturnOkExists, "received error: " + mock.IDExists},
+		"Server":     {pt, &t38c.SetOptions{NX: true}, srv.ReturnOkFalse, "received error: " + mock.TestOkFalse},
+	}
+
+	for name, tc := range errs {
+		srv.HandleFunc("SET", tc.hf)
+
+		ok, err := db.SetObject("test", "obj1", tc.obj, tc.opts)
+		if err == nil {
+			tErrorStr(t, name, "error", "nil")
+		} else if err.Error() != tc.exp {
+			tErrorStr(t, name, tc.exp, err)
+		}
+
+		if ok {
+			tErrorVal(t, name, false, ok)
+		}
+	}
+
+	_, err = new(t38c.Database).SetObject("test", "obj1", pt, nil)
+	if err == nil || err.Error() != "database not initialized" {
+		tErrorStr(t, "Uninitialized", "database not initialized", err)
+	}
+}
+
 // reflectMethod runs a method by reflection.
 func reflectMethod(db *t38c.Database, m string, args ...any) []any {
 	dbVal := reflect.ValueOf(db)
@@ -533,3 +625,196 @@ func reflectMethod(db *t38c.Database, m string, args ...any) []any {
 
 	return r
 }
+
+// TestCommandGolden tests the commands sent for a sequence of calls
+// against a golden file.
+func TestCommandGolden(t *testing.T) {
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	for _, cmd := range []string{"SET", "GET", "SCAN", "WITHIN", "EXPIRE", "DEL"} {
+		srv.HandleFunc(cmd, srv.ReturnOkTrue)
+	}
+
+	srv.ResetCommands()
+
+	_, err = db.SetObject("fleet", "truck 1", t38c.Point{Lat: 33.5, Lon: -112.25},
+		&t38c.SetOptions{Fields: []t38c.Field{{Name: "speed", Value: 90}}})
+	if err != nil {
+		tFatalErr(t, "SetObject", err)
+	}
+
+	_, err = db.Get("fleet", "truck 1", "WITHFIELDS")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	_, err = db.ScanQuery("fleet", t38c.NewQuery().Match("truck*").Where("speed", 50, 100))
+	if err != nil {
+		tFatalErr(t, "ScanQuery", err)
+	}
+
+	_, err = db.WithinQuery("fleet", t38c.NewQuery().Output(t38c.OutputIDs), "HASH", "9tbq")
+	if err != nil {
+		tFatalErr(t, "WithinQuery", err)
+	}
+
+	err = db.Expire("fleet", "truck 1", 60)
+	if err != nil {
+		tFatalErr(t, "Expire", err)
+	}
+
+	err = db.Del("fleet", "truck 1")
+	if err != nil {
+		tFatalErr(t, "Del", err)
+	}
+
+	srv.AssertGolden(t, "testdata/commands.golden")
+}
+
+// TestConcurrentCommands tests recording commands sent concurrently
+// over several connections.
+func TestConcurrentCommands(t *testing.T) {
+	const n = 20
+
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 4)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	srv.HandleFunc("GET", srv.ReturnOkTrue)
+	srv.ResetCommands()
+
+	var wg sync.WaitGroup
+
+	for i := range n {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := db.Get("fleet", fmt.Sprint(i))
+			if err != nil {
+				t.Errorf("Get - received unexpected error: %s", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	ids := make(map[string]bool)
+
+	for _, c := range srv.Commands() {
+		if c.Args[0] == "OUTPUT" {
+			continue // new pool connections
+		}
+
+		if len(c.Args) != 3 || c.Args[0] != "GET" || c.Conn < 1 {
+			tErrorVal(t, "Command", "GET fleet id", c)
+		}
+
+		ids[c.Args[len(c.Args)-1]] = true
+	}
+
+	if len(ids) != n {
+		tErrorVal(t, "Commands", n, len(ids))
+	}
+}
+
+// Test Keys.
+func TestKeys(t *testing.T) {
+	var cmd string
+
+	db, err := t38c.NewDatabase(t38c.TransportFunc(func(r *t38c.Response, c string, args ...string) error {
+		cmd = c + " " + strings.Join(args, " ")
+
+		return r.UnmarshalText([]byte(`{"ok":true,"keys":["fleet","fleet:archive"]}`))
+	}))
+	if err != nil {
+		tFatalErr(t, "NewDatabase", err)
+	}
+
+	keys, err := db.Keys("fleet*")
+	if err != nil {
+		tFatalErr(t, "Keys", err)
+	}
+
+	if cmd != "KEYS fleet*" {
+		tErrorStr(t, "Keys", "KEYS fleet*", cmd)
+	}
+
+	if strings.Join(keys, ",") != "fleet,fleet:archive" {
+		tErrorStr(t, "Keys", "fleet,fleet:archive", strings.Join(keys, ","))
+	}
+
+	_, err = new(t38c.Database).Keys("*")
+	if err == nil || err.Error() != "database not initialized" {
+		tErrorStr(t, "Keys", "database not initialized", err)
+	}
+}
+
+// Test ExpireDuration and TTLDuration.
+func TestDurations(t *testing.T) {
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+	srv.HandleFunc("EXPIRE", srv.ReturnOkTrue)
+	srv.HandleFunc("TTL", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	srv.ResetCommands()
+
+	err = db.ExpireDuration("test", "obj1", 1500*time.Millisecond)
+	if err != nil {
+		tFatalErr(t, "ExpireDuration", err)
+	}
+
+	tCommands(t, "ExpireDuration", "EXPIRE test obj1 1.5")
+
+	err = db.ExpireDuration("test", "obj1", -time.Second)
+	if err == nil || err.Error() != "invalid arguments" {
+		tErrorStr(t, "ExpireDuration", "invalid arguments", err)
+	}
+
+	ttl, err := db.TTLDuration("test", "obj1")
+	if err != nil {
+		tFatalErr(t, "TTLDuration", err)
+	}
+
+	if exp := 5670 * time.Millisecond; ttl != exp {
+		tErrorVal(t, "TTLDuration", exp, ttl)
+	}
+
+	srv.HandleFunc("TTL", srv.ReturnOkFalse)
+
+	_, err = db.TTLDuration("test", "obj1")
+	if err == nil || err.Error() != "received error: "+mock.TestOkFalse {
+		tErrorStr(t, "TTLDuration", "received error: "+mock.TestOkFalse, err)
+	}
+
+	uninit := new(t38c.Database)
+
+	err = uninit.ExpireDuration("test", "obj1", time.Second)
+	if err == nil || err.Error() != "database not initialized" {
+		tErrorStr(t, "ExpireDuration", "database not initialized", err)
+	}
+
+	_, err = uninit.TTLDuration("test", "obj1")
+	if err == nil || err.Error() != "database not initialized" {
+		tErrorStr(t, "TTLDuration", "database not initialized", err)
+	}
+}
@@ -0,0 +1,502 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package t38c
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// maxPooledBuffer is the largest buffer returned to the pool, so a
+// single huge object does not stay in memory after it is decoded.
+const maxPooledBuffer = 1 << 20
+
+// Decoder pools.
+var (
+	readerPool = sync.Pool{ //nolint:gochecknoglobals // shared pool
+		New: func() any { return bufio.NewReaderSize(nil, 64<<10) }, //nolint:mnd // 64 KiB reads
+	}
+	bufferPool = sync.Pool{ //nolint:gochecknoglobals // shared pool
+		New: func() any { return new(bytes.Buffer) },
+	}
+)
+
+// decoderState is the position of a Decoder within the response.
+type decoderState int
+
+// Decoder states.
+const (
+	decodeStart   decoderState = iota // before the response object
+	decodeMembers                     // reading members of the response
+	decodeObjects                     // reading elements of the objects array
+	decodeDone                        // finished or failed
+)
+
+// Decoder reads a database response from a stream, yielding the
+// elements of the objects array one at a time rather than holding all
+// of them in memory as UnmarshalText does. Its buffers are pooled and
+// reused, so its memory use depends on the size of the largest object
+// rather than the number of objects.
+//
+// Call Next until it returns false, then check Err. Members of the
+// response other than objects are decoded into Response as they are
+// read, so members preceding the objects array, such as fields, are
+// available while iterating.
+type Decoder struct {
+	br    *bufio.Reader
+	buf   *bytes.Buffer
+	r     *Response
+	state decoderState
+	more  bool // a member or element has been read
+	err   error
+}
+
+// NewDecoder returns a Decoder reading a response from rd. Call Close
+// when finished to return its buffers to the pool.
+func NewDecoder(rd io.Reader) *Decoder {
+	return newDecoder(rd, new(Response))
+}
+
+// newDecoder returns a Decoder reading a response from rd into r.
+func newDecoder(rd io.Reader, r *Response) *Decoder {
+	br := readerPool.Get().(*bufio.Reader) //nolint:forcetypeassert // pool only holds readers
+	br.Reset(rd)
+
+	buf := bufferPool.Get().(*bytes.Buffer) //nolint:forcetypeassert // pool only holds buffers
+	buf.Reset()
+
+	return &Decoder{br: br, buf: buf, r: r}
+}
+
+// Next advances to the next element of the objects array, returning
+// false at the end of the response or on error.
+func (d *Decoder) Next() bool {
+	if d.state == decodeDone {
+		return false
+	}
+
+	err := d.next()
+	if err != nil {
+		d.err = err
+		d.state = decodeDone
+
+		return false
+	}
+
+	return d.state == decodeObjects
+}
+
+// Object returns the raw JSON of the current element of the objects
+// array. It is only valid until the next call to Next.
+func (d *Decoder) Object() []byte {
+	if d.state != decodeObjects {
+		return nil
+	}
+
+	return d.buf.Bytes()
+}
+
+// Response returns the members of the response decoded so far, which
+// is all of them once Next has returned false without error. Its
+// Objects field is not populated. Response returns nil if decoding
+// failed.
+func (d *Decoder) Response() *Response {
+	if d.err != nil {
+		return nil
+	}
+
+	return d.r
+}
+
+// Err returns the first error encountered while decoding.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Close returns the Decoder's buffers to the pool. The Decoder must not
+// be used afterwards.
+func (d *Decoder) Close() {
+	if d.br == nil {
+		return
+	}
+
+	d.br.Reset(nil)
+	readerPool.Put(d.br)
+
+	if d.buf.Cap() <= maxPooledBuffer {
+		bufferPool.Put(d.buf)
+	}
+
+	d.br, d.buf = nil, nil
+	d.state = decodeDone
+}
+
+// next reads members until an element of the objects array is read or
+// the response ends.
+func (d *Decoder) next() error {
+	if d.state == decodeObjects {
+		ok, err := d.element()
+		if err != nil || ok {
+			return err
+		}
+	}
+
+	if d.state == decodeStart {
+		c, err := d.skip()
+		if err != nil {
+			return err
+		}
+
+		if c != '{' {
+			err = d.value(c)
+			if err != nil {
+				return err
+			}
+
+			return errNotObject
+		}
+
+		d.state = decodeMembers
+	}
+
+	for d.state == decodeMembers {
+		err := d.member()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// member reads a member of the response object. At the start of the
+// objects array it reads the first element instead.
+func (d *Decoder) member() error {
+	c, err := d.skip()
+	if err != nil {
+		return err
+	}
+
+	if d.more {
+		if c == '}' {
+			return d.end()
+		}
+
+		if c != ',' {
+			return errInvalidJSON
+		}
+
+		c, err = d.skip()
+		if err != nil {
+			return err
+		}
+	} else if c == '}' {
+		return d.end()
+	}
+
+	d.more = true
+
+	if c != '"' {
+		return errInvalidJSON
+	}
+
+	err = d.value(c)
+	if err != nil {
+		return err
+	}
+
+	k := gjson.ParseBytes(d.buf.Bytes())
+
+	c, err = d.skip()
+	if err != nil {
+		return err
+	}
+
+	if c != ':' {
+		return errInvalidJSON
+	}
+
+	c, err = d.skip()
+	if err != nil {
+		return err
+	}
+
+	if k.Str == "objects" && c == '[' {
+		d.state = decodeObjects
+		d.more = false
+
+		_, err = d.element()
+
+		return err
+	}
+
+	err = d.value(c)
+	if err != nil {
+		return err
+	}
+
+	return d.r.parse(k.Str, gjson.ParseBytes(d.buf.Bytes()))
+}
+
+// element reads an element of the objects array, returning false at
+// the end of the array.
+func (d *Decoder) element() (bool, error) {
+	c, err := d.skip()
+	if err != nil {
+		return false, err
+	}
+
+	if c == ']' {
+		d.state = decodeMembers
+		d.more = true
+
+		return false, nil
+	}
+
+	if d.more {
+		if c != ',' {
+			return false, errInvalidJSON
+		}
+
+		c, err = d.skip()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	d.more = true
+
+	return true, d.value(c)
+}
+
+// end checks that only whitespace follows the response object.
+func (d *Decoder) end() error {
+	d.state = decodeDone
+
+	for {
+		c, err := d.br.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return d.readErr(err)
+		}
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return errInvalidJSON
+		}
+	}
+}
+
+// skip skips whitespace and returns the next byte.
+func (d *Decoder) skip() (byte, error) {
+	for {
+		c, err := d.br.ReadByte()
+		if err != nil {
+			return 0, d.readErr(err)
+		}
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return c, nil
+		}
+	}
+}
+
+// value reads a JSON value starting with c into the buffer and checks
+// that it is valid.
+func (d *Decoder) value(c byte) error {
+	d.buf.Reset()
+	d.buf.WriteByte(c)
+
+	var err error
+
+	switch c {
+	case '"':
+		err = d.scan(0, true)
+	case '{', '[':
+		err = d.scan(1, false)
+	default:
+		err = d.literal()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !gjson.ValidBytes(d.buf.Bytes()) {
+		return errInvalidJSON
+	}
+
+	return nil
+}
+
+// scan copies buffered input into the buffer until the string or
+// nesting level being read is closed.
+func (d *Decoder) scan(depth int, str bool) error {
+	esc := false
+
+	for {
+		b, err := d.buffered()
+		if err != nil {
+			return err
+		}
+
+		i := 0
+		done := false
+
+		for ; i < len(b) && !done; i++ {
+			switch c := b[i]; {
+			case esc:
+				esc = false
+			case str:
+				esc = c == '\\'
+				str = c != '"'
+			case c == '"':
+				str = true
+			case c == '{' || c == '[':
+				depth++
+			case c == '}' || c == ']':
+				depth--
+			}
+
+			done = depth == 0 && !str
+		}
+
+		d.buf.Write(b[:i])
+		d.br.Discard(i) //nolint:errcheck // discarding buffered bytes cannot fail
+
+		if done {
+			return nil
+		}
+	}
+}
+
+// literal copies a number, true, false or null into the buffer.
+func (d *Decoder) literal() error {
+	for {
+		b, err := d.buffered()
+		if err != nil {
+			return err
+		}
+
+		i := bytes.IndexAny(b, ",}] \t\r\n")
+		if i < 0 {
+			i = len(b)
+		}
+
+		d.buf.Write(b[:i])
+		d.br.Discard(i) //nolint:errcheck // discarding buffered bytes cannot fail
+
+		if i < len(b) {
+			return nil
+		}
+	}
+}
+
+// buffered returns the buffered input, reading more if none remains.
+func (d *Decoder) buffered() ([]byte, error) {
+	if d.br.Buffered() == 0 {
+		_, err := d.br.Peek(1)
+		if err != nil {
+			return nil, d.readErr(err)
+		}
+	}
+
+	b, _ := d.br.Peek(d.br.Buffered())
+
+	return b, nil
+}
+
+// readErr converts an error from the underlying reader. The end of
+// input is only expected after the response, so it is reported as
+// invalid JSON.
+func (d *Decoder) readErr(err error) error {
+	if errors.Is(err, io.EOF) {
+		return errInvalidJSON
+	}
+
+	return newError(err, "error reading response")
+}
+
+// ObjectFunc is called with each object of a streamed response. r holds
+// the members of the response decoded so far and obj is the raw JSON of
+// an element of the objects array, which is only valid until ObjectFunc
+// returns.
+type ObjectFunc func(r *Response, obj []byte) error
+
+// objectFuncError wraps an error returned by an ObjectFunc so that it
+// can be returned to the caller unchanged.
+type objectFuncError struct {
+	err error
+}
+
+// Error returns the string value of the wrapped error.
+func (e *objectFuncError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *objectFuncError) Unwrap() error {
+	return e.err
+}
+
+// decodeStream decodes a response from rd into r, calling fn with each
+// object.
+func decodeStream(rd io.Reader, r *Response, fn ObjectFunc) error {
+	d := newDecoder(rd, r)
+	defer d.Close()
+
+	for d.Next() {
+		err := fn(r, d.Object())
+		if err != nil {
+			return &objectFuncError{err: err}
+		}
+	}
+
+	return d.Err()
+}
+
+// streamObjects calls fn with each object of a response which has
+// already been decoded, then clears r.Objects.
+func streamObjects(r *Response, fn ObjectFunc) error {
+	objs := r.Objects
+	r.Objects = nil
+
+	for _, o := range objs {
+		err := fn(r, []byte(o))
+		if err != nil {
+			return &objectFuncError{err: err}
+		}
+	}
+
+	return nil
+}
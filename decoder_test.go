@@ -0,0 +1,394 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package t38c_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// TestDecoder tests decoding responses from a stream.
+func TestDecoder(t *testing.T) {
+	t.Run("Objects", testDecoderObjects)
+	t.Run("Errors", testDecoderErrors)
+}
+
+// Test objects and members decoded from a stream.
+func testDecoderObjects(t *testing.T) {
+	tests := map[string]struct {
+		json    string
+		objects []string
+		count   int64
+	}{
+		"Empty":   {`{}`, nil, 0},
+		"None":    {`{"ok":true,"objects":[],"count":0}`, nil, 0},
+		"Null":    {`{"ok":true,"objects":null}`, nil, 0},
+		"Strings": {`{"ok":true,"objects":["a\"]","b\\"],"count":2}`, []string{`"a\"]"`, `"b\\"`}, 2},
+		"Objects": {
+			" {\"ok\":true,\"fields\":[\"speed\"],\"objects\":[\n" +
+				`{"id":"a","object":{"type":"Point","coordinates":[1,2]},"fields":[10]},` +
+				`{"id":"b}","object":"x","fields":[-1.5e3]}` +
+				"\n],\"count\":2,\"cursor\":5,\"elapsed\":\"1µs\"}\r\n",
+			[]string{
+				`{"id":"a","object":{"type":"Point","coordinates":[1,2]},"fields":[10]}`,
+				`{"id":"b}","object":"x","fields":[-1.5e3]}`,
+			},
+			2,
+		},
+	}
+
+	for name, tc := range tests {
+		d := t38c.NewDecoder(iotest.OneByteReader(strings.NewReader(tc.json)))
+
+		var objs []string
+
+		for d.Next() {
+			objs = append(objs, string(d.Object()))
+		}
+
+		if d.Err() != nil {
+			tErrorStr(t, name, "nil", d.Err())
+		}
+
+		if !reflect.DeepEqual(objs, tc.objects) {
+			tErrorStr(t, name, fmt.Sprint(tc.objects), fmt.Sprint(objs))
+		}
+
+		if d.Response().Count != tc.count {
+			tErrorVal(t, name, tc.count, d.Response().Count)
+		}
+
+		d.Close()
+	}
+}
+
+// Test malformed streams.
+func testDecoderErrors(t *testing.T) {
+	tests := map[string]string{
+		``:                               "not valid JSON",
+		`[]`:                             "not a JSON object",
+		`{"ok":true`:                     "not valid JSON",
+		`{"ok":true}x`:                   "not valid JSON",
+		`{"ok":true,}`:                   "not valid JSON",
+		`{"ok" true}`:                    "not valid JSON",
+		`{"ok":tru}`:                     "not valid JSON",
+		`{"objects":[{"id":"a"}`:         "not valid JSON",
+		`{"objects":[{"id":"a"} {}]}`:    "not valid JSON",
+		`{"objects":[{"id":"a"},]}`:      "not valid JSON",
+		`{"objects":[{"id":"a"]}`:        "not valid JSON",
+		`{"objects":["a]}`:               "not valid JSON",
+		`{"zzz":true}`:                   "unknown response value",
+		`{"fields":true,"objects":[{}]}`: "unknown field type",
+	}
+
+	for json, exp := range tests {
+		d := t38c.NewDecoder(strings.NewReader(json))
+
+		for d.Next() {
+		}
+
+		if d.Err() == nil || !strings.Contains(d.Err().Error(), exp) {
+			tErrorStr(t, json, exp, d.Err())
+		}
+
+		if d.Next() {
+			tErrorVal(t, json, false, true)
+		}
+
+		if d.Response() != nil {
+			tErrorStr(t, json, "nil", fmt.Sprint(d.Response()))
+		}
+
+		d.Close()
+	}
+
+	d := t38c.NewDecoder(iotest.ErrReader(errors.New("test failure")))
+	defer d.Close()
+
+	if d.Next() || d.Err() == nil || d.Err().Error() != "error reading response: test failure" {
+		tErrorStr(t, "ErrReader", "error reading response: test failure", d.Err())
+	}
+}
+
+// TestScanStream tests streaming the objects of a SCAN.
+func TestScanStream(t *testing.T) {
+	t.Run("RESP", func(t *testing.T) { testScanStream(t) })
+	t.Run("HTTP", func(t *testing.T) { testScanStream(t, t38c.WithHTTP(nil)) })
+	t.Run("Transport", testScanStreamTransport)
+	t.Run("Errors", testScanStreamErrors)
+}
+
+// streamServer returns a new emulating server storing n objects, which
+// is closed when the test completes.
+func streamServer(t *testing.T, n int) *mock.Server {
+	t.Helper()
+
+	ssrv := mock.NewServer()
+	if ssrv.Err != nil {
+		tFatalErr(t, "NewServer", ssrv.Err)
+	}
+
+	t.Cleanup(func() { ssrv.Close() })
+
+	e := ssrv.Emulate()
+
+	for i := range n {
+		e.Do("SET", "fleet", fmt.Sprintf("truck%03d", i), "FIELD", "speed", fmt.Sprint(i+1), "POINT", "33", "-112")
+	}
+
+	return ssrv
+}
+
+// Test streaming objects over a transport selected by opts.
+func testScanStream(t *testing.T, opts ...t38c.Option) {
+	ssrv := streamServer(t, 150)
+
+	db, err := t38c.Connect(ssrv.Addr, ssrv.Port, 1, opts...)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	var ids []string
+
+	for cursor := 0; ; {
+		r, err := db.ScanStream("fleet", t38c.NewQuery().Cursor(cursor).Limit(100), func(r *t38c.Response, obj []byte) error {
+			if _, ok := r.FieldNames["speed"]; !ok {
+				tErrorStr(t, "FieldNames", "speed", fmt.Sprint(r.FieldNames))
+			}
+
+			ids = append(ids, string(bytes.SplitN(obj, []byte(`"`), 5)[3]))
+
+			return nil
+		})
+		if err != nil {
+			tFatalErr(t, "ScanStream", err)
+		}
+
+		if r.Objects != nil {
+			tErrorVal(t, "Objects", 0, len(r.Objects))
+		}
+
+		if r.Cursor == 0 {
+			break
+		}
+
+		cursor = int(r.Cursor)
+	}
+
+	if len(ids) != 150 || ids[0] != "truck000" || ids[149] != "truck149" {
+		tErrorStr(t, "ScanStream", "truck000 to truck149", fmt.Sprint(ids))
+	}
+}
+
+// Test streaming objects from a Transport which does not implement
+// Streamer.
+func testScanStreamTransport(t *testing.T) {
+	e := mock.NewEmulator()
+	e.Do("SET", "fleet", "a", "STRING", "x")
+	e.Do("SET", "fleet", "b", "STRING", "y")
+
+	db, err := t38c.NewDatabase(t38c.TransportFunc(func(r *t38c.Response, cmd string, args ...string) error {
+		return r.UnmarshalText([]byte(e.Do(append([]string{cmd}, args...)...)))
+	}))
+	if err != nil {
+		tFatalErr(t, "NewDatabase", err)
+	}
+
+	var objs []string
+
+	r, err := db.ScanStream("fleet", nil, func(_ *t38c.Response, obj []byte) error {
+		objs = append(objs, string(obj))
+
+		return nil
+	})
+	if err != nil {
+		tFatalErr(t, "ScanStream", err)
+	}
+
+	exp := []string{`{"id":"a","object":"x"}`, `{"id":"b","object":"y"}`}
+	if !reflect.DeepEqual(objs, exp) {
+		tErrorStr(t, "ScanStream", fmt.Sprint(exp), fmt.Sprint(objs))
+	}
+
+	if r.Objects != nil || r.Count != 2 {
+		tErrorStr(t, "ScanStream", "no objects and count 2", fmt.Sprint(r.Objects, r.Count))
+	}
+}
+
+// Test errors while streaming, after which the connection must remain
+// usable.
+func testScanStreamErrors(t *testing.T) {
+	ssrv := streamServer(t, 3)
+
+	db, err := t38c.Connect(ssrv.Addr, ssrv.Port, 1, t38c.WithRetry(retryPolicy()))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	_, err = db.ScanStream("fleet", nil, nil)
+	if err == nil || err.Error() != "invalid arguments" {
+		tErrorStr(t, "ScanStream", "invalid arguments", err)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+
+	_, err = db.ScanStream("fleet", nil, func(*t38c.Response, []byte) error {
+		calls++
+
+		return stop
+	})
+	if !errors.Is(err, stop) || err != stop { //nolint:errorlint // error must be returned unwrapped
+		tErrorStr(t, "ScanStream", "stop", err)
+	}
+
+	if calls != 1 {
+		tErrorVal(t, "calls", 1, calls)
+	}
+
+	ssrv.Inject("SCAN", mock.FaultMalformed)
+
+	_, err = db.ScanStream("fleet", nil, func(*t38c.Response, []byte) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+		tErrorStr(t, "ScanStream", "not valid JSON", err)
+	}
+
+	r, err := db.Get("fleet", "truck001")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	if !strings.Contains(r.Object, "Point") {
+		tErrorStr(t, "Get", "Point", r.Object)
+	}
+}
+
+// FuzzDecoder checks that streaming arbitrary input agrees with
+// UnmarshalText.
+func FuzzDecoder(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add([]byte(s))
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		exp := new(t38c.Response)
+		experr := exp.UnmarshalText(b)
+
+		d := t38c.NewDecoder(iotest.HalfReader(bytes.NewReader(b)))
+		defer d.Close()
+
+		var objs []string
+
+		for d.Next() {
+			objs = append(objs, string(d.Object()))
+		}
+
+		if (experr == nil) != (d.Err() == nil) {
+			t.Fatalf("%q: UnmarshalText error %v, Decoder error %v", b, experr, d.Err())
+		}
+
+		if experr != nil {
+			return
+		}
+
+		if !reflect.DeepEqual(objs, exp.Objects) {
+			t.Errorf("%q: objects %q, expected %q", b, objs, exp.Objects)
+		}
+
+		exp.Objects = nil
+		if !reflect.DeepEqual(d.Response(), exp) {
+			t.Errorf("%q: Response %+v, expected %+v", b, *d.Response(), *exp)
+		}
+	})
+}
+
+// benchPayload returns a SCAN response holding n objects.
+func benchPayload(n int) []byte {
+	var b bytes.Buffer
+
+	b.WriteString(`{"ok":true,"fields":["speed","heading"],"objects":[`)
+
+	for i := range n {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		fmt.Fprintf(&b, `{"id":"truck%d","object":{"type":"Point","coordinates":[-112.2693,33.5123]},"fields":[%d,90]}`, i, i)
+	}
+
+	b.WriteString(`],"count":10000,"cursor":0,"elapsed":"1ms"}`)
+
+	return b.Bytes()
+}
+
+// BenchmarkUnmarshalText decodes a large SCAN response in full.
+func BenchmarkUnmarshalText(b *testing.B) {
+	payload := benchPayload(10000)
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+
+	for range b.N {
+		r := new(t38c.Response)
+
+		err := r.UnmarshalText(payload)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecoder streams the objects of a large SCAN response.
+func BenchmarkDecoder(b *testing.B) {
+	payload := benchPayload(10000)
+	rd := bytes.NewReader(payload)
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+
+	for range b.N {
+		rd.Reset(payload)
+
+		d := t38c.NewDecoder(rd)
+
+		for d.Next() {
+		}
+
+		if d.Err() != nil {
+			b.Fatal(d.Err())
+		}
+
+		d.Close()
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// TestFaults tests error handling with faults injected by the server.
+func TestFaults(t *testing.T) {
+	t.Run("Handshake", testFaultHandshake)
+	t.Run("Truncate", testFaultTruncate)
+	t.Run("Malformed", testFaultMalformed)
+	t.Run("Fragment", testFaultFragment)
+	t.Run("Latency", testFaultLatency)
+	t.Run("Breaker", testFaultBreaker)
+}
+
+// faultServer returns a new emulating server with an object stored,
+// which is closed when the test completes.
+func faultServer(t *testing.T) *mock.Server {
+	t.Helper()
+
+	fsrv := mock.NewServer()
+	if fsrv.Err != nil {
+		tFatalErr(t, "NewServer", fsrv.Err)
+	}
+
+	t.Cleanup(func() { fsrv.Close() })
+
+	fsrv.Emulate().Do("SET", "test", "obj1", "STRING", "x")
+
+	return fsrv
+}
+
+// Test failing the OUTPUT handshake on a specific dial.
+func testFaultHandshake(t *testing.T) {
+	fsrv := faultServer(t)
+	fsrv.FailDial(1)
+
+	_, err := t38c.Connect(fsrv.Addr, fsrv.Port, 1)
+	if err == nil {
+		tFatalNoErr(t, "Connect")
+	}
+
+	expErr := "error connecting to server: received error: " + mock.TestHandshakeFailure
+	if err.Error() != expErr {
+		tErrorStr(t, "Connect", expErr, err)
+	}
+
+	db, err := t38c.Connect(fsrv.Addr, fsrv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	db.Close()
+}
+
+// Test retrying after a reply is cut off mid-response.
+func testFaultTruncate(t *testing.T) {
+	fsrv := faultServer(t)
+
+	db, err := t38c.Connect(fsrv.Addr, fsrv.Port, 1, t38c.WithRetry(retryPolicy()))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	fsrv.Inject("GET", mock.FaultTruncate)
+
+	r, err := db.Get("test", "obj1")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	if r.Object != "x" {
+		tErrorStr(t, "Get", "x", r.Object)
+	}
+}
+
+// Test a reply that is not valid JSON.
+func testFaultMalformed(t *testing.T) {
+	fsrv := faultServer(t)
+
+	db, err := t38c.Connect(fsrv.Addr, fsrv.Port, 1, t38c.WithRetry(retryPolicy()))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	fsrv.Inject("GET", mock.FaultMalformed)
+
+	_, err = db.Get("test", "obj1")
+	if err == nil {
+		tFatalNoErr(t, "Get")
+	}
+
+	if !strings.Contains(err.Error(), "not valid JSON") {
+		tErrorStr(t, "Get", "not valid JSON", err)
+	}
+
+	if t38c.IsRetryable(err) {
+		tErrorVal(t, "IsRetryable", false, true)
+	}
+}
+
+// Test a reply delivered in partial frames.
+func testFaultFragment(t *testing.T) {
+	fsrv := faultServer(t)
+
+	db, err := t38c.Connect(fsrv.Addr, fsrv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	fsrv.Inject("GET", mock.FaultFragment)
+
+	r, err := db.Get("test", "obj1")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	if r.Object != "x" {
+		tErrorStr(t, "Get", "x", r.Object)
+	}
+}
+
+// Test latency added to replies.
+func testFaultLatency(t *testing.T) {
+	fsrv := faultServer(t)
+
+	db, err := t38c.Connect(fsrv.Addr, fsrv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	fsrv.SetLatency(20 * time.Millisecond)
+
+	start := time.Now()
+
+	_, err = db.Get("test", "obj1")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	if d := time.Since(start); d < 20*time.Millisecond {
+		tErrorVal(t, "Latency", 20*time.Millisecond, d)
+	}
+
+	fsrv.ResetFaults()
+}
+
+// Test the circuit breaker opening after dropped connections.
+func testFaultBreaker(t *testing.T) {
+	fsrv := faultServer(t)
+
+	db, err := t38c.Connect(fsrv.Addr, fsrv.Port, 1,
+		t38c.WithCircuitBreaker(2, time.Minute))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	fsrv.Inject("GET", mock.FaultDrop, mock.FaultTruncate)
+
+	for range 2 {
+		_, err = db.Get("test", "obj1")
+		if !t38c.IsRetryable(err) {
+			tErrorStr(t, "Get", "network error", err)
+		}
+	}
+
+	_, err = db.Get("test", "obj1")
+	if !errors.Is(err, t38c.ErrCircuitOpen) {
+		tErrorStr(t, "Open", t38c.ErrCircuitOpen, err)
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by the WebSocket handshake
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WebSocket protocol values from RFC 6455.
+const (
+	wsGUID       = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsMaxMessage = 64 << 20
+
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// Fence errors.
+var (
+	errHandshake = newError(nil, "websocket handshake failed")
+	errFrame     = newError(nil, "invalid websocket frame")
+	errNotLive   = newError(nil, "not a live query")
+)
+
+// Fence is a live geofence query streaming notifications from Tile38
+// over a WebSocket connection.
+//
+// Next must not be called concurrently, but Close may be called from
+// another goroutine to interrupt a blocked Next.
+type Fence struct {
+	conn net.Conn
+	br   *bufio.Reader
+	wmu  sync.Mutex
+}
+
+// DialFence connects to Tile38 at server:port over a WebSocket and
+// starts a live geofence query. The command and arguments are given in
+// the same form as the Tile38 CLI and must include FENCE, for example
+// DialFence("localhost", "9851", "NEARBY", "fleet", "FENCE", "POINT",
+// "33.5", "-112.2", "5000").
+func DialFence(server string, port string, cmd string, args ...string) (*Fence, error) {
+	return dialFence(server, port, nil, cmd, args)
+}
+
+// DialFenceTLS is like DialFence but connects using TLS.
+func DialFenceTLS(server string, port string, config *tls.Config, cmd string, args ...string) (*Fence, error) {
+	return dialFence(server, port, config, cmd, args)
+}
+
+// dialFence connects, performs the WebSocket handshake and checks that
+// the server confirms the live query.
+func dialFence(server string, port string, config *tls.Config, cmd string, args []string) (*Fence, error) {
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	addr := net.JoinHostPort(server, port)
+
+	if config == nil {
+		conn, err = net.Dial("tcp", addr)
+	} else {
+		conn, err = tls.Dial("tcp", addr, config)
+	}
+
+	if err != nil {
+		return nil, newError(err, "error connecting to server")
+	}
+
+	f := &Fence{
+		conn: conn,
+		br:   bufio.NewReader(conn),
+	}
+
+	err = f.handshake(addr, "/"+httpArgs(cmd, args))
+	if err != nil {
+		conn.Close() //nolint:errcheck // Close() in error path
+
+		return nil, newError(err, "error connecting to server")
+	}
+
+	r, err := f.Next()
+
+	switch {
+	case err != nil:
+	case !r.Ok:
+		err = fmt.Errorf("%w: %s", errResponse, r.Err)
+	case !r.Live:
+		err = errNotLive
+	}
+
+	if err != nil {
+		f.Close() //nolint:errcheck // Close() in error path
+
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Next blocks until the next notification is received and returns it
+// as a Response with the Command, Group, Detect, Key, Time, ID and
+// Object fields set, and Nearby or Faraway for ROAM notifications.
+// Next returns io.EOF once the server closes the connection.
+func (f *Fence) Next() (*Response, error) {
+	msg, err := f.readMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Response)
+
+	err = r.UnmarshalText(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Close ends the live query and closes the connection.
+func (f *Fence) Close() error {
+	_ = f.writeFrame(wsClose, []byte{0x03, 0xe8}) //nolint:mnd // status 1000, normal closure
+
+	err := f.conn.Close()
+	if err != nil {
+		return newError(err, "error closing fence connection")
+	}
+
+	return nil
+}
+
+// handshake sends the WebSocket opening handshake and validates the
+// server's reply.
+func (f *Fence) handshake(host string, path string) error {
+	b := make([]byte, 16) //nolint:mnd // key length from RFC 6455
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return err
+	}
+
+	key := base64.StdEncoding.EncodeToString(b)
+
+	_, err = io.WriteString(f.conn, "GET "+path+" HTTP/1.1\r\n"+
+		"Host: "+host+"\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+key+"\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+	if err != nil {
+		return err
+	}
+
+	res, err := http.ReadResponse(f.br, nil)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusSwitchingProtocols ||
+		!strings.EqualFold(res.Header.Get("Upgrade"), "websocket") ||
+		res.Header.Get("Sec-WebSocket-Accept") != wsAccept(key) {
+		res.Body.Close()
+
+		return fmt.Errorf("%w: %s", errHandshake, res.Status)
+	}
+
+	return nil
+}
+
+// readMessage returns the payload of the next text or binary message,
+// reassembling fragmented messages and answering control frames.
+func (f *Fence) readMessage() ([]byte, error) {
+	var (
+		msg     []byte
+		started bool
+	)
+
+	for {
+		fin, op, payload, err := f.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case wsPing:
+			err = f.writeFrame(wsPong, payload)
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			_ = f.writeFrame(wsClose, payload)
+
+			return nil, io.EOF
+		case wsText, wsBinary:
+			if started {
+				return nil, errFrame
+			}
+
+			started = true
+			msg = payload
+		case wsContinuation:
+			if !started {
+				return nil, errFrame
+			}
+
+			msg = append(msg, payload...)
+		default:
+			return nil, errFrame
+		}
+
+		if len(msg) > wsMaxMessage {
+			return nil, errFrame
+		}
+
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+// readFrame reads a single frame, unmasking the payload if required.
+func (f *Fence) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var h [8]byte
+
+	_, err = io.ReadFull(f.br, h[:2])
+	if err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = h[0]&0x80 != 0
+	op = h[0] & 0x0f
+	masked := h[1]&0x80 != 0
+	n := uint64(h[1] & 0x7f)
+
+	switch n {
+	case 126: //nolint:mnd // 16-bit length
+		_, err = io.ReadFull(f.br, h[:2])
+		n = uint64(binary.BigEndian.Uint16(h[:2]))
+	case 127: //nolint:mnd // 64-bit length
+		_, err = io.ReadFull(f.br, h[:8])
+		n = binary.BigEndian.Uint64(h[:8])
+	}
+
+	if err != nil {
+		return false, 0, nil, err
+	}
+
+	if n > wsMaxMessage {
+		return false, 0, nil, errFrame
+	}
+
+	var mask [4]byte
+
+	if masked {
+		_, err = io.ReadFull(f.br, mask[:])
+		if err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, n)
+
+	_, err = io.ReadFull(f.br, payload)
+	if err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return fin, op, payload, nil
+}
+
+// writeFrame writes a single masked frame, as required for frames sent
+// by a client.
+func (f *Fence) writeFrame(op byte, payload []byte) error {
+	frame := make([]byte, 0, len(payload)+14) //nolint:mnd // maximum header length
+	frame = append(frame, 0x80|op)
+
+	switch n := len(payload); {
+	case n < 126: //nolint:mnd // 7-bit length
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	var mask [4]byte
+
+	_, err := rand.Read(mask[:])
+	if err != nil {
+		return err
+	}
+
+	frame = append(frame, mask[:]...)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	f.wmu.Lock()
+	defer f.wmu.Unlock()
+
+	_, err = f.conn.Write(frame)
+
+	return err
+}
+
+// wsAccept returns the Sec-WebSocket-Accept value expected for key.
+func wsAccept(key string) string {
+	h := sha1.Sum([]byte(key + wsGUID)) //nolint:gosec // required by the WebSocket handshake
+
+	return base64.StdEncoding.EncodeToString(h[:])
+}
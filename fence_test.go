@@ -0,0 +1,225 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// fenceArgs is the live query used by the fence tests.
+var fenceArgs = []string{"fleet", "FENCE", "DETECT", "enter,exit", "POINT", "33.5", "-112.2", "5000"} //nolint:gochecknoglobals // shared test data
+
+// TestFence tests receiving live geofence notifications.
+func TestFence(t *testing.T) {
+	t.Run("Events", testFenceEvents)
+	t.Run("Roam", testFenceRoam)
+	t.Run("Errors", testFenceErrors)
+	t.Run("Handshake", testFenceHandshake)
+	t.Run("Close", testFenceClose)
+}
+
+// Test receiving events, including fragmented, large and control
+// frames.
+func testFenceEvents(t *testing.T) {
+	srv.HandleFunc("NEARBY", srv.ReturnLive)
+	srv.ResetCommands()
+
+	f, err := t38c.DialFence(srv.Addr, srv.Port, "NEARBY", fenceArgs...)
+	if err != nil {
+		tFatalErr(t, "DialFence", err)
+	}
+
+	defer f.Close()
+
+	tCommands(t, "DialFence", "NEARBY "+strings.Join(fenceArgs, " "))
+
+	srv.PingLive("hello")
+	srv.Publish(mock.TestEvent, 1)
+	srv.Publish(mock.TestEvent, 3)
+
+	for range 2 {
+		r, err := f.Next()
+		if err != nil {
+			tFatalErr(t, "Next", err)
+		}
+
+		exp := time.Date(2026, 1, 2, 3, 4, 5, 5e8, time.UTC)
+
+		if r.Command != "set" || r.Group != "g1" || r.Detect != "enter" ||
+			r.Key != "fleet" || r.ID != "truck1" || !r.Time.Equal(exp) {
+			tErrorVal(t, "Next", mock.TestEvent, r)
+		}
+
+		if r.Object != `{"type":"Point","coordinates":[-112.2,33.5]}` {
+			tErrorStr(t, "Object", "point", r.Object)
+		}
+	}
+
+	for _, n := range []int{200, 70000} {
+		obj := strings.Repeat("x", n)
+
+		srv.Publish(`{"id":"big","object":"`+obj+`"}`, 1)
+
+		r, err := f.Next()
+		if err != nil {
+			tFatalErr(t, "Next", err)
+		}
+
+		if r.Object != obj {
+			tErrorVal(t, "Object", n, len(r.Object))
+		}
+	}
+
+	srv.CloseLive()
+
+	_, err = f.Next()
+	if !errors.Is(err, io.EOF) {
+		tErrorStr(t, "Next", io.EOF, err)
+	}
+}
+
+// Test receiving ROAM notifications, including one sent to a hook.
+func testFenceRoam(t *testing.T) {
+	srv.HandleFunc("NEARBY", srv.ReturnLive)
+
+	f, err := t38c.DialFence(srv.Addr, srv.Port, "NEARBY", "fleet", "FENCE", "ROAM", "fleet", "*", "5000")
+	if err != nil {
+		tFatalErr(t, "DialFence", err)
+	}
+
+	defer f.Close()
+
+	other := `{"key":"fleet","id":"truck2","object":{"type":"Point","coordinates":[-112.21,33.51]},"meters":1401.5}`
+
+	srv.Publish(`{"command":"set","detect":"roam","key":"fleet","time":"2026-01-02T03:04:05Z",`+
+		`"id":"truck1","object":{"type":"Point","coordinates":[-112.2,33.5]},"nearby":`+other+`}`, 1)
+	srv.Publish(`{"command":"set","detect":"roam","hook":"warehouse","key":"fleet","time":"2026-01-02T03:04:05Z",`+
+		`"id":"truck1","object":{"type":"Point","coordinates":[-112.2,33.5]},"faraway":`+other+`,"meta":{"site":"1"}}`, 1)
+
+	r, err := f.Next()
+	if err != nil {
+		tFatalErr(t, "Next", err)
+	}
+
+	if r.Detect != "roam" || r.ID != "truck1" || r.Nearby != other || r.Faraway != "" {
+		tErrorStr(t, "Nearby", other, r.Nearby)
+	}
+
+	r, err = f.Next()
+	if err != nil {
+		tFatalErr(t, "Next", err)
+	}
+
+	if r.Hook != "warehouse" || r.Faraway != other || r.Nearby != "" || r.Meta != `{"site":"1"}` {
+		tErrorStr(t, "Faraway", other, r.Faraway)
+	}
+}
+
+// Test errors starting a live query.
+func testFenceErrors(t *testing.T) {
+	tests := map[string]func(){
+		"received error: " + mock.TestOkFalse:     func() { srv.HandleFunc("NEARBY", srv.ReturnOkFalse) },
+		"received error: " + mock.TestServerError: func() { srv.HandleFunc("NEARBY", srv.ReturnErr) },
+		"not a live query":                        func() { srv.HandleFunc("NEARBY", srv.ReturnOkTrue) },
+	}
+
+	for exp, setup := range tests {
+		setup()
+
+		f, err := t38c.DialFence(srv.Addr, srv.Port, "NEARBY", fenceArgs...)
+		if err == nil || err.Error() != exp {
+			tErrorStr(t, "DialFence", exp, err)
+		}
+
+		if f != nil {
+			tErrorStr(t, "Fence", "nil", "not nil")
+		}
+	}
+}
+
+// Test servers which do not accept the WebSocket handshake.
+func testFenceHandshake(t *testing.T) {
+	hs := httptest.NewTLSServer(http.NotFoundHandler())
+	defer hs.Close()
+
+	u, _ := url.Parse(hs.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+
+	cfg := hs.Client().Transport.(*http.Transport).TLSClientConfig //nolint:forcetypeassert // httptest client
+
+	_, err := t38c.DialFenceTLS(host, port, cfg, "NEARBY", fenceArgs...)
+
+	exp := "error connecting to server: websocket handshake failed: 404 Not Found"
+	if err == nil || err.Error() != exp {
+		tErrorStr(t, "DialFenceTLS", exp, err)
+	}
+
+	hs.Close()
+
+	_, err = t38c.DialFence(host, port, "NEARBY", fenceArgs...)
+	if !t38c.IsRetryable(err) {
+		tErrorStr(t, "DialFence", "network error", err)
+	}
+}
+
+// Test interrupting Next with Close.
+func testFenceClose(t *testing.T) {
+	srv.HandleFunc("NEARBY", srv.ReturnLive)
+
+	f, err := t38c.DialFence(srv.Addr, srv.Port, "NEARBY", fenceArgs...)
+	if err != nil {
+		tFatalErr(t, "DialFence", err)
+	}
+
+	done := make(chan error)
+
+	go func() {
+		_, err := f.Next()
+		done <- err
+	}()
+
+	err = f.Close()
+	if err != nil {
+		tFatalErr(t, "Close", err)
+	}
+
+	select {
+	case err = <-done:
+		if err == nil {
+			tFatalNoErr(t, "Next")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Next: not interrupted by Close")
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"kreklow.us/go/t38c"
+)
+
+// EarthRadius is the mean radius of the Earth in meters, used by Tile38
+// and the spherical calculations in this package.
+const EarthRadius = 6371e3
+
+// WGS 84 ellipsoid parameters used by Vincenty.
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+	wgs84B = wgs84A * (1 - wgs84F)
+)
+
+// vincentyIterations is the maximum number of iterations of Vincenty's
+// formula before it is considered to have failed.
+const vincentyIterations = 200
+
+// errNoConvergence is returned by Vincenty for nearly antipodal points.
+var errNoConvergence = errors.New("vincenty: failed to converge")
+
+// rad converts degrees to radians.
+func rad(deg float64) float64 {
+	return deg * math.Pi / 180 //nolint:mnd // degrees to radians
+}
+
+// deg converts radians to degrees.
+func deg(rad float64) float64 {
+	return rad * 180 / math.Pi //nolint:mnd // radians to degrees
+}
+
+// Haversine returns the great-circle distance in meters between two
+// points on a sphere of EarthRadius, matching the distances reported by
+// Tile38.
+func Haversine(a t38c.Point, b t38c.Point) float64 { //nolint:mnd // haversine formula
+	dlat := rad(b.Lat - a.Lat)
+	dlon := rad(b.Lon - a.Lon)
+
+	h := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(rad(a.Lat))*math.Cos(rad(b.Lat))*math.Sin(dlon/2)*math.Sin(dlon/2)
+
+	return 2 * EarthRadius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// Vincenty returns the distance in meters between two points on the
+// WGS 84 ellipsoid using Vincenty's inverse formula, which is accurate
+// to within a millimeter. It returns an error for nearly antipodal
+// points, where the formula does not converge.
+func Vincenty(a t38c.Point, b t38c.Point) (float64, error) { //nolint:mnd // coefficients of the formula
+	l := rad(b.Lon - a.Lon)
+	u1 := math.Atan((1 - wgs84F) * math.Tan(rad(a.Lat)))
+	u2 := math.Atan((1 - wgs84F) * math.Tan(rad(b.Lat)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	lambda := l
+
+	for range vincentyIterations {
+		sinLambda, cosLambda := math.Sincos(lambda)
+
+		sinSigma := math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0, nil // coincident points
+		}
+
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha := 1 - sinAlpha*sinAlpha
+
+		cos2SigmaM := 0.0
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+
+		c := wgs84F / 16 * cos2Alpha * (4 + wgs84F*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = l + (1-c)*wgs84F*sinAlpha*
+			(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+
+		if math.Abs(lambda-prev) < 1e-12 {
+			uSq := cos2Alpha * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+			ka := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+			kb := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+			dSigma := kb * sinSigma * (cos2SigmaM + kb/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+				kb/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+
+			return wgs84B * ka * (sigma - dSigma), nil
+		}
+	}
+
+	return 0, errNoConvergence
+}
+
+// Bearing returns the initial bearing in degrees clockwise from north,
+// from 0 up to 360, of the great-circle path from a to b.
+func Bearing(a t38c.Point, b t38c.Point) float64 {
+	lat1, lat2 := rad(a.Lat), rad(b.Lat)
+	dlon := rad(b.Lon - a.Lon)
+
+	y := math.Sin(dlon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dlon)
+
+	return math.Mod(deg(math.Atan2(y, x))+360, 360) //nolint:mnd // full circle
+}
+
+// Destination returns the point reached by traveling a distance in
+// meters from p along a great circle with the initial bearing in
+// degrees clockwise from north.
+func Destination(p t38c.Point, bearing float64, meters float64) t38c.Point {
+	lat1, lon1 := rad(p.Lat), rad(p.Lon)
+	d := meters / EarthRadius
+	brng := rad(bearing)
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(brng))
+	lon2 := lon1 + math.Atan2(math.Sin(brng)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+
+	return t38c.Point{Lat: deg(lat2), Lon: wrapLon(deg(lon2))}
+}
+
+// BoundsAround returns the smallest bounding box containing the circle
+// of radius meters around p. Since Bounds cannot cross the
+// antimeridian, a circle crossing it or containing a pole is given the
+// full range of longitudes.
+func BoundsAround(p t38c.Point, meters float64) (t38c.Bounds, error) {
+	_, err := p.ObjectArgs()
+	if err != nil {
+		return t38c.Bounds{}, err
+	}
+
+	if math.IsNaN(meters) || meters < 0 {
+		return t38c.Bounds{}, fmt.Errorf("invalid area: radius %v must not be negative", meters)
+	}
+
+	dlat := deg(meters / EarthRadius)
+	b := t38c.Bounds{MinLat: p.Lat - dlat, MinLon: -180, MaxLat: p.Lat + dlat, MaxLon: 180}
+
+	if b.MinLat <= -90 || b.MaxLat >= 90 {
+		b.MinLat, b.MaxLat = math.Max(b.MinLat, -90), math.Min(b.MaxLat, 90)
+
+		return b, nil
+	}
+
+	// the widest point of the circle is north or south of p, where the
+	// meridians tangent to it touch
+	s := math.Sin(meters/EarthRadius) / math.Cos(rad(p.Lat))
+	if s >= 1 {
+		return b, nil
+	}
+
+	dlon := deg(math.Asin(s))
+	if p.Lon-dlon >= -180 && p.Lon+dlon <= 180 {
+		b.MinLon, b.MaxLon = p.Lon-dlon, p.Lon+dlon
+	}
+
+	return b, nil
+}
+
+// Area returns the area in square meters of a polygon on a sphere of
+// EarthRadius.
+func Area(p t38c.Polygon) float64 {
+	ring := openRing(p)
+	if len(ring) < 3 { //nolint:mnd // minimum polygon points
+		return 0
+	}
+
+	var sum float64
+
+	for i, a := range ring {
+		b := ring[(i+1)%len(ring)]
+		sum += rad(b.Lon-a.Lon) * (2 + math.Sin(rad(a.Lat)) + math.Sin(rad(b.Lat))) //nolint:mnd // spherical excess
+	}
+
+	return math.Abs(sum * EarthRadius * EarthRadius / 2) //nolint:mnd // spherical excess
+}
+
+// Centroid returns the centroid of a polygon, treating latitude and
+// longitude as planar coordinates, which is accurate for polygons small
+// enough not to be distorted by the curvature of the Earth. The centroid
+// of a polygon with no area is the mean of its points.
+func Centroid(p t38c.Polygon) t38c.Point {
+	ring := openRing(p)
+	if len(ring) == 0 {
+		return t38c.Point{}
+	}
+
+	var area, lat, lon float64
+
+	for i, a := range ring {
+		b := ring[(i+1)%len(ring)]
+		cross := a.Lon*b.Lat - b.Lon*a.Lat
+		area += cross
+		lon += (a.Lon + b.Lon) * cross
+		lat += (a.Lat + b.Lat) * cross
+	}
+
+	if area == 0 {
+		for _, pt := range ring {
+			lat += pt.Lat
+			lon += pt.Lon
+		}
+
+		return t38c.Point{Lat: lat / float64(len(ring)), Lon: lon / float64(len(ring))}
+	}
+
+	return t38c.Point{Lat: lat / (3 * area), Lon: lon / (3 * area)} //nolint:mnd // polygon centroid
+}
+
+// openRing returns the points of a polygon without a closing point.
+func openRing(p t38c.Polygon) []t38c.Point {
+	if len(p) > 1 && p[0] == p[len(p)-1] {
+		return p[:len(p)-1]
+	}
+
+	return p
+}
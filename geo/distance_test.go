@@ -0,0 +1,247 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package geo_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/geo"
+	"kreklow.us/go/t38c/t38ctest"
+)
+
+// Points used in the distance tests.
+var (
+	nashville   = t38c.Point{Lat: 36.12, Lon: -86.67}
+	losAngeles  = t38c.Point{Lat: 33.94, Lon: -118.40}
+	flinderPeak = t38c.Point{Lat: -37.95103342, Lon: 144.42486789}
+	buninyong   = t38c.Point{Lat: -37.65282114, Lon: 143.92649554}
+)
+
+// near reports whether a and b differ by no more than tol.
+func near(a float64, b float64, tol float64) bool {
+	return math.Abs(a-b) <= tol
+}
+
+// TestHaversine tests great-circle distances.
+func TestHaversine(t *testing.T) {
+	if d := geo.Haversine(nashville, losAngeles); !near(d, 2886444, 1) {
+		t.Errorf("Haversine: expected 2886444, received %v", d)
+	}
+
+	if d := geo.Haversine(nashville, nashville); d != 0 {
+		t.Errorf("Haversine: expected 0, received %v", d)
+	}
+
+	if d := geo.Haversine(t38c.Point{Lat: 0, Lon: 0}, t38c.Point{Lat: 0, Lon: 180}); !near(d, math.Pi*geo.EarthRadius, 1e-6) {
+		t.Errorf("Haversine: expected half circumference, received %v", d)
+	}
+}
+
+// TestHaversineServer tests that distances match those of NEARBY.
+func TestHaversineServer(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+	srv.Do("SET", "cities", "la", "POINT", "33.94", "-118.40")
+
+	r, err := srv.Connect(t).NearbyQuery("cities", t38c.NewQuery().Distance(), "POINT", "36.12", "-86.67")
+	if err != nil || len(r.Objects) != 1 {
+		t.Fatalf("NearbyQuery: unexpected %v, %v", r, err)
+	}
+
+	obj := r.Objects[0]
+	i := strings.Index(obj, `"distance":`)
+
+	if i < 0 || !strings.HasPrefix(obj[i+11:], "2886444") {
+		t.Errorf("NearbyQuery: expected distance 2886444, received %s", obj)
+	}
+}
+
+// TestVincenty tests ellipsoidal distances.
+func TestVincenty(t *testing.T) {
+	d, err := geo.Vincenty(flinderPeak, buninyong)
+	if err != nil || !near(d, 54972.271, 1e-3) {
+		t.Errorf("Vincenty: expected 54972.271, received %v, %v", d, err)
+	}
+
+	d, err = geo.Vincenty(buninyong, buninyong)
+	if err != nil || d != 0 {
+		t.Errorf("Vincenty: expected 0, received %v, %v", d, err)
+	}
+
+	d, err = geo.Vincenty(t38c.Point{Lat: 0, Lon: 0}, t38c.Point{Lat: 0, Lon: 90})
+	if err != nil || !near(d, 10018754.171, 1e-3) {
+		t.Errorf("Vincenty: expected 10018754.171, received %v, %v", d, err)
+	}
+
+	_, err = geo.Vincenty(t38c.Point{Lat: 0, Lon: 0}, t38c.Point{Lat: 0.5, Lon: 179.7})
+	if err == nil || err.Error() != "vincenty: failed to converge" {
+		t.Errorf("Vincenty: expected failure to converge, received %v", err)
+	}
+}
+
+// TestBearing tests initial bearings and destinations.
+func TestBearing(t *testing.T) {
+	tests := []struct {
+		a, b t38c.Point
+		exp  float64
+	}{
+		{t38c.Point{}, t38c.Point{Lat: 1}, 0},
+		{t38c.Point{}, t38c.Point{Lon: 1}, 90},
+		{t38c.Point{}, t38c.Point{Lat: -1}, 180},
+		{t38c.Point{}, t38c.Point{Lon: -1}, 270},
+		{nashville, losAngeles, 274.59},
+	}
+
+	for _, tc := range tests {
+		if act := geo.Bearing(tc.a, tc.b); !near(act, tc.exp, 0.01) {
+			t.Errorf("Bearing(%v, %v): expected %v, received %v", tc.a, tc.b, tc.exp, act)
+		}
+	}
+
+	brng := geo.Bearing(nashville, losAngeles)
+	dist := geo.Haversine(nashville, losAngeles)
+
+	p := geo.Destination(nashville, brng, dist)
+	if !near(p.Lat, losAngeles.Lat, 1e-9) || !near(p.Lon, losAngeles.Lon, 1e-9) {
+		t.Errorf("Destination: expected %v, received %v", losAngeles, p)
+	}
+
+	p = geo.Destination(t38c.Point{Lon: 179}, 90, geo.Haversine(t38c.Point{}, t38c.Point{Lon: 2}))
+	if !near(p.Lat, 0, 1e-9) || !near(p.Lon, -179, 1e-9) {
+		t.Errorf("Destination: expected to cross the antimeridian, received %v", p)
+	}
+}
+
+// TestBoundsAround tests bounding boxes around circles.
+func TestBoundsAround(t *testing.T) {
+	b, err := geo.BoundsAround(nashville, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, brng := range []float64{0, 45, 90, 135, 180, 225, 270, 315} {
+		p := geo.Destination(nashville, brng, 10000)
+		if p.Lat < b.MinLat-1e-9 || p.Lat > b.MaxLat+1e-9 || p.Lon < b.MinLon-1e-9 || p.Lon > b.MaxLon+1e-9 {
+			t.Errorf("BoundsAround: %+v does not contain %v", b, p)
+		}
+	}
+
+	if n := geo.Destination(nashville, 0, 10000); !near(n.Lat, b.MaxLat, 1e-9) {
+		t.Errorf("BoundsAround: expected max latitude %v, received %v", n.Lat, b.MaxLat)
+	}
+
+	tests := map[string]struct {
+		p   t38c.Point
+		m   float64
+		exp t38c.Bounds
+	}{
+		"Pole":         {t38c.Point{Lat: 89.99}, 10000, t38c.Bounds{MinLat: 89.99 - 10000/geo.EarthRadius*180/math.Pi, MinLon: -180, MaxLat: 90, MaxLon: 180}},
+		"Antimeridian": {t38c.Point{Lon: 179.99}, 10000, t38c.Bounds{MinLat: -10000 / geo.EarthRadius * 180 / math.Pi, MinLon: -180, MaxLat: 10000 / geo.EarthRadius * 180 / math.Pi, MaxLon: 180}},
+		"Zero":         {t38c.Point{Lat: 1, Lon: 2}, 0, t38c.Bounds{MinLat: 1, MinLon: 2, MaxLat: 1, MaxLon: 2}},
+	}
+
+	for name, tc := range tests {
+		b, err := geo.BoundsAround(tc.p, tc.m)
+		if err != nil || !near(b.MinLat, tc.exp.MinLat, 1e-9) || !near(b.MaxLat, tc.exp.MaxLat, 1e-9) ||
+			b.MinLon != tc.exp.MinLon || b.MaxLon != tc.exp.MaxLon {
+			t.Errorf("%s: expected %+v, received %+v, %v", name, tc.exp, b, err)
+		}
+	}
+
+	_, err = geo.BoundsAround(t38c.Point{}, -1)
+	if err == nil || err.Error() != "invalid area: radius -1 must not be negative" {
+		t.Errorf("BoundsAround: unexpected error %v", err)
+	}
+
+	_, err = geo.BoundsAround(t38c.Point{Lat: 100}, 1)
+	if err == nil {
+		t.Error("BoundsAround: expected error")
+	}
+}
+
+// TestArea tests polygon areas and centroids.
+func TestArea(t *testing.T) {
+	square := t38c.Polygon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 1}, {Lat: 1, Lon: 0}, {Lat: 0, Lon: 0}}
+
+	exp := geo.EarthRadius * geo.EarthRadius * math.Pi / 180 * math.Sin(math.Pi/180)
+	if a := geo.Area(square); !near(a, exp, 1e-3) {
+		t.Errorf("Area: expected %v, received %v", exp, a)
+	}
+
+	if a := geo.Area(square[:2]); a != 0 {
+		t.Errorf("Area: expected 0, received %v", a)
+	}
+
+	tests := []struct {
+		p   t38c.Polygon
+		exp t38c.Point
+	}{
+		{square, t38c.Point{Lat: 0.5, Lon: 0.5}},
+		{t38c.Polygon{{Lat: 0, Lon: 0}, {Lat: 3, Lon: 0}, {Lat: 0, Lon: 3}}, t38c.Point{Lat: 1, Lon: 1}},
+		{t38c.Polygon{{Lat: 0, Lon: 0}, {Lat: 1, Lon: 1}, {Lat: 2, Lon: 2}}, t38c.Point{Lat: 1, Lon: 1}},
+		{nil, t38c.Point{}},
+	}
+
+	for _, tc := range tests {
+		c := geo.Centroid(tc.p)
+		if !near(c.Lat, tc.exp.Lat, 1e-12) || !near(c.Lon, tc.exp.Lon, 1e-12) {
+			t.Errorf("Centroid(%v): expected %v, received %v", tc.p, tc.exp, c)
+		}
+	}
+}
+
+// TestParse tests decoding GeoJSON objects from responses.
+func TestParse(t *testing.T) {
+	p, err := geo.ParsePoint(`{"type":"Point","coordinates":[-112.2,33.5,100]}`)
+	if err != nil || p != (t38c.Point{Lat: 33.5, Lon: -112.2}) {
+		t.Errorf("ParsePoint: unexpected %v, %v", p, err)
+	}
+
+	poly, err := geo.ParsePolygon(`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]],[[0.1,0.1],[0.2,0.1],[0.1,0.2],[0.1,0.1]]]}`)
+	if err != nil || len(poly) != 4 || poly[1] != (t38c.Point{Lat: 0, Lon: 1}) {
+		t.Errorf("ParsePolygon: unexpected %v, %v", poly, err)
+	}
+
+	errs := map[string]func() error{
+		`invalid GeoJSON: {`:                  func() error { _, err := geo.ParsePoint(`{`); return err },
+		`GeoJSON type "Polygon" is not Point`: func() error { _, err := geo.ParsePoint(`{"type":"Polygon"}`); return err },
+		`invalid position: [1]`:               func() error { _, err := geo.ParsePoint(`{"type":"Point","coordinates":[1]}`); return err },
+		`invalid polygon: {"type":"Polygon","coordinates":[]}`: func() error {
+			_, err := geo.ParsePolygon(`{"type":"Polygon","coordinates":[]}`)
+			return err
+		},
+		`invalid position: ["a",1]`: func() error {
+			_, err := geo.ParsePolygon(`{"type":"Polygon","coordinates":[[["a",1]]]}`)
+			return err
+		},
+		`GeoJSON type "Point" is not Polygon`: func() error { _, err := geo.ParsePolygon(`{"type":"Point"}`); return err },
+	}
+
+	for exp, f := range errs {
+		if err := f(); err == nil || err.Error() != exp {
+			t.Errorf("expected %s, received %v", exp, err)
+		}
+	}
+}
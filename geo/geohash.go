@@ -0,0 +1,254 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package geo computes the geohash, XYZ tile and quadkey areas accepted
+// by Tile38 spatial queries.
+//
+// Geohashes are represented by t38c.Hash, and tiles and quadkeys by
+// Tile and Quadkey. All three implement t38c.Area, so they can be
+// passed directly to Database.WithinArea and Database.IntersectsArea:
+//
+//	tile, err := geo.TileAt(33.5, -112.2, 12)
+//	r, err := db.WithinArea("fleet", nil, tile)
+//
+// The package also provides distance, bearing, bounding box and polygon
+// area calculations for post-processing results, using the same
+// spherical Earth model as Tile38 unless noted otherwise. ParsePoint
+// and ParsePolygon decode the GeoJSON objects of a Response for use with
+// them.
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"kreklow.us/go/t38c"
+)
+
+// MaxHashPrecision is the maximum number of characters in a geohash.
+const MaxHashPrecision = 12
+
+// MaxCells is the maximum number of cells returned when covering a
+// bounding box.
+const MaxCells = 1 << 16
+
+// geohashChars is the geohash base32 alphabet.
+const geohashChars = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// EncodeHash returns the geohash of a point with the supplied number of
+// characters, which must be between 1 and MaxHashPrecision.
+func EncodeHash(lat float64, lon float64, precision int) (t38c.Hash, error) {
+	err := checkPrecision(precision)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = t38c.Point{Lat: lat, Lon: lon}.ObjectArgs()
+	if err != nil {
+		return "", err
+	}
+
+	return encodeHash(lat, lon, precision), nil
+}
+
+// encodeHash returns the geohash of a valid point.
+func encodeHash(lat float64, lon float64, precision int) t38c.Hash {
+	b := [4]float64{-90, -180, 90, 180} // minlat, minlon, maxlat, maxlon
+	even := true
+	hash := make([]byte, precision)
+
+	for i := range hash {
+		idx := 0
+
+		for range 5 {
+			// even bits refine longitude, odd bits refine latitude
+			lo, hi, v := 0, 2, lat
+			if even {
+				lo, hi, v = 1, 3, lon
+			}
+
+			idx <<= 1
+
+			mid := (b[lo] + b[hi]) / 2 //nolint:mnd // midpoint
+			if v >= mid {
+				idx |= 1
+				b[lo] = mid
+			} else {
+				b[hi] = mid
+			}
+
+			even = !even
+		}
+
+		hash[i] = geohashChars[idx]
+	}
+
+	return t38c.Hash(hash)
+}
+
+// DecodeHash returns the bounds of the cell covered by a geohash.
+func DecodeHash(h t38c.Hash) (t38c.Bounds, error) {
+	_, err := h.ObjectArgs()
+	if err != nil {
+		return t38c.Bounds{}, err
+	}
+
+	b := [4]float64{-90, -180, 90, 180}
+	even := true
+
+	for _, c := range string(h) {
+		idx := strings.IndexRune(geohashChars, c)
+
+		for bit := 4; bit >= 0; bit-- {
+			lo, hi := 0, 2
+			if even {
+				lo, hi = 1, 3
+			}
+
+			mid := (b[lo] + b[hi]) / 2 //nolint:mnd // midpoint
+			if idx&(1<<bit) != 0 {
+				b[lo] = mid
+			} else {
+				b[hi] = mid
+			}
+
+			even = !even
+		}
+	}
+
+	return t38c.Bounds{MinLat: b[0], MinLon: b[1], MaxLat: b[2], MaxLon: b[3]}, nil
+}
+
+// HashCenter returns the center of the cell covered by a geohash.
+func HashCenter(h t38c.Hash) (t38c.Point, error) {
+	b, err := DecodeHash(h)
+	if err != nil {
+		return t38c.Point{}, err
+	}
+
+	return center(b), nil
+}
+
+// HashNeighbors returns the geohashes of the cells adjacent to h at the
+// same precision, clockwise from north. Cells beyond the poles are
+// omitted, and cells across the antimeridian wrap around.
+func HashNeighbors(h t38c.Hash) ([]t38c.Hash, error) {
+	b, err := DecodeHash(h)
+	if err != nil {
+		return nil, err
+	}
+
+	c := center(b)
+	height, width := b.MaxLat-b.MinLat, b.MaxLon-b.MinLon
+
+	hashes := make([]t38c.Hash, 0, len(directions))
+
+	for _, d := range directions {
+		lat := c.Lat + float64(d[0])*height
+		if lat < -90 || lat > 90 {
+			continue
+		}
+
+		hashes = append(hashes, encodeHash(lat, wrapLon(c.Lon+float64(d[1])*width), len(h)))
+	}
+
+	return hashes, nil
+}
+
+// HashCover returns the geohashes of the supplied precision covering a
+// bounding box, ordered from south to north and west to east. It
+// returns an error if more than MaxCells would be required.
+func HashCover(b t38c.Bounds, precision int) ([]t38c.Hash, error) {
+	err := checkPrecision(precision)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = b.ObjectArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	bits := 5 * precision   //nolint:mnd // 5 bits per character
+	rows := 1 << (bits / 2) //nolint:mnd // latitude takes the odd bits
+	cols := 1 << (bits - bits/2)
+	height, width := 180/float64(rows), 360/float64(cols)
+
+	r0, r1 := cell(b.MinLat+90, height, rows), cell(b.MaxLat+90, height, rows)
+	c0, c1 := cell(b.MinLon+180, width, cols), cell(b.MaxLon+180, width, cols)
+
+	n := (r1 - r0 + 1) * (c1 - c0 + 1)
+	if n > MaxCells {
+		return nil, fmt.Errorf("bounds require %d cells at precision %d, more than %d", n, precision, MaxCells)
+	}
+
+	hashes := make([]t38c.Hash, 0, n)
+
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			lat := -90 + (float64(r)+0.5)*height //nolint:mnd // cell center
+			lon := -180 + (float64(c)+0.5)*width //nolint:mnd // cell center
+			hashes = append(hashes, encodeHash(lat, lon, precision))
+		}
+	}
+
+	return hashes, nil
+}
+
+// checkPrecision validates a geohash precision.
+func checkPrecision(precision int) error {
+	if precision < 1 || precision > MaxHashPrecision {
+		return fmt.Errorf("invalid precision: geohash must be 1 to %d characters", MaxHashPrecision)
+	}
+
+	return nil
+}
+
+// directions are the offsets in rows and columns of adjacent cells,
+// clockwise from north.
+var directions = [8][2]int{ //nolint:gochecknoglobals // constant table
+	{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1},
+}
+
+// cell returns the index of the cell of the supplied size containing
+// offset v, limited to the last of n cells.
+func cell(v float64, size float64, n int) int {
+	return min(int(math.Floor(v/size)), n-1)
+}
+
+// center returns the center of a bounding box.
+func center(b t38c.Bounds) t38c.Point {
+	return t38c.Point{Lat: (b.MinLat + b.MaxLat) / 2, Lon: (b.MinLon + b.MaxLon) / 2} //nolint:mnd // midpoint
+}
+
+// wrapLon wraps a longitude into the range -180 to 180.
+func wrapLon(lon float64) float64 {
+	switch {
+	case lon >= 180: //nolint:mnd // antimeridian
+		return lon - 360 //nolint:mnd // full circle
+	case lon < -180: //nolint:mnd // antimeridian
+		return lon + 360 //nolint:mnd // full circle
+	}
+
+	return lon
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package geo_test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/geo"
+)
+
+// TestEncodeHash tests encoding points as geohashes.
+func TestEncodeHash(t *testing.T) {
+	tests := []struct {
+		lat, lon  float64
+		precision int
+		exp       t38c.Hash
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{42.6, -5.6, 5, "ezs42"},
+		{-90, -180, 12, "000000000000"},
+		{90, 180, 3, "zzz"},
+		{33.5, -112.2, 1, "9"},
+	}
+
+	for _, tc := range tests {
+		h, err := geo.EncodeHash(tc.lat, tc.lon, tc.precision)
+		if err != nil || h != tc.exp {
+			t.Errorf("EncodeHash(%v, %v, %d): expected %s, received %s, %v", tc.lat, tc.lon, tc.precision, tc.exp, h, err)
+		}
+	}
+
+	errs := map[string][3]float64{
+		"invalid precision: geohash must be 1 to 12 characters": {0, 0, 13},
+		"invalid object: latitude 91 out of range":              {91, 0, 5},
+	}
+
+	for exp, args := range errs {
+		_, err := geo.EncodeHash(args[0], args[1], int(args[2]))
+		if err == nil || err.Error() != exp {
+			t.Errorf("EncodeHash(%v): expected %s, received %v", args, exp, err)
+		}
+	}
+}
+
+// TestDecodeHash tests decoding geohashes.
+func TestDecodeHash(t *testing.T) {
+	b, err := geo.DecodeHash("ezs42")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := t38c.Bounds{MinLat: 42.5830078125, MinLon: -5.625, MaxLat: 42.626953125, MaxLon: -5.5810546875}
+	if b != exp {
+		t.Errorf("DecodeHash: expected %+v, received %+v", exp, b)
+	}
+
+	c, err := geo.HashCenter("u4pruydqqvj")
+	if err != nil || math.Abs(c.Lat-57.64911) > 1e-5 || math.Abs(c.Lon-10.40744) > 1e-5 {
+		t.Errorf("HashCenter: unexpected %+v, %v", c, err)
+	}
+
+	_, err = geo.HashCenter("ai")
+	if err == nil || err.Error() != `invalid object: invalid geohash "ai"` {
+		t.Errorf("HashCenter: unexpected error %v", err)
+	}
+}
+
+// TestHashNeighbors tests finding adjacent geohashes.
+func TestHashNeighbors(t *testing.T) {
+	tests := map[t38c.Hash][]t38c.Hash{
+		"gbsuv": {"gbsvj", "gbsvn", "gbsuy", "gbsuw", "gbsut", "gbsus", "gbsuu", "gbsvh"},
+		"b":     {"c", "9", "8", "x", "z"},
+		"0":     {"2", "3", "1", "p", "r"},
+	}
+
+	for h, exp := range tests {
+		hs, err := geo.HashNeighbors(h)
+		if err != nil || !reflect.DeepEqual(hs, exp) {
+			t.Errorf("HashNeighbors(%s): expected %v, received %v, %v", h, exp, hs, err)
+		}
+	}
+
+	_, err := geo.HashNeighbors("")
+	if err == nil {
+		t.Error("HashNeighbors: expected error")
+	}
+}
+
+// TestHashCover tests covering bounding boxes with geohashes.
+func TestHashCover(t *testing.T) {
+	hs, err := geo.HashCover(t38c.Bounds{MinLat: 30, MinLon: -120, MaxLat: 50, MaxLon: -80}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := []t38c.Hash{"9", "d", "c", "f"}; !reflect.DeepEqual(hs, exp) {
+		t.Errorf("HashCover: expected %v, received %v", exp, hs)
+	}
+
+	hs, err = geo.HashCover(t38c.Bounds{MinLat: 42.6, MinLon: -5.6, MaxLat: 42.6, MaxLon: -5.6}, 5)
+	if err != nil || !reflect.DeepEqual(hs, []t38c.Hash{"ezs42"}) {
+		t.Errorf("HashCover: unexpected %v, %v", hs, err)
+	}
+
+	hs, err = geo.HashCover(t38c.Bounds{MinLat: -90, MinLon: -180, MaxLat: 90, MaxLon: 180}, 2)
+	if err != nil || len(hs) != 1024 || hs[0] != "00" || hs[1023] != "zz" {
+		t.Errorf("HashCover: unexpected %d cells, %v", len(hs), err)
+	}
+
+	errs := map[string]struct {
+		b t38c.Bounds
+		p int
+	}{
+		"invalid precision: geohash must be 1 to 12 characters":        {t38c.Bounds{}, 0},
+		"invalid object: bounds minimum is greater than maximum":       {t38c.Bounds{MinLat: 1}, 5},
+		"bounds require 1048576 cells at precision 4, more than 65536": {t38c.Bounds{MinLat: -90, MinLon: -180, MaxLat: 90, MaxLon: 180}, 4},
+	}
+
+	for exp, tc := range errs {
+		_, err := geo.HashCover(tc.b, tc.p)
+		if err == nil || !strings.HasPrefix(err.Error(), exp) {
+			t.Errorf("HashCover(%+v, %d): expected %s, received %v", tc.b, tc.p, exp, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package geo
+
+import (
+	"fmt"
+
+	"github.com/tidwall/gjson"
+	"kreklow.us/go/t38c"
+)
+
+// ParsePoint returns the point in a GeoJSON Point object, such as
+// Response.Object or the object of an element of Response.Objects.
+func ParsePoint(object string) (t38c.Point, error) {
+	g, err := parseGeometry(object, "Point")
+	if err != nil {
+		return t38c.Point{}, err
+	}
+
+	return position(g.Get("coordinates"))
+}
+
+// ParsePolygon returns the exterior ring of a GeoJSON Polygon object,
+// such as Response.Object or the object of an element of
+// Response.Objects. Any holes are ignored.
+func ParsePolygon(object string) (t38c.Polygon, error) {
+	g, err := parseGeometry(object, "Polygon")
+	if err != nil {
+		return nil, err
+	}
+
+	coords := g.Get("coordinates.0").Array()
+	if len(coords) == 0 {
+		return nil, fmt.Errorf("invalid polygon: %s", object)
+	}
+
+	p := make(t38c.Polygon, len(coords))
+
+	for i, c := range coords {
+		p[i], err = position(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// parseGeometry parses a GeoJSON object and checks its type.
+func parseGeometry(object string, typ string) (gjson.Result, error) {
+	if !gjson.Valid(object) {
+		return gjson.Result{}, fmt.Errorf("invalid GeoJSON: %s", object)
+	}
+
+	g := gjson.Parse(object)
+	if t := g.Get("type").String(); t != typ {
+		return g, fmt.Errorf("GeoJSON type %q is not %s", t, typ)
+	}
+
+	return g, nil
+}
+
+// position converts a GeoJSON position to a point.
+func position(c gjson.Result) (t38c.Point, error) {
+	v := c.Array()
+	if len(v) < 2 || v[0].Type != gjson.Number || v[1].Type != gjson.Number { //nolint:mnd // lon, lat
+		return t38c.Point{}, fmt.Errorf("invalid position: %s", c.Raw)
+	}
+
+	return t38c.Point{Lat: v[1].Num, Lon: v[0].Num}, nil
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package geo
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"kreklow.us/go/t38c"
+)
+
+// MaxZoom is the maximum zoom level of a Tile.
+const MaxZoom = 30
+
+// MaxMercatorLat is the latitude at the northern edge of the Web
+// Mercator projection used by tiles.
+const MaxMercatorLat = 85.05112877980659
+
+// Tile is a Web Mercator map tile in the XYZ scheme, with tile 0, 0 at
+// the north-west corner of each zoom level.
+type Tile struct {
+	X, Y, Z int
+}
+
+// TileAt returns the tile containing a point at zoom level z. Points
+// beyond the latitude limits of the projection fall in the northern-
+// or southernmost tiles.
+func TileAt(lat float64, lon float64, z int) (Tile, error) {
+	if z < 0 || z > MaxZoom {
+		return Tile{}, fmt.Errorf("invalid area: tile zoom must be 0 to %d", MaxZoom)
+	}
+
+	_, err := t38c.Point{Lat: lat, Lon: lon}.ObjectArgs()
+	if err != nil {
+		return Tile{}, err
+	}
+
+	lat = math.Max(-MaxMercatorLat, math.Min(MaxMercatorLat, lat))
+	n := 1 << z
+
+	rad := lat * math.Pi / 180                                                  //nolint:mnd // degrees to radians
+	y := (1 - math.Log(math.Tan(rad)+1/math.Cos(rad))/math.Pi) / 2 * float64(n) //nolint:mnd // Web Mercator
+
+	return Tile{
+		X: cell(lon+180, 360/float64(n), n), //nolint:mnd // degrees
+		Y: max(0, min(int(y), n-1)),
+		Z: z,
+	}, nil
+}
+
+// AreaArgs implements t38c.Area.
+func (t Tile) AreaArgs() ([]string, error) {
+	err := t.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{"TILE", strconv.Itoa(t.X), strconv.Itoa(t.Y), strconv.Itoa(t.Z)}, nil
+}
+
+// check validates the zoom level and coordinates of the tile.
+func (t Tile) check() error {
+	if t.Z < 0 || t.Z > MaxZoom {
+		return fmt.Errorf("invalid area: tile zoom must be 0 to %d", MaxZoom)
+	}
+
+	n := 1 << t.Z
+	if t.X < 0 || t.X >= n || t.Y < 0 || t.Y >= n {
+		return fmt.Errorf("invalid area: tile x and y must be 0 to %d at zoom %d", n-1, t.Z)
+	}
+
+	return nil
+}
+
+// Bounds returns the bounds of the tile.
+func (t Tile) Bounds() t38c.Bounds {
+	n := math.Exp2(float64(t.Z))
+	lat := func(y int) float64 {
+		return math.Atan(math.Sinh(math.Pi*(1-2*float64(y)/n))) * 180 / math.Pi //nolint:mnd // Web Mercator
+	}
+
+	return t38c.Bounds{
+		MinLat: lat(t.Y + 1),
+		MinLon: float64(t.X)/n*360 - 180, //nolint:mnd // degrees
+		MaxLat: lat(t.Y),
+		MaxLon: float64(t.X+1)/n*360 - 180, //nolint:mnd // degrees
+	}
+}
+
+// Center returns the center of the bounds of the tile.
+func (t Tile) Center() t38c.Point {
+	return center(t.Bounds())
+}
+
+// Quadkey returns the quadkey of the tile.
+func (t Tile) Quadkey() Quadkey {
+	b := make([]byte, t.Z)
+
+	for i := range b {
+		bit := t.Z - 1 - i
+		b[i] = byte('0' + (t.X>>bit)&1 + ((t.Y>>bit)&1)<<1)
+	}
+
+	return Quadkey(b)
+}
+
+// Neighbors returns the tiles adjacent to t at the same zoom level,
+// clockwise from north. Tiles beyond the edges of the projection are
+// omitted, and tiles across the antimeridian wrap around.
+func (t Tile) Neighbors() []Tile {
+	n := 1 << t.Z
+	tiles := make([]Tile, 0, len(directions))
+
+	for _, d := range directions {
+		y := t.Y - d[0] // rows increase to the south
+		if y < 0 || y >= n {
+			continue
+		}
+
+		nt := Tile{X: (t.X + d[1] + n) % n, Y: y, Z: t.Z}
+
+		// at low zoom levels, wrapping can reach t or repeat a tile
+		if nt != t && !slices.Contains(tiles, nt) {
+			tiles = append(tiles, nt)
+		}
+	}
+
+	return tiles
+}
+
+// TileCover returns the tiles at zoom level z covering a bounding box,
+// ordered from north to south and west to east. It returns an error if
+// more than MaxCells would be required.
+func TileCover(b t38c.Bounds, z int) ([]Tile, error) {
+	_, err := b.ObjectArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	nw, err := TileAt(b.MaxLat, b.MinLon, z)
+	if err != nil {
+		return nil, err
+	}
+
+	se, _ := TileAt(b.MinLat, b.MaxLon, z)
+
+	n := (se.X - nw.X + 1) * (se.Y - nw.Y + 1)
+	if n > MaxCells {
+		return nil, fmt.Errorf("bounds require %d tiles at zoom %d, more than %d", n, z, MaxCells)
+	}
+
+	tiles := make([]Tile, 0, n)
+
+	for y := nw.Y; y <= se.Y; y++ {
+		for x := nw.X; x <= se.X; x++ {
+			tiles = append(tiles, Tile{X: x, Y: y, Z: z})
+		}
+	}
+
+	return tiles, nil
+}
+
+// Quadkey is a tile identified by a string of the digits 0 to 3, one
+// per zoom level.
+type Quadkey string
+
+// AreaArgs implements t38c.Area.
+func (q Quadkey) AreaArgs() ([]string, error) {
+	_, err := q.Tile()
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{"QUADKEY", string(q)}, nil
+}
+
+// Tile returns the tile identified by the quadkey.
+func (q Quadkey) Tile() (Tile, error) {
+	if len(q) < 1 || len(q) > MaxZoom {
+		return Tile{}, fmt.Errorf("invalid area: quadkey must be 1 to %d digits", MaxZoom)
+	}
+
+	if strings.Trim(string(q), "0123") != "" {
+		return Tile{}, fmt.Errorf("invalid area: invalid quadkey %q", string(q))
+	}
+
+	t := Tile{Z: len(q)}
+
+	for _, c := range q {
+		d := int(c - '0')
+		t.X = t.X<<1 | d&1
+		t.Y = t.Y<<1 | d>>1
+	}
+
+	return t, nil
+}
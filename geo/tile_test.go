@@ -0,0 +1,209 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package geo_test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/geo"
+	"kreklow.us/go/t38c/t38ctest"
+)
+
+// TestTileAt tests finding the tile containing a point.
+func TestTileAt(t *testing.T) {
+	tests := []struct {
+		lat, lon float64
+		z        int
+		exp      geo.Tile
+	}{
+		{41.85, -87.65, 10, geo.Tile{X: 262, Y: 380, Z: 10}},
+		{90, 180, 2, geo.Tile{X: 3, Y: 0, Z: 2}},
+		{-90, -180, 2, geo.Tile{X: 0, Y: 3, Z: 2}},
+		{0, 0, 0, geo.Tile{}},
+	}
+
+	for _, tc := range tests {
+		tile, err := geo.TileAt(tc.lat, tc.lon, tc.z)
+		if err != nil || tile != tc.exp {
+			t.Errorf("TileAt(%v, %v, %d): expected %+v, received %+v, %v", tc.lat, tc.lon, tc.z, tc.exp, tile, err)
+		}
+	}
+
+	_, err := geo.TileAt(0, 0, 31)
+	if err == nil || err.Error() != "invalid area: tile zoom must be 0 to 30" {
+		t.Errorf("TileAt: unexpected error %v", err)
+	}
+
+	_, err = geo.TileAt(0, 181, 1)
+	if err == nil || err.Error() != "invalid object: longitude 181 out of range" {
+		t.Errorf("TileAt: unexpected error %v", err)
+	}
+}
+
+// TestTileBounds tests the bounds and center of tiles.
+func TestTileBounds(t *testing.T) {
+	b := geo.Tile{}.Bounds()
+	if math.Abs(b.MaxLat-geo.MaxMercatorLat) > 1e-9 || b.MinLat != -b.MaxLat || b.MinLon != -180 || b.MaxLon != 180 {
+		t.Errorf("Bounds: unexpected %+v", b)
+	}
+
+	tile := geo.Tile{X: 262, Y: 380, Z: 10}
+	b = tile.Bounds()
+
+	if b.MinLat > 41.85 || b.MaxLat < 41.85 || b.MinLon > -87.65 || b.MaxLon < -87.65 {
+		t.Errorf("Bounds: %+v does not contain the point", b)
+	}
+
+	c := tile.Center()
+
+	back, err := geo.TileAt(c.Lat, c.Lon, tile.Z)
+	if err != nil || back != tile {
+		t.Errorf("Center: %+v is in %+v, %v", c, back, err)
+	}
+}
+
+// TestQuadkey tests converting between tiles and quadkeys.
+func TestQuadkey(t *testing.T) {
+	tests := map[geo.Quadkey]geo.Tile{
+		"213":  {X: 3, Y: 5, Z: 3},
+		"0":    {X: 0, Y: 0, Z: 1},
+		"3333": {X: 15, Y: 15, Z: 4},
+	}
+
+	for q, tile := range tests {
+		if act := tile.Quadkey(); act != q {
+			t.Errorf("Quadkey(%+v): expected %s, received %s", tile, q, act)
+		}
+
+		act, err := q.Tile()
+		if err != nil || act != tile {
+			t.Errorf("Tile(%s): expected %+v, received %+v, %v", q, tile, act, err)
+		}
+	}
+
+	errs := map[geo.Quadkey]string{
+		"":                                "invalid area: quadkey must be 1 to 30 digits",
+		"0123012301230123012301230123012": "invalid area: quadkey must be 1 to 30 digits",
+		"124":                             `invalid area: invalid quadkey "124"`,
+	}
+
+	for q, exp := range errs {
+		_, err := q.AreaArgs()
+		if err == nil || err.Error() != exp {
+			t.Errorf("AreaArgs(%s): expected %s, received %v", q, exp, err)
+		}
+	}
+}
+
+// TestTileNeighbors tests finding adjacent tiles.
+func TestTileNeighbors(t *testing.T) {
+	tests := []struct {
+		tile geo.Tile
+		exp  []geo.Tile
+	}{
+		{geo.Tile{X: 5, Y: 5, Z: 4}, []geo.Tile{
+			{5, 4, 4}, {6, 4, 4}, {6, 5, 4}, {6, 6, 4}, {5, 6, 4}, {4, 6, 4}, {4, 5, 4}, {4, 4, 4},
+		}},
+		{geo.Tile{X: 0, Y: 0, Z: 2}, []geo.Tile{{1, 0, 2}, {1, 1, 2}, {0, 1, 2}, {3, 1, 2}, {3, 0, 2}}},
+		{geo.Tile{X: 0, Y: 0, Z: 1}, []geo.Tile{{1, 0, 1}, {1, 1, 1}, {0, 1, 1}}},
+		{geo.Tile{}, []geo.Tile{}},
+	}
+
+	for _, tc := range tests {
+		if act := tc.tile.Neighbors(); !reflect.DeepEqual(act, tc.exp) {
+			t.Errorf("Neighbors(%+v): expected %v, received %v", tc.tile, tc.exp, act)
+		}
+	}
+}
+
+// TestTileCover tests covering bounding boxes with tiles.
+func TestTileCover(t *testing.T) {
+	tiles, err := geo.TileCover(t38c.Bounds{MinLat: -10, MinLon: -10, MaxLat: 10, MaxLon: 10}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []geo.Tile{{0, 0, 1}, {1, 0, 1}, {0, 1, 1}, {1, 1, 1}}
+	if !reflect.DeepEqual(tiles, exp) {
+		t.Errorf("TileCover: expected %v, received %v", exp, tiles)
+	}
+
+	errs := map[string]struct {
+		b t38c.Bounds
+		z int
+	}{
+		"invalid object: bounds minimum is greater than maximum": {t38c.Bounds{MinLon: 1}, 1},
+		"invalid area: tile zoom must be 0 to 30":                {t38c.Bounds{}, -1},
+		"bounds require 262144 tiles at zoom 9":                  {t38c.Bounds{MinLat: -90, MinLon: -180, MaxLat: 90, MaxLon: 180}, 9},
+	}
+
+	for exp, tc := range errs {
+		_, err := geo.TileCover(tc.b, tc.z)
+		if err == nil || !strings.HasPrefix(err.Error(), exp) {
+			t.Errorf("TileCover(%+v, %d): expected %s, received %v", tc.b, tc.z, exp, err)
+		}
+	}
+
+	_, err = geo.Tile{X: 4, Y: 0, Z: 2}.AreaArgs()
+	if err == nil || err.Error() != "invalid area: tile x and y must be 0 to 3 at zoom 2" {
+		t.Errorf("AreaArgs: unexpected error %v", err)
+	}
+
+	_, err = geo.Tile{Z: 31}.AreaArgs()
+	if err == nil || err.Error() != "invalid area: tile zoom must be 0 to 30" {
+		t.Errorf("AreaArgs: unexpected error %v", err)
+	}
+}
+
+// TestAreaQueries tests using the areas in spatial queries.
+func TestAreaQueries(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+	srv.Do("SET", "fleet", "phx", "POINT", "33.5", "-112.2")
+	srv.Do("SET", "fleet", "nyc", "POINT", "40.7", "-74")
+
+	db := srv.Connect(t)
+
+	tile, err := geo.TileAt(33.5, -112.2, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := geo.EncodeHash(33.5, -112.2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := t38c.NewQuery().Output(t38c.OutputIDs)
+
+	for _, a := range []t38c.Area{tile, tile.Quadkey(), hash} {
+		r, err := db.WithinArea("fleet", q, a)
+		if err != nil || !reflect.DeepEqual(r.IDs, []string{"phx"}) {
+			args, _ := a.AreaArgs()
+			t.Errorf("WithinArea(%v): unexpected %v, %v", args, r, err)
+		}
+	}
+}
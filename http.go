@@ -0,0 +1,172 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxHTTPResponse is the largest HTTP response body accepted.
+const maxHTTPResponse = 1 << 30
+
+// WithHTTP returns an Option which sends commands to Tile38's HTTP
+// interface instead of using RESP connections. If client is nil, a
+// client with its own connection pool is used. Database.Close only
+// closes the idle connections of a client created this way.
+func WithHTTP(client *http.Client) Option {
+	return withHTTP("http", client)
+}
+
+// WithHTTPS returns an Option which sends commands to Tile38's HTTP
+// interface using TLS. If client is nil, a client with its own
+// connection pool is used, as with WithHTTP.
+func WithHTTPS(client *http.Client) Option {
+	return withHTTP("https", client)
+}
+
+// withHTTP returns an Option selecting the HTTP transport.
+func withHTTP(scheme string, client *http.Client) Option {
+	return func(db *Database) {
+		owned := client == nil
+		if owned {
+			client = &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()} //nolint:forcetypeassert // DefaultTransport is an *http.Transport
+		}
+
+		db.tr = &httpTransport{
+			client: client,
+			scheme: scheme,
+			owned:  owned,
+		}
+	}
+}
+
+// httpTransport is a Transport using Tile38's HTTP interface, which
+// accepts commands as a request path with arguments separated by "+",
+// such as GET /SCAN+fleet.
+type httpTransport struct {
+	client *http.Client
+	scheme string
+	host   string
+	owned  bool // client was created by withHTTP
+}
+
+// connect sets the server address and checks that the server responds
+// to a PING.
+func (t *httpTransport) connect(db *Database, server string, port string) error {
+	t.host = net.JoinHostPort(server, port)
+
+	r := new(Response)
+
+	err := t.Do(r, "PING")
+	if err == nil && !r.Ok {
+		err = errResponse
+	}
+
+	db.logDial(t.host, err)
+
+	return err
+}
+
+// Do sends a command as an HTTP GET request.
+func (t *httpTransport) Do(r *Response, cmd string, args ...string) error {
+	resp, err := t.get(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponse))
+	if err != nil {
+		return err
+	}
+
+	if r == nil {
+		if resp.StatusCode != http.StatusOK {
+			return newErrorf(nil, "unexpected HTTP status: %s", resp.Status)
+		}
+
+		return nil
+	}
+
+	err = r.UnmarshalText(b)
+	if err != nil && resp.StatusCode != http.StatusOK {
+		return newErrorf(nil, "unexpected HTTP status: %s", resp.Status)
+	}
+
+	return err
+}
+
+// Stream sends a command as an HTTP GET request, decoding the reply as
+// it is read.
+func (t *httpTransport) Stream(r *Response, fn ObjectFunc, cmd string, args ...string) error {
+	resp, err := t.get(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	err = decodeStream(io.LimitReader(resp.Body, maxHTTPResponse), r, fn)
+
+	var ferr *objectFuncError
+	if err != nil && !errors.As(err, &ferr) && resp.StatusCode != http.StatusOK {
+		return newErrorf(nil, "unexpected HTTP status: %s", resp.Status)
+	}
+
+	return err
+}
+
+// get sends a command as an HTTP GET request.
+func (t *httpTransport) get(cmd string, args []string) (*http.Response, error) {
+	u := t.scheme + "://" + t.host + "/" + httpArgs(cmd, args)
+
+	return t.client.Get(u) //nolint:noctx,wrapcheck // no context in the Database API
+}
+
+// Close closes any idle connections held by the client, if it was
+// created by the transport.
+func (t *httpTransport) Close() error {
+	if t.owned {
+		t.client.CloseIdleConnections()
+	}
+
+	return nil
+}
+
+// httpArgs returns the command and arguments escaped and joined with
+// "+" for use as a request path.
+func httpArgs(cmd string, args []string) string {
+	esc := make([]string, 0, len(args)+1)
+
+	for _, a := range append([]string{cmd}, args...) {
+		esc = append(esc, strings.ReplaceAll(url.PathEscape(a), "+", "%2B"))
+	}
+
+	return strings.Join(esc, "+")
+}
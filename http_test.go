@@ -0,0 +1,203 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// TestHTTP tests the HTTP transport.
+func TestHTTP(t *testing.T) {
+	t.Run("Commands", testHTTPCommands)
+	t.Run("Errors", testHTTPErrors)
+	t.Run("Status", testHTTPStatus)
+	t.Run("Connect", testHTTPConnect)
+	t.Run("Close", testHTTPClose)
+}
+
+// Test commands sent over HTTP.
+func testHTTPCommands(t *testing.T) {
+	srv.HandleFunc("PING", nil)
+	srv.ResetCommands()
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1, t38c.WithHTTP(nil))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	srv.HandleFunc("SET", srv.ReturnOkTrue)
+	srv.HandleFunc("GET", srv.ReturnOkTrue)
+
+	err = db.Set("test", "a+b c/d", "STRING", "100% {\"x\"}")
+	if err != nil {
+		tFatalErr(t, "Set", err)
+	}
+
+	r, err := db.Get("test", "a+b c/d")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	if r.Object != mock.TestObject {
+		tErrorStr(t, "Get", mock.TestObject, r.Object)
+	}
+
+	tCommands(t, "Commands", "SET test a+b c/d STRING 100% {\"x\"}", "GET test a+b c/d")
+}
+
+// Test errors returned over HTTP.
+func testHTTPErrors(t *testing.T) {
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1, t38c.WithHTTP(nil))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	tests := map[string]func(*testing.T){
+		"received error: " + mock.TestServerError: func(*testing.T) { srv.HandleFunc("SCAN", srv.ReturnErr) },
+		"received error: " + mock.TestOkFalse:     func(*testing.T) { srv.HandleFunc("SCAN", srv.ReturnOkFalse) },
+		"not valid JSON": func(*testing.T) {
+			srv.HandleFunc("SCAN", srv.ReturnOkTrue)
+			srv.Inject("SCAN", mock.FaultMalformed)
+		},
+	}
+
+	for exp, setup := range tests {
+		setup(t)
+
+		_, err = db.Scan("test")
+		if err == nil || !strings.Contains(err.Error(), exp) {
+			tErrorStr(t, "Scan", exp, err)
+		}
+	}
+}
+
+// Test a server returning a non-JSON error page.
+func testHTTPStatus(t *testing.T) {
+	var fail atomic.Bool
+
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fail.Load() {
+			http.Error(w, "<html>unavailable</html>", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"ping":"pong"}`)) //nolint:errcheck // test server
+	}))
+	defer hs.Close()
+
+	u, _ := url.Parse(hs.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+
+	db, err := t38c.Connect(host, port, 1, t38c.WithHTTP(hs.Client()))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	fail.Store(true)
+
+	_, err = db.Get("test", "obj1")
+
+	exp := "database error: unexpected HTTP status: 503 Service Unavailable"
+	if err == nil || err.Error() != exp {
+		tErrorStr(t, "Get", exp, err)
+	}
+}
+
+// Test connecting to an unavailable server.
+func testHTTPConnect(t *testing.T) {
+	hs := httptest.NewTLSServer(http.NotFoundHandler())
+	u, _ := url.Parse(hs.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+
+	_, err := t38c.Connect(host, port, 1, t38c.WithHTTPS(hs.Client()))
+	if err == nil || !strings.HasPrefix(err.Error(), "error connecting to server") {
+		tErrorStr(t, "Connect", "error connecting to server", err)
+	}
+
+	hs.Close()
+
+	_, err = t38c.Connect(host, port, 1, t38c.WithHTTP(nil))
+	if !t38c.IsRetryable(err) {
+		tErrorStr(t, "Connect", "network error", err)
+	}
+}
+
+// closeCounter is an http.RoundTripper counting calls to
+// CloseIdleConnections.
+type closeCounter struct {
+	http.RoundTripper
+	closed atomic.Int32
+}
+
+// CloseIdleConnections counts the call.
+func (c *closeCounter) CloseIdleConnections() {
+	c.closed.Add(1)
+}
+
+// Test that Close leaves the connections of a client supplied by the
+// caller open.
+func testHTTPClose(t *testing.T) {
+	srv.HandleFunc("PING", nil)
+
+	cc := &closeCounter{RoundTripper: http.DefaultTransport}
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1, t38c.WithHTTP(&http.Client{Transport: cc}))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	err = db.Close()
+	if err != nil {
+		tFatalErr(t, "Close", err)
+	}
+
+	if n := cc.closed.Load(); n != 0 {
+		tErrorVal(t, "CloseIdleConnections", 0, n)
+	}
+
+	db, err = t38c.Connect(srv.Addr, srv.Port, 1, t38c.WithHTTP(nil))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	err = db.Close()
+	if err != nil {
+		tFatalErr(t, "Close", err)
+	}
+}
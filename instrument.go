@@ -0,0 +1,167 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrorClass describes the kind of failure encountered by a command.
+type ErrorClass string
+
+// Error classes reported in a CommandEvent.
+const (
+	ClassNone    ErrorClass = "none"         // command succeeded
+	ClassNetwork ErrorClass = "network"      // transient network error
+	ClassServer  ErrorClass = "server"       // error reported by Tile38
+	ClassTimeout ErrorClass = "timeout"      // server-side timeout exceeded
+	ClassCircuit ErrorClass = "circuit_open" // rejected by circuit breaker
+	ClassOther   ErrorClass = "other"        // any other error
+)
+
+// Classify returns the ErrorClass of an error returned by a Database
+// method.
+func Classify(err error) ErrorClass {
+	switch {
+	case err == nil:
+		return ClassNone
+	case errors.Is(err, ErrCircuitOpen):
+		return ClassCircuit
+	case errors.Is(err, ErrTimeout):
+		return ClassTimeout
+	case errors.Is(err, errResponse):
+		return ClassServer
+	case IsRetryable(err):
+		return ClassNetwork
+	default:
+		return ClassOther
+	}
+}
+
+// CommandEvent describes a completed command, including all retries.
+type CommandEvent struct {
+	Command  string        // command name, such as "GET"
+	Key      string        // collection key, if the command has one
+	Start    time.Time     // time the command was started
+	Duration time.Duration // time taken as seen by the client
+	Elapsed  time.Duration // time taken as reported by Tile38, if known
+	Attempts int           // number of attempts made
+	Err      error         // error returned to the caller
+	Class    ErrorClass    // classification of Err
+}
+
+// Instrument receives a CommandEvent for every command run against the
+// database. ObserveCommand is called synchronously after the command
+// completes and must be safe for concurrent use.
+type Instrument interface {
+	ObserveCommand(e *CommandEvent)
+}
+
+// InstrumentFunc is a function implementing Instrument.
+type InstrumentFunc func(e *CommandEvent)
+
+// ObserveCommand calls f(e).
+func (f InstrumentFunc) ObserveCommand(e *CommandEvent) {
+	f(e)
+}
+
+// WithInstrument returns an Option adding an Instrument to the
+// Database. The option may be given more than once.
+func WithInstrument(in Instrument) Option {
+	return func(db *Database) {
+		db.instruments = append(db.instruments, in)
+	}
+}
+
+// Metrics is an Instrument which reports commands to counter and
+// histogram functions, such as closures over the WithLabelValues
+// methods of Prometheus CounterVec and HistogramVec collectors. Nil
+// functions are skipped.
+type Metrics struct {
+	// Count is called once for each command.
+	Count func(command string, class ErrorClass)
+
+	// Latency is called with the duration of each command in seconds
+	// as seen by the client.
+	Latency func(command string, seconds float64)
+
+	// ServerLatency is called with the duration of each command in
+	// seconds as reported by Tile38, when available.
+	ServerLatency func(command string, seconds float64)
+}
+
+// ObserveCommand implements Instrument.
+func (m Metrics) ObserveCommand(e *CommandEvent) {
+	if m.Count != nil {
+		m.Count(e.Command, e.Class)
+	}
+
+	if m.Latency != nil {
+		m.Latency(e.Command, e.Duration.Seconds())
+	}
+
+	if m.ServerLatency != nil && e.Elapsed > 0 {
+		m.ServerLatency(e.Command, e.Elapsed.Seconds())
+	}
+}
+
+// Tracer is an Instrument which reports each command as a completed
+// span, such as an OpenTelemetry span started and ended with explicit
+// timestamps. Attribute names follow the OpenTelemetry database
+// semantic conventions.
+type Tracer struct {
+	Span func(name string, start, end time.Time, attrs map[string]string, err error)
+}
+
+// ObserveCommand implements Instrument.
+func (tr Tracer) ObserveCommand(e *CommandEvent) {
+	if tr.Span == nil {
+		return
+	}
+
+	attrs := map[string]string{
+		"db.system":            "tile38",
+		"db.operation.name":    e.Command,
+		"db.tile38.attempts":   strconv.Itoa(e.Attempts),
+		"db.tile38.errorclass": string(e.Class),
+	}
+
+	if e.Key != "" {
+		attrs["db.collection.name"] = e.Key
+	}
+
+	if e.Elapsed > 0 {
+		attrs["db.tile38.elapsed"] = e.Elapsed.String()
+	}
+
+	tr.Span("tile38 "+e.Command, e.Start, e.Start.Add(e.Duration), attrs, e.Err)
+}
+
+// observe sends a CommandEvent to each configured Instrument.
+func (db *Database) observe(e *CommandEvent) {
+	for _, in := range db.instruments {
+		in.ObserveCommand(e)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"kreklow.us/go/t38c"
+)
+
+// TestInstrument tests command instrumentation.
+func TestInstrument(t *testing.T) {
+	t.Run("Events", testInstrumentEvents)
+	t.Run("Metrics", testInstrumentMetrics)
+	t.Run("Tracer", testInstrumentTracer)
+	t.Run("Classify", testInstrumentClassify)
+}
+
+// Test events passed to an InstrumentFunc.
+func testInstrumentEvents(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []t38c.CommandEvent
+	)
+
+	in := t38c.InstrumentFunc(func(e *t38c.CommandEvent) {
+		mu.Lock()
+		events = append(events, *e)
+		mu.Unlock()
+	})
+
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1, t38c.WithInstrument(in))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	srv.HandleFunc("GET", srv.ReturnOkTrue)
+	srv.HandleFunc("SCAN", srv.ReturnOkFalse)
+	srv.HandleFunc("TEST", srv.ReturnOkTrue)
+	srv.HandleFunc("KEYS", srv.ReturnOkTrue)
+
+	_, err = db.Get("fleet", "truck1")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	_, err = db.Scan("fleet")
+	if err == nil {
+		tFatalNoErr(t, "Scan")
+	}
+
+	// the reply has no result, but the command itself succeeds
+	db.TestIntersects(t38c.Point{Lat: 33.5, Lon: -112.2}, t38c.Hash("9tbq")) //nolint:errcheck // only the event is checked
+	db.Keys("fleet*")                                                        //nolint:errcheck // only the event is checked
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, received %d", len(events))
+	}
+
+	exp := []t38c.CommandEvent{
+		{Command: "GET", Key: "fleet", Attempts: 1, Class: t38c.ClassNone},
+		{Command: "SCAN", Key: "fleet", Attempts: 1, Class: t38c.ClassServer},
+		{Command: "TEST", Key: "", Attempts: 1, Class: t38c.ClassNone},
+		{Command: "KEYS", Key: "", Attempts: 1, Class: t38c.ClassNone},
+	}
+
+	for i, e := range events {
+		if e.Command != exp[i].Command {
+			tErrorStr(t, "Command", exp[i].Command, e.Command)
+		}
+
+		if e.Key != exp[i].Key {
+			tErrorStr(t, "Key", exp[i].Key, e.Key)
+		}
+
+		if e.Attempts != exp[i].Attempts {
+			tErrorVal(t, "Attempts", exp[i].Attempts, e.Attempts)
+		}
+
+		if e.Class != exp[i].Class {
+			tErrorStr(t, "Class", exp[i].Class, e.Class)
+		}
+
+		if e.Duration <= 0 {
+			tErrorVal(t, "Duration", "> 0", e.Duration)
+		}
+	}
+
+	if events[1].Err == nil {
+		tErrorStr(t, "Err", "error", "nil")
+	}
+}
+
+// Test the Metrics adapter.
+func testInstrumentMetrics(t *testing.T) {
+	var counted, latency, server string
+
+	m := t38c.Metrics{
+		Count: func(cmd string, class t38c.ErrorClass) {
+			counted = cmd + " " + string(class)
+		},
+		Latency: func(cmd string, s float64) {
+			if s == 2 {
+				latency = cmd
+			}
+		},
+		ServerLatency: func(cmd string, s float64) {
+			if s == 0.5 {
+				server = cmd
+			}
+		},
+	}
+
+	m.ObserveCommand(&t38c.CommandEvent{
+		Command:  "SCAN",
+		Duration: 2 * time.Second,
+		Elapsed:  500 * time.Millisecond,
+		Class:    t38c.ClassNone,
+	})
+
+	if counted != "SCAN none" {
+		tErrorStr(t, "Count", "SCAN none", counted)
+	}
+
+	if latency != "SCAN" {
+		tErrorStr(t, "Latency", "SCAN", latency)
+	}
+
+	if server != "SCAN" {
+		tErrorStr(t, "ServerLatency", "SCAN", server)
+	}
+}
+
+// Test the Tracer adapter.
+func testInstrumentTracer(t *testing.T) {
+	var (
+		name  string
+		dur   time.Duration
+		attrs map[string]string
+		serr  error
+	)
+
+	tr := t38c.Tracer{
+		Span: func(n string, start, end time.Time, a map[string]string, err error) {
+			name, dur, attrs, serr = n, end.Sub(start), a, err
+		},
+	}
+
+	expErr := errors.New("test")
+
+	tr.ObserveCommand(&t38c.CommandEvent{
+		Command:  "GET",
+		Key:      "fleet",
+		Start:    time.Now(),
+		Duration: time.Second,
+		Elapsed:  time.Millisecond,
+		Attempts: 2,
+		Err:      expErr,
+		Class:    t38c.ClassOther,
+	})
+
+	if name != "tile38 GET" {
+		tErrorStr(t, "Name", "tile38 GET", name)
+	}
+
+	if dur != time.Second {
+		tErrorVal(t, "Duration", time.Second, dur)
+	}
+
+	if !errors.Is(serr, expErr) {
+		tErrorStr(t, "Err", expErr, serr)
+	}
+
+	expAttrs := map[string]string{
+		"db.system":            "tile38",
+		"db.operation.name":    "GET",
+		"db.collection.name":   "fleet",
+		"db.tile38.attempts":   "2",
+		"db.tile38.errorclass": "other",
+		"db.tile38.elapsed":    "1ms",
+	}
+
+	for k, v := range expAttrs {
+		if attrs[k] != v {
+			tErrorStr(t, k, v, attrs[k])
+		}
+	}
+}
+
+// Test error classification.
+func testInstrumentClassify(t *testing.T) {
+	tests := map[t38c.ErrorClass]error{
+		t38c.ClassNone:    nil,
+		t38c.ClassNetwork: io.EOF,
+		t38c.ClassCircuit: t38c.ErrCircuitOpen,
+		t38c.ClassOther:   errors.New("other"),
+	}
+
+	for exp, err := range tests {
+		c := t38c.Classify(err)
+		if c != exp {
+			tErrorStr(t, "Classify", exp, c)
+		}
+	}
+}
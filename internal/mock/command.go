@@ -0,0 +1,166 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tidwall/resp"
+)
+
+// UpdateGoldenEnv is the environment variable which, when set to a
+// non-empty value, causes AssertGolden to rewrite golden files instead
+// of comparing against them.
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// Command is a command received by the Server.
+type Command struct {
+	// Conn is the number of the connection which sent the command,
+	// in the order connections were accepted starting at 1.
+	Conn int
+
+	// Time is the time the command was received.
+	Time time.Time
+
+	// Args is the command name and arguments.
+	Args []string
+}
+
+// String returns the command arguments separated by spaces, quoting any
+// argument which is empty or contains spaces, quotes or control
+// characters.
+func (c Command) String() string {
+	strs := make([]string, len(c.Args))
+
+	for i, a := range c.Args {
+		if a == "" || strings.Contains(a, " ") || strconv.Quote(a) != `"`+a+`"` {
+			a = strconv.Quote(a)
+		}
+
+		strs[i] = a
+	}
+
+	return strings.Join(strs, " ")
+}
+
+// Commands returns a copy of the commands received by the server since
+// it started or ResetCommands was last called, in the order received.
+// Only commands with a registered handler are recorded.
+func (s *Server) Commands() []Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmds := make([]Command, len(s.commands))
+	copy(cmds, s.commands)
+
+	return cmds
+}
+
+// ConnCommands returns the commands received on a single connection.
+func (s *Server) ConnCommands(conn int) []Command {
+	var cmds []Command
+
+	for _, c := range s.Commands() {
+		if c.Conn == conn {
+			cmds = append(cmds, c)
+		}
+	}
+
+	return cmds
+}
+
+// ResetCommands clears the recorded commands.
+func (s *Server) ResetCommands() {
+	s.mu.Lock()
+	s.commands = nil
+	s.mu.Unlock()
+}
+
+// Transcript returns the recorded commands, one per line.
+func (s *Server) Transcript() string {
+	var b strings.Builder
+
+	for _, c := range s.Commands() {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// AssertGolden compares the transcript of recorded commands with the
+// contents of the golden file at path, reporting a test error if they
+// differ. If the UPDATE_GOLDEN environment variable is set, the golden
+// file is written instead.
+func (s *Server) AssertGolden(tb testing.TB, path string) {
+	tb.Helper()
+
+	got := s.Transcript()
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		err := os.MkdirAll(filepath.Dir(path), 0o755) //nolint:mnd // directory permissions
+		if err == nil {
+			err = os.WriteFile(path, []byte(got), 0o644) //nolint:gosec,mnd // golden files are not sensitive
+		}
+
+		if err != nil {
+			tb.Fatalf("writing golden file: %v", err)
+		}
+
+		return
+	}
+
+	exp, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		tb.Fatalf("golden file %s does not exist, set %s=1 to create it", path, UpdateGoldenEnv)
+	} else if err != nil {
+		tb.Fatalf("reading golden file: %v", err)
+	}
+
+	if got != string(exp) {
+		tb.Errorf("commands do not match %s\nexpected:\n%s\nreceived:\n%s", path, exp, got)
+	}
+}
+
+// record appends a command received on a connection to the log.
+func (s *Server) record(conn int, args []resp.Value) {
+	cmd := Command{
+		Conn: conn,
+		Time: time.Now(),
+		Args: make([]string, len(args)),
+	}
+
+	for i, v := range args {
+		cmd.Args[i] = v.String()
+	}
+
+	s.mu.Lock()
+	s.commands = append(s.commands, cmd)
+	s.mu.Unlock()
+}
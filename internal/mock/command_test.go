@@ -0,0 +1,117 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mock_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tidwall/resp"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// TestCommandString tests quoting of command arguments.
+func TestCommandString(t *testing.T) {
+	tests := map[string][]string{
+		`SET fleet truck1 STRING x`:         {"SET", "fleet", "truck1", "STRING", "x"},
+		`SET fleet "truck 1" STRING ""`:     {"SET", "fleet", "truck 1", "STRING", ""},
+		`SET fleet a OBJECT "{\"type\":1}"`: {"SET", "fleet", "a", "OBJECT", `{"type":1}`},
+		`EVAL "return 1\nend" 0`:            {"EVAL", "return 1\nend", "0"},
+	}
+
+	for exp, args := range tests {
+		act := mock.Command{Args: args}.String()
+		if act != exp {
+			t.Errorf("expected %s, received %s", exp, act)
+		}
+	}
+}
+
+// TestCommandLog tests recording commands per connection.
+func TestCommandLog(t *testing.T) {
+	srv := mock.NewServer()
+	defer srv.Close()
+
+	srv.HandleFunc("GET", func(c *resp.Conn, _ []resp.Value) bool {
+		return c.WriteSimpleString("OK") == nil
+	})
+
+	for range 2 {
+		nc, err := net.Dial("tcp", net.JoinHostPort(srv.Addr, srv.Port))
+		if err != nil {
+			t.Fatalf("Dial: %v", err)
+		}
+
+		rd := resp.NewReader(nc)
+
+		for _, cmd := range []string{"*1\r\n$4\r\nPING\r\n", "*3\r\n$3\r\nGET\r\n$1\r\na\r\n$1\r\nb\r\n"} {
+			_, err = nc.Write([]byte(cmd))
+			if err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+
+			_, _, err = rd.ReadValue()
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+		}
+
+		nc.Close()
+	}
+
+	cmds := srv.Commands()
+	if len(cmds) != 2 {
+		t.Fatalf("expected 2 commands, received %d", len(cmds))
+	}
+
+	for i, c := range cmds {
+		if c.Conn != i+1 || c.String() != "GET a b" || c.Time.IsZero() {
+			t.Errorf("command %d: unexpected %+v", i, c)
+		}
+
+		if cc := srv.ConnCommands(i + 1); len(cc) != 1 || cc[0].Conn != i+1 {
+			t.Errorf("connection %d: unexpected %+v", i+1, cc)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "log.golden")
+
+	t.Setenv(mock.UpdateGoldenEnv, "1")
+	srv.AssertGolden(t, path)
+
+	b, err := os.ReadFile(path)
+	if err != nil || string(b) != "GET a b\nGET a b\n" {
+		t.Errorf("golden file: unexpected %q, %v", b, err)
+	}
+
+	t.Setenv(mock.UpdateGoldenEnv, "")
+	srv.AssertGolden(t, path)
+
+	srv.ResetCommands()
+
+	if srv.Transcript() != "" {
+		t.Errorf("expected empty transcript, received %q", srv.Transcript())
+	}
+}
@@ -0,0 +1,1053 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/resp"
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/geo"
+	"kreklow.us/go/t38c/internal/num"
+)
+
+// defaultLimit is the number of results returned by a query without
+// LIMIT.
+const defaultLimit = 100
+
+// Errors returned by the emulator, matching those returned by Tile38.
+var (
+	errKeyNotFound = errors.New("key not found")
+	errIDNotFound  = errors.New(IDNotFound)
+	errIDExists    = errors.New(IDExists)
+	errGeoJSON     = errors.New("invalid geojson")
+)
+
+// Emulator is an in-memory implementation of a subset of the Tile38
+// commands producing JSON output. It supports SET, GET, DEL, PDEL,
+// SCAN, SEARCH, EXPIRE, PERSIST, TTL, KEYS, NEARBY and WITHIN with
+// their common options, optionally prefixed by TIMEOUT seconds, which
+// is accepted but has no effect. AUTH accepts any password.
+type Emulator struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	keys map[string]map[string]*item
+}
+
+// item is an object stored in the emulator.
+type item struct {
+	id      string
+	object  string // GeoJSON or string value
+	isStr   bool
+	bbox    [4]float64 // minlat, minlon, maxlat, maxlon
+	fields  map[string]float64
+	expires time.Time
+	dist    float64 // distance from a NEARBY point
+}
+
+// handler runs a command given at least min arguments, including the
+// command name.
+type handler struct {
+	min int
+	f   func(*Emulator, []string) (string, error)
+}
+
+// handlers are the commands supported by the Emulator.
+var handlers = map[string]handler{ //nolint:gochecknoglobals // constant command table
+	"AUTH":    {2, (*Emulator).auth},
+	"OUTPUT":  {2, (*Emulator).output},
+	"SET":     {5, (*Emulator).set},
+	"GET":     {3, (*Emulator).get},
+	"DEL":     {3, (*Emulator).del},
+	"PDEL":    {3, (*Emulator).pdel},
+	"SCAN":    {2, (*Emulator).scan},
+	"SEARCH":  {2, (*Emulator).search},
+	"EXPIRE":  {4, (*Emulator).expire},
+	"PERSIST": {3, (*Emulator).persist},
+	"TTL":     {3, (*Emulator).ttl},
+	"KEYS":    {2, (*Emulator).keysMatching},
+	"NEARBY":  {5, (*Emulator).nearby},
+	"WITHIN":  {3, (*Emulator).within},
+}
+
+// NewEmulator returns an empty Emulator.
+func NewEmulator() *Emulator {
+	return &Emulator{
+		now:  time.Now,
+		keys: make(map[string]map[string]*item),
+	}
+}
+
+// SetClock replaces the function used to obtain the current time,
+// allowing tests to control expirations.
+func (e *Emulator) SetClock(now func() time.Time) {
+	e.mu.Lock()
+	e.now = now
+	e.mu.Unlock()
+}
+
+// Emulate registers the handlers of a new Emulator on the server and
+// returns the Emulator.
+func (s *Server) Emulate() *Emulator {
+	e := NewEmulator()
+
+	for _, cmd := range []string{
+		"AUTH", "OUTPUT", "SET", "GET", "DEL", "PDEL", "SCAN", "SEARCH",
+		"EXPIRE", "PERSIST", "TTL", "KEYS", "NEARBY", "WITHIN", "TIMEOUT",
+	} {
+		s.HandleFunc(cmd, func(c *resp.Conn, args []resp.Value) bool {
+			strs := make([]string, len(args))
+			for i, v := range args {
+				strs[i] = v.String()
+			}
+
+			err := c.WriteString(e.Do(strs...))
+			if err != nil {
+				s.Err = err
+
+				return false
+			}
+
+			return true
+		})
+	}
+
+	return e
+}
+
+// Do runs a command and returns the JSON response.
+func (e *Emulator) Do(args ...string) string {
+	start := time.Now()
+
+	e.mu.Lock()
+	body, err := e.run(args)
+	e.mu.Unlock()
+
+	var b bytes.Buffer
+
+	if err != nil {
+		b.WriteString(`{"ok":false,"err":`)
+		b.WriteString(jsonString(err.Error()))
+	} else {
+		b.WriteString(`{"ok":true`)
+		b.WriteString(body)
+	}
+
+	b.WriteString(`,"elapsed":`)
+	b.WriteString(jsonString(time.Since(start).String()))
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// run dispatches a command, returning the body of the response.
+func (e *Emulator) run(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New("empty command")
+	}
+
+	cmd := strings.ToUpper(args[0])
+
+	if cmd == "TIMEOUT" {
+		return e.timeout(args)
+	}
+
+	h, ok := handlers[cmd]
+	if !ok {
+		return "", errors.New("unknown command '" + args[0] + "'")
+	}
+
+	if len(args) < h.min {
+		return "", errors.New("wrong number of arguments for '" + strings.ToLower(cmd) + "' command")
+	}
+
+	return h.f(e, args[1:])
+}
+
+// timeout handles TIMEOUT seconds command ..., running the command
+// without a time limit since the emulator never blocks.
+func (e *Emulator) timeout(args []string) (string, error) {
+	if len(args) < 3 { //nolint:mnd // TIMEOUT seconds command
+		return "", errors.New("wrong number of arguments for 'timeout' command")
+	}
+
+	secs, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || secs < 0 {
+		return "", errInvalid(args[1])
+	}
+
+	if strings.EqualFold(args[2], "TIMEOUT") {
+		return "", errInvalid(args[2])
+	}
+
+	return e.run(args[2:])
+}
+
+// auth handles AUTH, accepting any password.
+func (e *Emulator) auth(_ []string) (string, error) {
+	return "", nil
+}
+
+// output handles OUTPUT, which only accepts JSON.
+func (e *Emulator) output(args []string) (string, error) {
+	if strings.ToUpper(args[0]) != "JSON" {
+		return "", errInvalid(args[0])
+	}
+
+	return "", nil
+}
+
+// set handles SET key id [FIELD name value ...] [EX seconds] [NX|XX]
+// (POINT|BOUNDS|HASH|OBJECT|STRING) ...
+func (e *Emulator) set(args []string) (string, error) {
+	key, id := args[0], args[1]
+	it := &item{id: id, fields: make(map[string]float64)}
+
+	var nx, xx bool
+
+	i := 2
+
+options:
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "FIELD":
+			if i+2 >= len(args) {
+				return "", errInvalid(args[i])
+			}
+
+			v, err := strconv.ParseFloat(args[i+2], 64)
+			if err != nil {
+				return "", errInvalid(args[i+2])
+			}
+
+			it.fields[args[i+1]] = v
+			i += 2
+		case "EX":
+			if i+1 >= len(args) {
+				return "", errInvalid(args[i])
+			}
+
+			secs, err := strconv.ParseFloat(args[i+1], 64)
+			if err != nil {
+				return "", errInvalid(args[i+1])
+			}
+
+			it.expires = e.now().Add(time.Duration(secs * float64(time.Second)))
+			i++
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		default:
+			break options
+		}
+	}
+
+	if i >= len(args) {
+		return "", errors.New("wrong number of arguments for 'set' command")
+	}
+
+	err := parseObject(it, args[i:])
+	if err != nil {
+		return "", err
+	}
+
+	old := e.lookup(key, id)
+
+	switch {
+	case nx && old != nil:
+		return "", errIDExists
+	case xx && old == nil:
+		return "", errIDNotFound
+	case old != nil:
+		for k, v := range old.fields {
+			if _, ok := it.fields[k]; !ok {
+				it.fields[k] = v
+			}
+		}
+	}
+
+	if e.keys[key] == nil {
+		e.keys[key] = make(map[string]*item)
+	}
+
+	e.keys[key][id] = it
+
+	return "", nil
+}
+
+// get handles GET key id [WITHFIELDS] [OBJECT].
+func (e *Emulator) get(args []string) (string, error) {
+	it, err := e.find(args[0], args[1])
+	if err != nil {
+		return "", err
+	}
+
+	var withfields bool
+
+	for _, a := range args[2:] {
+		switch strings.ToUpper(a) {
+		case "WITHFIELDS":
+			withfields = true
+		case "OBJECT":
+		default:
+			return "", errInvalid(a)
+		}
+	}
+
+	var b strings.Builder
+
+	b.WriteString(`,"object":`)
+	b.WriteString(it.objectJSON())
+
+	if withfields && len(it.fields) > 0 {
+		names := fieldNames([]*item{it})
+
+		b.WriteString(`,"fields":{`)
+
+		for i, n := range names {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			b.WriteString(jsonString(n))
+			b.WriteByte(':')
+			b.WriteString(num.Format(it.fields[n]))
+		}
+
+		b.WriteByte('}')
+	}
+
+	return b.String(), nil
+}
+
+// del handles DEL key id.
+func (e *Emulator) del(args []string) (string, error) {
+	if e.keys[args[0]] != nil {
+		delete(e.keys[args[0]], args[1])
+	}
+
+	return "", nil
+}
+
+// pdel handles PDEL key pattern.
+func (e *Emulator) pdel(args []string) (string, error) {
+	for id := range e.keys[args[0]] {
+		if ok, _ := path.Match(args[1], id); ok {
+			delete(e.keys[args[0]], id)
+		}
+	}
+
+	return "", nil
+}
+
+// expire handles EXPIRE key id seconds.
+func (e *Emulator) expire(args []string) (string, error) {
+	it, err := e.find(args[0], args[1])
+	if err != nil {
+		return "", err
+	}
+
+	secs, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		return "", errInvalid(args[2])
+	}
+
+	it.expires = e.now().Add(time.Duration(secs * float64(time.Second)))
+
+	return "", nil
+}
+
+// persist handles PERSIST key id.
+func (e *Emulator) persist(args []string) (string, error) {
+	it, err := e.find(args[0], args[1])
+	if err != nil {
+		return "", err
+	}
+
+	it.expires = time.Time{}
+
+	return "", nil
+}
+
+// ttl handles TTL key id.
+func (e *Emulator) ttl(args []string) (string, error) {
+	it, err := e.find(args[0], args[1])
+	if err != nil {
+		return "", err
+	}
+
+	if it.expires.IsZero() {
+		return `,"ttl":-1`, nil
+	}
+
+	return `,"ttl":` + num.Format(it.expires.Sub(e.now()).Seconds()), nil
+}
+
+// keysMatching handles KEYS pattern, listing the keys which hold
+// unexpired objects.
+func (e *Emulator) keysMatching(args []string) (string, error) {
+	var keys []string
+
+	for key := range e.keys {
+		if ok, _ := path.Match(args[0], key); !ok {
+			continue
+		}
+
+		if len(e.items(key, &query{}, nil)) > 0 {
+			keys = append(keys, jsonString(key))
+		}
+	}
+
+	sort.Strings(keys)
+
+	return `,"keys":[` + strings.Join(keys, ",") + `]`, nil
+}
+
+// scan handles SCAN key [options].
+func (e *Emulator) scan(args []string) (string, error) {
+	q, rest, err := parseQuery(args[1:])
+	if err != nil {
+		return "", err
+	}
+
+	if len(rest) > 0 {
+		return "", errInvalid(rest[0])
+	}
+
+	return e.results(q, e.items(args[0], q, nil)), nil
+}
+
+// search handles SEARCH key [options], which only returns string
+// objects.
+func (e *Emulator) search(args []string) (string, error) {
+	q, rest, err := parseQuery(args[1:])
+	if err != nil {
+		return "", err
+	}
+
+	if len(rest) > 0 {
+		return "", errInvalid(rest[0])
+	}
+
+	return e.results(q, e.items(args[0], q, func(it *item) bool { return it.isStr })), nil
+}
+
+// nearby handles NEARBY key [options] POINT lat lon [meters].
+func (e *Emulator) nearby(args []string) (string, error) {
+	q, rest, err := parseQuery(args[1:])
+	if err != nil {
+		return "", err
+	}
+
+	if len(rest) < 3 || strings.ToUpper(rest[0]) != "POINT" { //nolint:mnd // POINT lat lon
+		return "", errors.New("wrong number of arguments for 'nearby' command")
+	}
+
+	nums, err := parseFloats(rest[1:])
+	if err != nil {
+		return "", err
+	}
+
+	meters := math.Inf(1)
+	if len(nums) > 2 { //nolint:mnd // optional radius
+		meters = nums[2]
+	}
+
+	its := e.items(args[0], q, func(it *item) bool {
+		if it.isStr {
+			return false
+		}
+
+		lat, lon := it.center()
+		it.dist = geo.Haversine(t38c.Point{Lat: nums[0], Lon: nums[1]}, t38c.Point{Lat: lat, Lon: lon})
+
+		return it.dist <= meters
+	})
+
+	sort.SliceStable(its, func(i, j int) bool { return its[i].dist < its[j].dist })
+
+	return e.results(q, its), nil
+}
+
+// within handles WITHIN key [options] (BOUNDS|CIRCLE|HASH|GET) ...
+func (e *Emulator) within(args []string) (string, error) {
+	q, rest, err := parseQuery(args[1:])
+	if err != nil {
+		return "", err
+	}
+
+	inside, err := e.parseArea(rest)
+	if err != nil {
+		return "", err
+	}
+
+	return e.results(q, e.items(args[0], q, func(it *item) bool {
+		return !it.isStr && inside(it)
+	})), nil
+}
+
+// parseArea parses the area of a WITHIN command into a function
+// reporting whether an item lies within it.
+func (e *Emulator) parseArea(args []string) (func(*item) bool, error) {
+	if len(args) == 0 {
+		return nil, errors.New("wrong number of arguments for 'within' command")
+	}
+
+	var bbox [4]float64
+
+	switch strings.ToUpper(args[0]) {
+	case "BOUNDS":
+		nums, err := parseFloats(args[1:])
+		if err != nil || len(nums) != 4 {
+			return nil, errors.New("wrong number of arguments for 'within' command")
+		}
+
+		copy(bbox[:], nums)
+	case "HASH":
+		if len(args) != 2 { //nolint:mnd // HASH geohash
+			return nil, errors.New("wrong number of arguments for 'within' command")
+		}
+
+		b, err := geo.DecodeHash(t38c.Hash(args[1]))
+		if err != nil {
+			return nil, errInvalid(args[1])
+		}
+
+		bbox = bboxOf(b)
+	case "TILE":
+		nums, err := parseFloats(args[1:])
+		if err != nil || len(nums) != 3 {
+			return nil, errors.New("wrong number of arguments for 'within' command")
+		}
+
+		bbox = bboxOf(geo.Tile{X: int(nums[0]), Y: int(nums[1]), Z: int(nums[2])}.Bounds())
+	case "QUADKEY":
+		if len(args) != 2 { //nolint:mnd // QUADKEY key
+			return nil, errors.New("wrong number of arguments for 'within' command")
+		}
+
+		tile, err := geo.Quadkey(args[1]).Tile()
+		if err != nil {
+			return nil, errInvalid(args[1])
+		}
+
+		bbox = bboxOf(tile.Bounds())
+	case "GET":
+		if len(args) != 3 { //nolint:mnd // GET key id
+			return nil, errors.New("wrong number of arguments for 'within' command")
+		}
+
+		it, err := e.find(args[1], args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		bbox = it.bbox
+	case "CIRCLE":
+		nums, err := parseFloats(args[1:])
+		if err != nil || len(nums) != 3 {
+			return nil, errors.New("wrong number of arguments for 'within' command")
+		}
+
+		return func(it *item) bool {
+			for _, lat := range []float64{it.bbox[0], it.bbox[2]} {
+				for _, lon := range []float64{it.bbox[1], it.bbox[3]} {
+					if geo.Haversine(t38c.Point{Lat: nums[0], Lon: nums[1]}, t38c.Point{Lat: lat, Lon: lon}) > nums[2] {
+						return false
+					}
+				}
+			}
+
+			return true
+		}, nil
+	default:
+		return nil, errInvalid(args[0])
+	}
+
+	return func(it *item) bool {
+		return it.bbox[0] >= bbox[0] && it.bbox[1] >= bbox[1] &&
+			it.bbox[2] <= bbox[2] && it.bbox[3] <= bbox[3]
+	}, nil
+}
+
+// lookup returns an unexpired item, or nil if not found.
+func (e *Emulator) lookup(key string, id string) *item {
+	it := e.keys[key][id]
+	if it != nil && it.expired(e.now()) {
+		delete(e.keys[key], id)
+
+		return nil
+	}
+
+	return it
+}
+
+// find returns an unexpired item, or an error if not found.
+func (e *Emulator) find(key string, id string) (*item, error) {
+	if len(e.keys[key]) == 0 {
+		return nil, errKeyNotFound
+	}
+
+	it := e.lookup(key, id)
+	if it == nil {
+		return nil, errIDNotFound
+	}
+
+	return it, nil
+}
+
+// items returns the unexpired items of a key matching the query and the
+// optional filter, sorted by id.
+func (e *Emulator) items(key string, q *query, filter func(*item) bool) []*item {
+	var its []*item
+
+	now := e.now()
+
+	for id, it := range e.keys[key] {
+		if it.expired(now) {
+			delete(e.keys[key], id)
+
+			continue
+		}
+
+		if q.match != "" {
+			if ok, _ := path.Match(q.match, id); !ok {
+				continue
+			}
+		}
+
+		if !q.where(it) || (filter != nil && !filter(it)) {
+			continue
+		}
+
+		its = append(its, it)
+	}
+
+	sort.Slice(its, func(i, j int) bool { return its[i].id < its[j].id })
+
+	if q.desc {
+		slices.Reverse(its)
+	}
+
+	return its
+}
+
+// results renders a page of items according to the query output.
+func (e *Emulator) results(q *query, its []*item) string {
+	total := len(its)
+	next := 0
+
+	its = its[min(q.cursor, total):]
+	if len(its) > q.limit {
+		its = its[:q.limit]
+		next = q.cursor + q.limit
+	}
+
+	var b strings.Builder
+
+	switch q.output {
+	case "COUNT":
+	case "IDS":
+		b.WriteString(`,"ids":[`)
+
+		for i, it := range its {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			b.WriteString(jsonString(it.id))
+		}
+
+		b.WriteByte(']')
+	default:
+		var names []string
+		if !q.nofields {
+			names = fieldNames(its)
+		}
+
+		if len(names) > 0 {
+			b.WriteString(`,"fields":[`)
+
+			for i, n := range names {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+
+				b.WriteString(jsonString(n))
+			}
+
+			b.WriteByte(']')
+		}
+
+		b.WriteString(`,"objects":[`)
+
+		for i, it := range its {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			b.WriteString(`{"id":`)
+			b.WriteString(jsonString(it.id))
+			b.WriteString(`,"object":`)
+			b.WriteString(it.objectJSON())
+
+			if len(names) > 0 {
+				b.WriteString(`,"fields":[`)
+
+				for j, n := range names {
+					if j > 0 {
+						b.WriteByte(',')
+					}
+
+					b.WriteString(num.Format(it.fields[n]))
+				}
+
+				b.WriteByte(']')
+			}
+
+			if q.distance {
+				b.WriteString(`,"distance":`)
+				b.WriteString(num.Format(math.Round(it.dist*100) / 100)) //nolint:mnd // centimeters
+			}
+
+			b.WriteByte('}')
+		}
+
+		b.WriteByte(']')
+	}
+
+	count := len(its)
+	if q.output == "COUNT" {
+		count = total
+		next = 0
+	}
+
+	b.WriteString(`,"count":`)
+	b.WriteString(strconv.Itoa(count))
+	b.WriteString(`,"cursor":`)
+	b.WriteString(strconv.Itoa(next))
+
+	return b.String()
+}
+
+// expired reports whether the item has expired at time now.
+func (it *item) expired(now time.Time) bool {
+	return !it.expires.IsZero() && !now.Before(it.expires)
+}
+
+// center returns the center of the item's bounding box.
+func (it *item) center() (float64, float64) {
+	return (it.bbox[0] + it.bbox[2]) / 2, (it.bbox[1] + it.bbox[3]) / 2 //nolint:mnd // midpoint
+}
+
+// objectJSON returns the object as a JSON value.
+func (it *item) objectJSON() string {
+	if it.isStr {
+		return jsonString(it.object)
+	}
+
+	return it.object
+}
+
+// query holds the options of a SCAN, SEARCH, NEARBY or WITHIN command.
+type query struct {
+	cursor   int
+	limit    int
+	match    string
+	desc     bool
+	distance bool
+	nofields bool
+	output   string
+	wheres   []where
+}
+
+// where is a WHERE field min max condition.
+type where struct {
+	field    string
+	min, max float64
+}
+
+// parseQuery parses query options, returning the remaining arguments.
+func parseQuery(args []string) (*query, []string, error) {
+	q := &query{limit: defaultLimit, output: "OBJECTS"}
+
+	for i := 0; i < len(args); i++ {
+		var err error
+
+		switch opt := strings.ToUpper(args[i]); opt {
+		case "CURSOR", "LIMIT", "SPARSE":
+			if i+1 >= len(args) {
+				return nil, nil, errInvalid(args[i])
+			}
+
+			var n int
+
+			n, err = strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				return nil, nil, errInvalid(args[i+1])
+			}
+
+			if opt == "CURSOR" {
+				q.cursor = n
+			} else if opt == "LIMIT" {
+				q.limit = n
+			}
+
+			i++
+		case "MATCH":
+			if i+1 >= len(args) {
+				return nil, nil, errInvalid(args[i])
+			}
+
+			q.match = args[i+1]
+			i++
+		case "WHERE":
+			if i+3 >= len(args) { //nolint:mnd // WHERE field min max
+				return nil, nil, errInvalid(args[i])
+			}
+
+			var w where
+
+			w.field = args[i+1]
+
+			w.min, err = parseBound(args[i+2])
+			if err == nil {
+				w.max, err = parseBound(args[i+3])
+			}
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			q.wheres = append(q.wheres, w)
+			i += 3
+		case "ASC":
+			q.desc = false
+		case "DESC":
+			q.desc = true
+		case "DISTANCE":
+			q.distance = true
+		case "NOFIELDS":
+			q.nofields = true
+		case "COUNT", "IDS", "OBJECTS":
+			q.output = opt
+		default:
+			return q, args[i:], nil
+		}
+	}
+
+	return q, nil, nil
+}
+
+// where reports whether the item satisfies all WHERE conditions.
+func (q *query) where(it *item) bool {
+	for _, w := range q.wheres {
+		v := it.fields[w.field]
+		if v < w.min || v > w.max {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseObject parses the object arguments of a SET command into it.
+func parseObject(it *item, args []string) error {
+	typ := strings.ToUpper(args[0])
+
+	if typ == "STRING" || typ == "OBJECT" || typ == "HASH" {
+		if len(args) != 2 { //nolint:mnd // type and value
+			return errors.New("wrong number of arguments for 'set' command")
+		}
+	}
+
+	switch typ {
+	case "STRING":
+		it.object, it.isStr = args[1], true
+	case "OBJECT":
+		return parseGeoJSON(it, args[1])
+	case "HASH":
+		p, err := geo.HashCenter(t38c.Hash(args[1]))
+		if err != nil {
+			return errInvalid(args[1])
+		}
+
+		it.object = `{"type":"Point","coordinates":[` + num.Format(p.Lon) + `,` + num.Format(p.Lat) + `]}`
+		it.bbox = [4]float64{p.Lat, p.Lon, p.Lat, p.Lon}
+	case "POINT":
+		nums, err := parseFloats(args[1:])
+		if err != nil || len(nums) < 2 || len(nums) > 3 {
+			return errors.New("wrong number of arguments for 'set' command")
+		}
+
+		coords := num.Format(nums[1]) + `,` + num.Format(nums[0])
+		if len(nums) == 3 { //nolint:mnd // lat lon z
+			coords += `,` + num.Format(nums[2])
+		}
+
+		it.object = `{"type":"Point","coordinates":[` + coords + `]}`
+		it.bbox = [4]float64{nums[0], nums[1], nums[0], nums[1]}
+	case "BOUNDS":
+		nums, err := parseFloats(args[1:])
+		if err != nil || len(nums) != 4 {
+			return errors.New("wrong number of arguments for 'set' command")
+		}
+
+		s, w, n, e := num.Format(nums[0]), num.Format(nums[1]), num.Format(nums[2]), num.Format(nums[3])
+		it.object = `{"type":"Polygon","coordinates":[[[` + w + `,` + s + `],[` + e + `,` + s + `],[` +
+			e + `,` + n + `],[` + w + `,` + n + `],[` + w + `,` + s + `]]]}`
+		copy(it.bbox[:], nums)
+	default:
+		return errInvalid(args[0])
+	}
+
+	return nil
+}
+
+// parseGeoJSON stores a GeoJSON object in it and computes its bounding
+// box from all coordinates in the object.
+func parseGeoJSON(it *item, s string) error {
+	var b bytes.Buffer
+
+	err := json.Compact(&b, []byte(s))
+	if err != nil || !gjson.Get(s, "type").Exists() {
+		return errGeoJSON
+	}
+
+	bbox := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	found := false
+
+	var walk func(v gjson.Result, coords bool)
+
+	walk = func(v gjson.Result, coords bool) {
+		arr := v.Array()
+		if coords && v.IsArray() && len(arr) >= 2 && arr[0].Type == gjson.Number {
+			lon, lat := arr[0].Num, arr[1].Num
+			bbox = [4]float64{min(bbox[0], lat), min(bbox[1], lon), max(bbox[2], lat), max(bbox[3], lon)}
+			found = true
+
+			return
+		}
+
+		if !v.IsObject() && !v.IsArray() {
+			return
+		}
+
+		v.ForEach(func(k, x gjson.Result) bool {
+			walk(x, coords || k.Str == "coordinates")
+
+			return true
+		})
+	}
+
+	walk(gjson.Parse(s), false)
+
+	if !found {
+		return errGeoJSON
+	}
+
+	it.object = b.String()
+	it.bbox = bbox
+
+	return nil
+}
+
+// fieldNames returns the sorted names of all fields of the items.
+func fieldNames(its []*item) []string {
+	var names []string
+
+	for _, it := range its {
+		for n := range it.fields {
+			if !slices.Contains(names, n) {
+				names = append(names, n)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// parseFloats parses all arguments as numbers.
+func parseFloats(args []string) ([]float64, error) {
+	nums := make([]float64, len(args))
+
+	for i, a := range args {
+		v, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			return nil, errInvalid(a)
+		}
+
+		nums[i] = v
+	}
+
+	return nums, nil
+}
+
+// parseBound parses a WHERE bound, accepting -inf and +inf.
+func parseBound(s string) (float64, error) {
+	switch strings.ToLower(s) {
+	case "-inf":
+		return math.Inf(-1), nil
+	case "+inf", "inf":
+		return math.Inf(1), nil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errInvalid(s)
+	}
+
+	return v, nil
+}
+
+// bboxOf returns b as a bounding box of minlat, minlon, maxlat and
+// maxlon.
+func bboxOf(b t38c.Bounds) [4]float64 {
+	return [4]float64{b.MinLat, b.MinLon, b.MaxLat, b.MaxLon}
+}
+
+// jsonString returns s as a JSON string.
+func jsonString(s string) string {
+	b, _ := json.Marshal(s) //nolint:errchkjson // strings always marshal
+
+	return string(b)
+}
+
+// errInvalid returns an invalid argument error.
+func errInvalid(arg string) error {
+	return errors.New("invalid argument '" + arg + "'")
+}
@@ -0,0 +1,232 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// do runs a command and returns the parsed response, failing the test
+// if the response is not valid JSON.
+func do(t *testing.T, e *mock.Emulator, args ...string) gjson.Result {
+	t.Helper()
+
+	out := e.Do(args...)
+	if !gjson.Valid(out) {
+		t.Fatalf("%v - invalid JSON: %s", args, out)
+	}
+
+	return gjson.Parse(out)
+}
+
+// expect runs a command and compares the values at the supplied paths.
+func expect(t *testing.T, e *mock.Emulator, args []string, exp map[string]string) {
+	t.Helper()
+
+	r := do(t, e, args...)
+
+	for p, v := range exp {
+		if act := r.Get(p).Raw; act != v {
+			t.Errorf("%v %s - expected: %s | received: %s", args, p, v, act)
+		}
+	}
+}
+
+// TestEmulatorCRUD tests storing and retrieving objects.
+func TestEmulatorCRUD(t *testing.T) {
+	e := mock.NewEmulator()
+
+	cmds := []struct {
+		args []string
+		exp  map[string]string
+	}{
+		{[]string{"AUTH", "secret"}, map[string]string{"ok": "true"}},
+		{[]string{"OUTPUT", "json"}, map[string]string{"ok": "true"}},
+		{[]string{"OUTPUT", "resp"}, map[string]string{"err": `"invalid argument 'resp'"`}},
+		{[]string{"GET", "fleet", "t1"}, map[string]string{"ok": "false", "err": `"key not found"`}},
+		{[]string{"SET", "fleet", "t1", "FIELD", "speed", "10", "POINT", "33", "-112"}, map[string]string{"ok": "true"}},
+		{[]string{"GET", "fleet", "t1"}, map[string]string{"object": `{"type":"Point","coordinates":[-112,33]}`, "fields": ""}},
+		{[]string{"GET", "fleet", "t2"}, map[string]string{"err": `"id not found"`}},
+		{[]string{"SET", "fleet", "t1", "NX", "POINT", "1", "2"}, map[string]string{"err": `"id already exists"`}},
+		{[]string{"SET", "fleet", "t2", "XX", "POINT", "1", "2"}, map[string]string{"err": `"id not found"`}},
+		{[]string{"SET", "fleet", "t1", "XX", "FIELD", "age", "2", "POINT", "34", "-111", "100"}, map[string]string{"ok": "true"}},
+		{[]string{"GET", "fleet", "t1", "WITHFIELDS"}, map[string]string{
+			"object": `{"type":"Point","coordinates":[-111,34,100]}`,
+			"fields": `{"age":2,"speed":10}`,
+		}},
+		{[]string{"GET", "fleet", "t1", "POINT"}, map[string]string{"err": `"invalid argument 'POINT'"`}},
+		{[]string{"SET", "fleet", "b1", "BOUNDS", "1", "2", "3", "4"}, map[string]string{"ok": "true"}},
+		{[]string{"GET", "fleet", "b1"}, map[string]string{"object": `{"type":"Polygon","coordinates":[[[2,1],[4,1],[4,3],[2,3],[2,1]]]}`}},
+		{[]string{"SET", "fleet", "h1", "HASH", "s"}, map[string]string{"ok": "true"}},
+		{[]string{"GET", "fleet", "h1"}, map[string]string{"object": `{"type":"Point","coordinates":[22.5,22.5]}`}},
+		{[]string{"SET", "fleet", "o1", "OBJECT", `{"type": "LineString", "coordinates": [[1,2],[3,4]]}`}, map[string]string{"ok": "true"}},
+		{[]string{"GET", "fleet", "o1"}, map[string]string{"object": `{"type":"LineString","coordinates":[[1,2],[3,4]]}`}},
+		{[]string{"SET", "names", "n1", "STRING", "Tom"}, map[string]string{"ok": "true"}},
+		{[]string{"GET", "names", "n1"}, map[string]string{"object": `"Tom"`}},
+		{[]string{"KEYS", "*"}, map[string]string{"keys": `["fleet","names"]`}},
+		{[]string{"KEYS", "n*"}, map[string]string{"keys": `["names"]`}},
+		{[]string{"DEL", "names", "n1"}, map[string]string{"ok": "true"}},
+		{[]string{"DEL", "names", "n1"}, map[string]string{"ok": "true"}},
+		{[]string{"GET", "names", "n1"}, map[string]string{"err": `"key not found"`}},
+		{[]string{"PDEL", "fleet", "t*"}, map[string]string{"ok": "true"}},
+		{[]string{"GET", "fleet", "t1"}, map[string]string{"err": `"id not found"`}},
+		{[]string{"GET", "fleet", "b1"}, map[string]string{"ok": "true"}},
+		{[]string{"KEYS", "*"}, map[string]string{"keys": `["fleet"]`}},
+		{[]string{"KEYS", "x*"}, map[string]string{"keys": `[]`}},
+	}
+
+	for _, c := range cmds {
+		expect(t, e, c.args, c.exp)
+	}
+}
+
+// TestEmulatorErrors tests invalid commands.
+func TestEmulatorErrors(t *testing.T) {
+	e := mock.NewEmulator()
+
+	cmds := map[string][]string{
+		`"empty command"`:                                   {},
+		`"unknown command 'nope'"`:                          {"nope"},
+		`"wrong number of arguments for 'get' command"`:     {"GET", "fleet"},
+		`"wrong number of arguments for 'set' command"`:     {"SET", "fleet", "t1", "POINT", "1", "FIELD"},
+		`"invalid argument 'x'"`:                            {"SET", "fleet", "t1", "FIELD", "a", "x", "POINT", "1", "2"},
+		`"invalid argument 'FIELD'"`:                        {"SET", "fleet", "t1", "FIELD", "a"},
+		`"invalid argument 'y'"`:                            {"SET", "fleet", "t1", "EX", "y", "POINT", "1", "2"},
+		`"invalid argument 'LINE'"`:                         {"SET", "fleet", "t1", "LINE", "1", "2"},
+		`"invalid geojson"`:                                 {"SET", "fleet", "t1", "OBJECT", `{"type":"Point"}`},
+		`"invalid argument 'a'"`:                            {"SET", "fleet", "t1", "HASH", "a"},
+		`"wrong number of arguments for 'within' command"`:  {"WITHIN", "fleet", "BOUNDS", "1"},
+		`"invalid argument 'POLY'"`:                         {"WITHIN", "fleet", "POLY", "1"},
+		`"invalid argument '024'"`:                          {"WITHIN", "fleet", "QUADKEY", "024"},
+		`"invalid argument ''"`:                             {"WITHIN", "fleet", "QUADKEY", ""},
+		`"invalid argument 'z'"`:                            {"SCAN", "fleet", "LIMIT", "z"},
+		`"invalid argument 'POINT'"`:                        {"SCAN", "fleet", "POINT"},
+		`"invalid argument 'q'"`:                            {"SCAN", "fleet", "WHERE", "a", "q", "1"},
+		`"wrong number of arguments for 'nearby' command"`:  {"NEARBY", "fleet", "BOUNDS", "1", "2"},
+		`"wrong number of arguments for 'timeout' command"`: {"TIMEOUT", "1"},
+		`"invalid argument 'soon'"`:                         {"TIMEOUT", "soon", "SCAN", "fleet"},
+		`"invalid argument 'TIMEOUT'"`:                      {"TIMEOUT", "1", "TIMEOUT", "1", "SCAN", "fleet"},
+	}
+
+	for exp, args := range cmds {
+		expect(t, e, args, map[string]string{"ok": "false", "err": exp})
+	}
+}
+
+// TestEmulatorExpire tests expirations.
+func TestEmulatorExpire(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	e := mock.NewEmulator()
+	e.SetClock(func() time.Time { return now })
+
+	cmds := []struct {
+		args []string
+		exp  map[string]string
+	}{
+		{[]string{"SET", "fleet", "t1", "EX", "10", "POINT", "1", "2"}, map[string]string{"ok": "true"}},
+		{[]string{"SET", "fleet", "t2", "POINT", "1", "2"}, map[string]string{"ok": "true"}},
+		{[]string{"TTL", "fleet", "t1"}, map[string]string{"ttl": "10"}},
+		{[]string{"TTL", "fleet", "t2"}, map[string]string{"ttl": "-1"}},
+		{[]string{"EXPIRE", "fleet", "t2", "2.5"}, map[string]string{"ok": "true"}},
+		{[]string{"TTL", "fleet", "t2"}, map[string]string{"ttl": "2.5"}},
+		{[]string{"PERSIST", "fleet", "t2"}, map[string]string{"ok": "true"}},
+		{[]string{"TTL", "fleet", "t2"}, map[string]string{"ttl": "-1"}},
+		{[]string{"EXPIRE", "fleet", "t3", "1"}, map[string]string{"err": `"id not found"`}},
+		{[]string{"EXPIRE", "fleet", "t2", "x"}, map[string]string{"err": `"invalid argument 'x'"`}},
+		{[]string{"PERSIST", "fleet", "t3"}, map[string]string{"err": `"id not found"`}},
+		{[]string{"TTL", "fleet", "t3"}, map[string]string{"err": `"id not found"`}},
+	}
+
+	for _, c := range cmds {
+		expect(t, e, c.args, c.exp)
+	}
+
+	now = now.Add(10 * time.Second)
+
+	expect(t, e, []string{"GET", "fleet", "t1"}, map[string]string{"err": `"id not found"`})
+	expect(t, e, []string{"SCAN", "fleet", "IDS"}, map[string]string{"ids": `["t2"]`, "count": "1"})
+}
+
+// TestEmulatorQueries tests SCAN, SEARCH, NEARBY and WITHIN.
+func TestEmulatorQueries(t *testing.T) {
+	e := mock.NewEmulator()
+
+	for _, args := range [][]string{
+		{"SET", "fleet", "a", "FIELD", "speed", "10", "POINT", "33", "-112"},
+		{"SET", "fleet", "b", "FIELD", "speed", "20", "POINT", "33.01", "-112"},
+		{"SET", "fleet", "c", "POINT", "34", "-112"},
+		{"SET", "fleet", "d", "STRING", "dog"},
+		{"SET", "zone", "z", "BOUNDS", "32.5", "-112.5", "33.5", "-111.5"},
+	} {
+		expect(t, e, args, map[string]string{"ok": "true"})
+	}
+
+	cmds := []struct {
+		args []string
+		exp  map[string]string
+	}{
+		{[]string{"SCAN", "fleet", "IDS"}, map[string]string{"ids": `["a","b","c","d"]`, "count": "4", "cursor": "0"}},
+		{[]string{"TIMEOUT", "0.5", "SCAN", "fleet", "IDS"}, map[string]string{"ids": `["a","b","c","d"]`, "count": "4"}},
+		{[]string{"SCAN", "fleet", "DESC", "LIMIT", "2", "IDS"}, map[string]string{"ids": `["d","c"]`, "cursor": "2"}},
+		{[]string{"SCAN", "fleet", "CURSOR", "2", "LIMIT", "2", "ASC", "IDS"}, map[string]string{"ids": `["c","d"]`, "cursor": "0"}},
+		{[]string{"SCAN", "fleet", "MATCH", "[ab]", "COUNT"}, map[string]string{"count": "2", "ids": ""}},
+		{[]string{"SCAN", "fleet", "WHERE", "speed", "15", "+inf"}, map[string]string{
+			"fields":  `["speed"]`,
+			"objects": `[{"id":"b","object":{"type":"Point","coordinates":[-112,33.01]},"fields":[20]}]`,
+		}},
+		{[]string{"SCAN", "fleet", "NOFIELDS", "WHERE", "speed", "5", "15", "OBJECTS"}, map[string]string{
+			"fields":  "",
+			"objects": `[{"id":"a","object":{"type":"Point","coordinates":[-112,33]}}]`,
+		}},
+		{[]string{"SEARCH", "fleet"}, map[string]string{"objects": `[{"id":"d","object":"dog"}]`}},
+		{[]string{"SEARCH", "fleet", "POINT"}, map[string]string{"err": `"invalid argument 'POINT'"`}},
+		{[]string{"NEARBY", "fleet", "IDS", "POINT", "33.02", "-112"}, map[string]string{"ids": `["b","a","c"]`}},
+		{[]string{"NEARBY", "fleet", "IDS", "POINT", "33.02", "-112", "5000"}, map[string]string{"ids": `["b","a"]`}},
+		{[]string{"NEARBY", "fleet", "DISTANCE", "NOFIELDS", "LIMIT", "1", "POINT", "33.01", "-112"}, map[string]string{
+			"objects.0.id":       `"b"`,
+			"objects.0.distance": "0",
+			"cursor":             "1",
+		}},
+		{[]string{"WITHIN", "fleet", "IDS", "BOUNDS", "32", "-113", "33.005", "-111"}, map[string]string{"ids": `["a"]`}},
+		{[]string{"WITHIN", "fleet", "IDS", "GET", "zone", "z"}, map[string]string{"ids": `["a","b"]`}},
+		{[]string{"WITHIN", "fleet", "IDS", "GET", "zone", "y"}, map[string]string{"err": `"id not found"`}},
+		{[]string{"WITHIN", "fleet", "IDS", "CIRCLE", "33", "-112", "2000"}, map[string]string{"ids": `["a","b"]`}},
+		{[]string{"WITHIN", "fleet", "IDS", "HASH", "9"}, map[string]string{"ids": `["a","b","c"]`}},
+		{[]string{"WITHIN", "zone", "COUNT", "CIRCLE", "33", "-112", "1000"}, map[string]string{"count": "0"}},
+		{[]string{"WITHIN", "fleet", "IDS", "TILE", "1", "3", "3"}, map[string]string{"ids": `["a","b","c"]`}},
+		{[]string{"WITHIN", "fleet", "IDS", "QUADKEY", "023"}, map[string]string{"ids": `["a","b","c"]`}},
+		{[]string{"WITHIN", "fleet", "IDS", "QUADKEY", "022"}, map[string]string{"ids": `[]`}},
+		{[]string{"WITHIN", "fleet", "TILE", "1", "3"}, map[string]string{"err": `"wrong number of arguments for 'within' command"`}},
+		{[]string{"WITHIN", "fleet", "QUADKEY"}, map[string]string{"err": `"wrong number of arguments for 'within' command"`}},
+		{[]string{"SCAN", "empty", "IDS"}, map[string]string{"ok": "true", "ids": "[]", "count": "0"}},
+	}
+
+	for _, c := range cmds {
+		expect(t, e, c.args, c.exp)
+	}
+}
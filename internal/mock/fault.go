@@ -0,0 +1,155 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mock
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+// Fault is a fault injected into the reply to a command.
+type Fault int
+
+// Faults injected by the Server.
+const (
+	// FaultNone sends the reply unchanged.
+	FaultNone Fault = iota
+
+	// FaultDrop closes the connection without sending the reply.
+	FaultDrop
+
+	// FaultTruncate sends the first half of the reply and then closes
+	// the connection.
+	FaultTruncate
+
+	// FaultMalformed replaces the reply with a string that is not valid
+	// JSON.
+	FaultMalformed
+
+	// FaultFragment sends the reply one byte at a time, pausing between
+	// each write, so the client receives partial RESP frames.
+	FaultFragment
+)
+
+// Fault injection values.
+const (
+	// MalformedJSON is the reply sent by FaultMalformed.
+	MalformedJSON string = `{"ok":true,"object":`
+
+	// fragmentDelay is the pause between writes for FaultFragment.
+	fragmentDelay = time.Millisecond
+)
+
+// Inject queues faults for a command. Each time the command is
+// received, the next queued fault is applied to its reply. Once the
+// queue is exhausted, replies are sent unchanged.
+func (s *Server) Inject(cmd string, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmd = strings.ToUpper(cmd)
+	s.faults[cmd] = append(s.faults[cmd], faults...)
+}
+
+// SetLatency sets a delay added before every reply.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	s.latency = d
+	s.mu.Unlock()
+}
+
+// FailDial causes the OUTPUT handshake to fail on the given connections,
+// numbered in the order they are accepted starting at 1.
+func (s *Server) FailDial(n ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range n {
+		s.failDial[d] = true
+	}
+}
+
+// ResetFaults removes all latency and queued faults.
+func (s *Server) ResetFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = 0
+	clear(s.faults)
+	clear(s.failDial)
+}
+
+// nextFault removes and returns the next fault queued for a command.
+func (s *Server) nextFault(cmd string) (Fault, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := s.faults[cmd]
+	if len(q) == 0 {
+		return FaultNone, s.latency
+	}
+
+	s.faults[cmd] = q[1:]
+
+	return q[0], s.latency
+}
+
+// reply writes a reply to the connection, applying latency and the
+// next fault queued for the command. The malformed reply is sent in
+// place of data for FaultMalformed. It returns false if the connection
+// should be closed.
+func (s *Server) reply(nc net.Conn, cmd string, data []byte, malformed []byte) bool {
+	fault, latency := s.nextFault(cmd)
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	switch fault {
+	case FaultNone:
+	case FaultDrop:
+		return false
+	case FaultTruncate:
+		_, _ = nc.Write(data[:len(data)/2])
+
+		return false
+	case FaultMalformed:
+		data = malformed
+	case FaultFragment:
+		for i := range data {
+			_, err := nc.Write(data[i : i+1])
+			if err != nil {
+				return false
+			}
+
+			time.Sleep(fragmentDelay)
+		}
+
+		return true
+	}
+
+	_, err := nc.Write(data)
+
+	return err == nil
+}
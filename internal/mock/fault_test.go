@@ -0,0 +1,99 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mock_test
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// ping sends a PING over a new connection and returns everything read
+// until the reply is complete or the connection is closed.
+func ping(t *testing.T, srv *mock.Server) string {
+	t.Helper()
+
+	nc, err := net.Dial("tcp", net.JoinHostPort(srv.Addr, srv.Port))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+
+	defer nc.Close()
+
+	_, err = nc.Write([]byte("*1\r\n$4\r\nPING\r\n"))
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var out []byte
+
+	buf := make([]byte, 64)
+
+	for {
+		n, err := nc.Read(buf)
+		out = append(out, buf[:n]...)
+
+		if err == io.EOF || len(out) > 1 && string(out[len(out)-2:]) == "\r\n" {
+			return string(out)
+		}
+
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+	}
+}
+
+// TestFaults tests the replies produced by each fault.
+func TestFaults(t *testing.T) {
+	srv := mock.NewServer()
+	defer srv.Close()
+
+	srv.Inject("ping", mock.FaultNone, mock.FaultDrop, mock.FaultTruncate,
+		mock.FaultMalformed, mock.FaultFragment)
+
+	exp := []string{
+		"+PONG\r\n",
+		"",
+		"+PO",
+		"+" + mock.MalformedJSON + "\r\n",
+		"+PONG\r\n",
+		"+PONG\r\n",
+	}
+
+	for i, e := range exp {
+		out := ping(t, srv)
+		if out != e {
+			t.Errorf("reply %d: expected %q, received %q", i, e, out)
+		}
+	}
+
+	srv.Inject("PING", mock.FaultDrop)
+	srv.ResetFaults()
+
+	out := ping(t, srv)
+	if out != "+PONG\r\n" {
+		t.Errorf("reset: expected %q, received %q", "+PONG\r\n", out)
+	}
+}
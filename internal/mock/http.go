@@ -0,0 +1,136 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mock
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/resp"
+)
+
+// isHTTP reports whether a connection begins with an HTTP request
+// rather than a RESP command. Like Tile38, the Server accepts both on
+// the same port.
+func isHTTP(br *bufio.Reader) bool {
+	b, err := br.Peek(4) //nolint:mnd // length of method prefix
+	if err != nil {
+		return false
+	}
+
+	return string(b) == "GET " || string(b) == "POST"
+}
+
+// handleHTTP reads HTTP requests from a connection, where the request
+// path is a command with arguments separated by "+", and dispatches
+// them to the registered handlers. Replies are sent as JSON response
+// bodies, with RESP errors converted to Ok:false.
+func (s *Server) handleHTTP(nc net.Conn, br *bufio.Reader, dial int) {
+	for {
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+
+		req.Body.Close()
+
+		args, err := pathArgs(req)
+		if err != nil {
+			return
+		}
+
+		if strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+			s.handleWebSocket(nc, br, req, args, dial)
+
+			return
+		}
+
+		var buf bytes.Buffer
+
+		c := &resp.Conn{
+			Reader:     resp.NewReader(strings.NewReader("")),
+			Writer:     resp.NewWriter(&buf),
+			RemoteAddr: nc.RemoteAddr().String(),
+		}
+
+		keep := s.dispatch(c, args, dial)
+
+		data := httpResponse(httpBody(buf.Bytes()))
+		malformed := httpResponse(MalformedJSON)
+
+		if !s.reply(nc, strings.ToUpper(args[0].String()), data, malformed) || !keep {
+			return
+		}
+	}
+}
+
+// pathArgs returns the command and arguments from a request path.
+func pathArgs(req *http.Request) ([]resp.Value, error) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.EscapedPath(), "/"), "+")
+	args := make([]resp.Value, len(parts))
+
+	for i, p := range parts {
+		a, err := url.PathUnescape(p)
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = resp.StringValue(a)
+	}
+
+	return args, nil
+}
+
+// httpBody converts a RESP reply written by a handler to the JSON body
+// returned by Tile38's HTTP interface.
+func httpBody(data []byte) string {
+	v, _, err := resp.NewReader(bytes.NewReader(data)).ReadValue()
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case v.Type() == resp.Error:
+		msg, _ := json.Marshal(v.Error().Error())
+
+		return `{"ok":false,"err":` + string(msg) + `}`
+	case v.String() == "PONG":
+		return `{"ok":true,"ping":"pong"}`
+	default:
+		return v.String()
+	}
+}
+
+// httpResponse returns an HTTP response with a JSON body.
+func httpResponse(body string) []byte {
+	return []byte("HTTP/1.1 200 OK\r\n" +
+		"Content-Type: application/json\r\n" +
+		"Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" +
+		body)
+}
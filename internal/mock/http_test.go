@@ -0,0 +1,78 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mock_test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// TestHTTP tests commands sent to the server over HTTP.
+func TestHTTP(t *testing.T) {
+	srv := mock.NewServer()
+	defer srv.Close()
+
+	srv.Emulate()
+	srv.HandleFunc("FAIL", srv.ReturnErr)
+
+	base := "http://" + net.JoinHostPort(srv.Addr, srv.Port) + "/"
+
+	tests := []struct {
+		path string
+		exp  string
+	}{
+		{"PING", `{"ok":true,"ping":"pong"}`},
+		{"FAIL+x", `{"ok":false,"err":"` + mock.TestServerError + `"}`},
+		{"NOPE", `{"ok":false,"err":"ERR unknown command 'NOPE'"}`},
+		{"SET+fleet+a%2Bb+STRING+x%20y", ``},
+		{"GET+fleet+a%2Bb", `"object":"x y"`},
+	}
+
+	for _, tc := range tests {
+		res, err := http.Get(base + tc.path) //nolint:noctx // test request
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+
+		b, err := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+
+		if !strings.Contains(string(b), tc.exp) {
+			t.Errorf("%s: expected %s, received %s", tc.path, tc.exp, b)
+		}
+	}
+
+	exp := "FAIL x\nSET fleet a+b STRING \"x y\"\nGET fleet a+b\n"
+	if srv.Transcript() != exp {
+		t.Errorf("expected %q, received %q", exp, srv.Transcript())
+	}
+}
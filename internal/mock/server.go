@@ -24,10 +24,13 @@
 package mock
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
 	"net"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/tidwall/resp"
@@ -36,12 +39,22 @@ import (
 // Constant values returned by handlers.
 const (
 	IDNotFound string = "id not found"
+	IDExists   string = "id already exists"
 
 	TestOkFalse     string = "test ok false"
 	TestServerError string = "test server error"
 
 	TestObject string  = `{"id":"test"}`
 	TestTTL    float64 = 5.67
+	TestResult string  = `{"count":2,"ids":["a","b"]}`
+
+	NoScript string = "NOSCRIPT No matching script. Please use EVAL."
+
+	TestEvent string = `{"command":"set","group":"g1","detect":"enter",` +
+		`"key":"fleet","time":"2026-01-02T03:04:05.5Z","id":"truck1",` +
+		`"object":{"type":"Point","coordinates":[-112.2,33.5]}}`
+
+	TestHandshakeFailure string = "test handshake failure"
 )
 
 // Errors returned by handlers.
@@ -49,54 +62,201 @@ var (
 	errServerError = errors.New(TestServerError)
 )
 
+// HandlerFunc handles a command received by the Server. Returning
+// false closes the connection.
+type HandlerFunc func(c *resp.Conn, args []resp.Value) bool
+
 // Server is a RESP-compatible server for testing.
 type Server struct {
-	*resp.Server
-
 	Addr string
 	Port string
 
 	Err error
 
-	DataIn bytes.Buffer
+	mu       sync.Mutex
+	ln       net.Listener
+	handlers map[string]HandlerFunc
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+
+	dials    int
+	latency  time.Duration
+	faults   map[string][]Fault
+	failDial map[int]bool
+	commands []Command
+	live     map[net.Conn]*sync.Mutex
 }
 
-// NewServer returns a new Server listening at Addr:Port.
+// NewServer returns a new Server listening at Addr:Port, where Port is
+// an ephemeral port chosen by the operating system. The listener is
+// bound before NewServer returns, so the server is immediately ready to
+// accept connections. If the listener cannot be created, Err is set.
 func NewServer() *Server {
-	srv := new(Server)
+	srv := &Server{
+		Addr:     "127.0.0.1",
+		handlers: make(map[string]HandlerFunc),
+		conns:    make(map[net.Conn]struct{}),
+		faults:   make(map[string][]Fault),
+		failDial: make(map[int]bool),
+		live:     make(map[net.Conn]*sync.Mutex),
+	}
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(srv.Addr, "0"))
+	if err != nil {
+		srv.Err = err
+
+		return srv
+	}
+
+	_, srv.Port, _ = net.SplitHostPort(ln.Addr().String())
+	srv.ln = ln
+
+	srv.wg.Add(1)
+
+	go srv.serve()
+
+	return srv
+}
+
+// HandleFunc registers the handler for the given command, replacing any
+// existing handler. A nil handler removes the registration.
+func (s *Server) HandleFunc(cmd string, h HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if h == nil {
+		delete(s.handlers, strings.ToUpper(cmd))
+
+		return
+	}
+
+	s.handlers[strings.ToUpper(cmd)] = h
+}
+
+// Close stops the server and closes all open connections.
+func (s *Server) Close() error {
+	if s.ln == nil {
+		return s.Err
+	}
+
+	err := s.ln.Close()
+
+	s.mu.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
 
-	srv.Server = resp.NewServer()
-	srv.Addr = "127.0.0.1"
-	srv.Port = "9876"
+	s.wg.Wait()
 
-	go func(s *Server) {
-		err := s.ListenAndServe(net.JoinHostPort(s.Addr, s.Port))
+	return err
+}
+
+// serve accepts connections until the listener is closed.
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		nc, err := s.ln.Accept()
 		if err != nil {
-			s.Err = err
+			return
 		}
-	}(srv)
 
-	time.Sleep(100 * time.Millisecond) //nolint:mnd // give server time to start
+		s.mu.Lock()
+		s.conns[nc] = struct{}{}
+		s.dials++
+		dial := s.dials
+		s.mu.Unlock()
 
-	return srv
+		s.wg.Add(1)
+
+		go s.handle(nc, dial)
+	}
 }
 
-// ReturnErr is a handler that returns Err:TestServerError.
-func (s *Server) ReturnErr(c *resp.Conn, args []resp.Value) bool {
-	var data []byte
+// handle reads commands from a connection, dispatches them to the
+// registered handlers and sends the replies, applying any configured
+// faults.
+func (s *Server) handle(nc net.Conn, dial int) {
+	defer s.wg.Done()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, nc)
+		s.mu.Unlock()
+
+		nc.Close()
+	}()
+
+	br := bufio.NewReader(nc)
+
+	if isHTTP(br) {
+		s.handleHTTP(nc, br, dial)
+
+		return
+	}
 
-	for k, v := range args {
-		if k == 0 {
-			data = v.Bytes()
+	rd := resp.NewReader(br)
 
+	for {
+		v, _, _, err := rd.ReadMultiBulk()
+		if err != nil {
+			return
+		}
+
+		args := v.Array()
+		if len(args) == 0 {
 			continue
 		}
 
-		data = bytes.Join([][]byte{data, v.Bytes()}, []byte(" "))
+		var buf bytes.Buffer
+
+		c := &resp.Conn{
+			Reader:     rd,
+			Writer:     resp.NewWriter(&buf),
+			RemoteAddr: nc.RemoteAddr().String(),
+		}
+
+		keep := s.dispatch(c, args, dial)
+
+		malformed := []byte("+" + MalformedJSON + "\r\n")
+
+		if !s.reply(nc, strings.ToUpper(args[0].String()), buf.Bytes(), malformed) || !keep {
+			return
+		}
 	}
+}
 
-	s.DataIn.Write(data)
+// dispatch runs the handler registered for a command. PING is answered
+// with PONG unless a handler is registered for it, and unknown commands
+// receive an error.
+func (s *Server) dispatch(c *resp.Conn, args []resp.Value, dial int) bool {
+	cmd := strings.ToUpper(args[0].String())
+
+	s.mu.Lock()
+	h := s.handlers[cmd]
+	fail := cmd == "OUTPUT" && s.failDial[dial]
+	s.mu.Unlock()
+
+	switch {
+	case fail:
+		s.record(dial, args)
+
+		return c.WriteSimpleString(
+			fmt.Sprintf(`{"ok":false,"err":"%s"}`, TestHandshakeFailure)) == nil
+	case h != nil:
+		s.record(dial, args)
+
+		return h(c, args)
+	case cmd == "PING":
+		return c.WriteSimpleString("PONG") == nil
+	default:
+		return c.WriteError(fmt.Errorf("ERR unknown command '%s'", args[0])) == nil
+	}
+}
 
+// ReturnErr is a handler that returns Err:TestServerError.
+func (s *Server) ReturnErr(c *resp.Conn, args []resp.Value) bool {
 	err := c.WriteError(errServerError)
 	if err != nil {
 		s.Err = err
@@ -109,20 +269,6 @@ func (s *Server) ReturnErr(c *resp.Conn, args []resp.Value) bool {
 
 // ReturnOkFalse is a handler that returns Ok:false with Err:TestOkFalse.
 func (s *Server) ReturnOkFalse(c *resp.Conn, args []resp.Value) bool {
-	var data []byte
-
-	for k, v := range args {
-		if k == 0 {
-			data = v.Bytes()
-
-			continue
-		}
-
-		data = bytes.Join([][]byte{data, v.Bytes()}, []byte(" "))
-	}
-
-	s.DataIn.Write(data)
-
 	str := fmt.Sprintf(`{"ok":false,"err":"%s"}`, TestOkFalse)
 
 	err := c.WriteSimpleString(str)
@@ -138,21 +284,37 @@ func (s *Server) ReturnOkFalse(c *resp.Conn, args []resp.Value) bool {
 // ReturnOkNotFound is a handler that returns Ok:false with
 // Err:IdNotFound.
 func (s *Server) ReturnOkNotFound(c *resp.Conn, args []resp.Value) bool {
-	var data []byte
+	str := fmt.Sprintf(`{"ok":false,"err":"%s"}`, IDNotFound)
 
-	for k, v := range args {
-		if k == 0 {
-			data = v.Bytes()
+	err := c.WriteSimpleString(str)
+	if err != nil {
+		s.Err = err
 
-			continue
-		}
+		return false
+	}
+
+	return true
+}
+
+// ReturnOkExists is a handler that returns Ok:false with
+// Err:IDExists.
+func (s *Server) ReturnOkExists(c *resp.Conn, args []resp.Value) bool {
+	str := fmt.Sprintf(`{"ok":false,"err":"%s"}`, IDExists)
 
-		data = bytes.Join([][]byte{data, v.Bytes()}, []byte(" "))
+	err := c.WriteSimpleString(str)
+	if err != nil {
+		s.Err = err
+
+		return false
 	}
 
-	s.DataIn.Write(data)
+	return true
+}
 
-	str := fmt.Sprintf(`{"ok":false,"err":"%s"}`, IDNotFound)
+// ReturnNoScript is a handler that returns Ok:false with
+// Err:NoScript.
+func (s *Server) ReturnNoScript(c *resp.Conn, args []resp.Value) bool {
+	str := fmt.Sprintf(`{"ok":false,"err":"%s"}`, NoScript)
 
 	err := c.WriteSimpleString(str)
 	if err != nil {
@@ -164,23 +326,37 @@ func (s *Server) ReturnOkNotFound(c *resp.Conn, args []resp.Value) bool {
 	return true
 }
 
-// ReturnOkTrue is a handler that returns Ok:true with
-// Object:TestObject and TTL:TestTTL.
-func (s *Server) ReturnOkTrue(c *resp.Conn, args []resp.Value) bool {
-	var data []byte
+// ReturnResult is a handler that returns Ok:true with
+// Result:TestResult.
+func (s *Server) ReturnResult(c *resp.Conn, args []resp.Value) bool {
+	str := fmt.Sprintf(`{"ok":true,"result":%s}`, TestResult)
 
-	for k, v := range args {
-		if k == 0 {
-			data = v.Bytes()
+	err := c.WriteSimpleString(str)
+	if err != nil {
+		s.Err = err
 
-			continue
-		}
+		return false
+	}
+
+	return true
+}
 
-		data = bytes.Join([][]byte{data, v.Bytes()}, []byte(" "))
+// ReturnLive is a handler that returns Ok:true with Live:true, as sent
+// when a live geofence query starts.
+func (s *Server) ReturnLive(c *resp.Conn, _ []resp.Value) bool {
+	err := c.WriteSimpleString(`{"ok":true,"live":true}`)
+	if err != nil {
+		s.Err = err
+
+		return false
 	}
 
-	s.DataIn.Write(data)
+	return true
+}
 
+// ReturnOkTrue is a handler that returns Ok:true with
+// Object:TestObject and TTL:TestTTL.
+func (s *Server) ReturnOkTrue(c *resp.Conn, args []resp.Value) bool {
 	str := fmt.Sprintf(`{"ok":true,"object":%s,"ttl":%v}`, TestObject, TestTTL)
 
 	err := c.WriteSimpleString(str)
@@ -192,3 +368,9 @@ func (s *Server) ReturnOkTrue(c *resp.Conn, args []resp.Value) bool {
 
 	return true
 }
+
+// ReturnDrop is a handler that closes the connection without sending
+// a response.
+func (s *Server) ReturnDrop(_ *resp.Conn, _ []resp.Value) bool {
+	return false
+}
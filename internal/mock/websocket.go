@@ -0,0 +1,265 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mock
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1" //nolint:gosec // required by the WebSocket handshake
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/tidwall/resp"
+)
+
+// WebSocket protocol values from RFC 6455.
+const (
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// Publish sends a message to every open live query WebSocket
+// connection. If fragments is greater than 1, the message is split
+// across that many frames.
+func (s *Server) Publish(msg string, fragments int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for nc, mu := range s.live {
+		mu.Lock()
+		writeFragments(nc, []byte(msg), fragments)
+		mu.Unlock()
+	}
+}
+
+// PingLive sends a ping frame to every open live query WebSocket
+// connection.
+func (s *Server) PingLive(payload string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for nc, mu := range s.live {
+		mu.Lock()
+		_ = writeFrame(nc, true, wsPing, []byte(payload))
+		mu.Unlock()
+	}
+}
+
+// CloseLive sends a close frame to every open live query WebSocket
+// connection.
+func (s *Server) CloseLive() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for nc, mu := range s.live {
+		mu.Lock()
+		_ = writeFrame(nc, true, wsClose, []byte{0x03, 0xe8}) //nolint:mnd // status 1000
+		mu.Unlock()
+	}
+}
+
+// handleWebSocket completes a WebSocket handshake, dispatches the
+// command in the request path and sends the reply as the first message.
+// If the reply starts a live query, the connection stays open to
+// receive messages sent with Publish until either side closes it.
+func (s *Server) handleWebSocket(nc net.Conn, br *bufio.Reader, req *http.Request, args []resp.Value, dial int) {
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		_, _ = nc.Write(httpStatus("400 Bad Request"))
+
+		return
+	}
+
+	h := sha1.Sum([]byte(key + wsGUID)) //nolint:gosec // required by the WebSocket handshake
+
+	_, err := io.WriteString(nc, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: "+base64.StdEncoding.EncodeToString(h[:])+"\r\n\r\n")
+	if err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	c := &resp.Conn{
+		Reader:     resp.NewReader(strings.NewReader("")),
+		Writer:     resp.NewWriter(&buf),
+		RemoteAddr: nc.RemoteAddr().String(),
+	}
+
+	s.dispatch(c, args, dial)
+
+	body := httpBody(buf.Bytes())
+
+	mu := new(sync.Mutex)
+
+	s.mu.Lock()
+	err = writeFrame(nc, true, wsText, []byte(body))
+	s.live[nc] = mu
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.live, nc)
+		s.mu.Unlock()
+	}()
+
+	if err != nil || !strings.Contains(body, `"live":true`) {
+		return
+	}
+
+	for {
+		op, payload, err := readFrame(br)
+		if err != nil {
+			return
+		}
+
+		mu.Lock()
+
+		switch op {
+		case wsPing:
+			err = writeFrame(nc, true, wsPong, payload)
+		case wsClose:
+			_ = writeFrame(nc, true, wsClose, payload)
+			err = io.EOF
+		}
+
+		mu.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeFragments writes a text message split across n frames.
+func writeFragments(nc net.Conn, msg []byte, n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	size := (len(msg) + n - 1) / n
+	op := byte(wsText)
+
+	for i := 0; i < n; i++ {
+		end := min((i+1)*size, len(msg))
+		start := min(i*size, end)
+
+		err := writeFrame(nc, i == n-1, op, msg[start:end])
+		if err != nil {
+			return
+		}
+
+		op = wsContinuation
+	}
+}
+
+// writeFrame writes a single unmasked frame, as sent by a server.
+func writeFrame(w io.Writer, fin bool, op byte, payload []byte) error {
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+
+	frame := []byte{b0}
+
+	switch n := len(payload); {
+	case n < 126: //nolint:mnd // 7-bit length
+		frame = append(frame, byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 126) //nolint:mnd // 16-bit length follows
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 127) //nolint:mnd // 64-bit length follows
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	_, err := w.Write(append(frame, payload...))
+
+	return err
+}
+
+// readFrame reads a single masked frame, as sent by a client.
+func readFrame(br *bufio.Reader) (op byte, payload []byte, err error) {
+	var h [8]byte
+
+	_, err = io.ReadFull(br, h[:2])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	op = h[0] & 0x0f
+	n := uint64(h[1] & 0x7f)
+
+	switch n {
+	case 126: //nolint:mnd // 16-bit length
+		_, err = io.ReadFull(br, h[:2])
+		n = uint64(binary.BigEndian.Uint16(h[:2]))
+	case 127: //nolint:mnd // 64-bit length
+		_, err = io.ReadFull(br, h[:8])
+		n = binary.BigEndian.Uint64(h[:8])
+	}
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var mask [4]byte
+
+	if h[1]&0x80 != 0 {
+		_, err = io.ReadFull(br, mask[:])
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload = make([]byte, n)
+
+	_, err = io.ReadFull(br, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return op, payload, nil
+}
+
+// httpStatus returns an HTTP response with the given status and no
+// body.
+func httpStatus(status string) []byte {
+	return []byte("HTTP/1.1 " + status + "\r\nContent-Length: 0\r\n\r\n")
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithLogger returns an Option which logs connection events and
+// command failures to l. Only the command name and key of a command
+// are logged, never its arguments or the objects it returns.
+func WithLogger(l *slog.Logger) Option {
+	return func(db *Database) {
+		db.log = l
+	}
+}
+
+// WithSlowThreshold returns an Option which logs a warning for any
+// command taking longer than d. It has no effect unless a logger is
+// set with WithLogger.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(db *Database) {
+		db.slow = d
+	}
+}
+
+// logDial logs the result of opening a connection.
+func (db *Database) logDial(addr string, err error) {
+	if db.log == nil {
+		return
+	}
+
+	if err != nil {
+		db.log.Warn("tile38 connection failed",
+			slog.String("addr", addr),
+			slog.Any("error", err),
+		)
+
+		return
+	}
+
+	db.log.Debug("tile38 connection established", slog.String("addr", addr))
+}
+
+// logCommand logs failed and slow commands.
+func (db *Database) logCommand(e *CommandEvent) {
+	if db.log == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("command", e.Command),
+		slog.String("key", e.Key),
+		slog.Duration("duration", e.Duration),
+		slog.Int("attempts", e.Attempts),
+	}
+
+	if e.Err != nil {
+		attrs = append(attrs,
+			slog.String("class", string(e.Class)),
+			slog.Any("error", e.Err),
+		)
+
+		db.log.LogAttrs(context.Background(), slog.LevelError, "tile38 command failed", attrs...)
+
+		return
+	}
+
+	if db.slow > 0 && e.Duration > db.slow {
+		if e.Elapsed > 0 {
+			attrs = append(attrs, slog.Duration("elapsed", e.Elapsed))
+		}
+
+		db.log.LogAttrs(context.Background(), slog.LevelWarn, "tile38 slow command", attrs...)
+	}
+}
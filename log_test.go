@@ -0,0 +1,121 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"kreklow.us/go/t38c"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p) //nolint:wrapcheck // pass through
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// TestLogger tests structured logging.
+func TestLogger(t *testing.T) {
+	buf := new(syncBuffer)
+	log := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1,
+		t38c.WithLogger(log), t38c.WithSlowThreshold(time.Nanosecond))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	srv.HandleFunc("SET", srv.ReturnOkFalse)
+	srv.HandleFunc("GET", srv.ReturnOkTrue)
+
+	err = db.Set("fleet", "truck1", "STRING", "secret-payload")
+	if err == nil {
+		tFatalNoErr(t, "Set")
+	}
+
+	_, err = db.Get("fleet", "truck1")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	out := buf.String()
+
+	exp := []string{
+		`level=DEBUG msg="tile38 connection established" addr=` + net.JoinHostPort(srv.Addr, srv.Port),
+		`level=ERROR msg="tile38 command failed" command=SET key=fleet`,
+		`class=server error="received error: ` + "test ok false",
+		`level=WARN msg="tile38 slow command" command=GET key=fleet`,
+	}
+
+	for _, e := range exp {
+		if !strings.Contains(out, e) {
+			tErrorStr(t, "Log", e, out)
+		}
+	}
+
+	if strings.Contains(out, "secret-payload") {
+		t.Errorf("log contains command arguments: %s", out)
+	}
+}
+
+// TestLoggerDialError tests logging a failed connection.
+func TestLoggerDialError(t *testing.T) {
+	buf := new(syncBuffer)
+	log := slog.New(slog.NewTextHandler(buf, nil))
+
+	srv.HandleFunc("OUTPUT", srv.ReturnOkFalse)
+
+	_, err := t38c.Connect(srv.Addr, srv.Port, 1, t38c.WithLogger(log))
+	if err == nil {
+		tFatalNoErr(t, "Connect")
+	}
+
+	exp := `level=WARN msg="tile38 connection failed" addr=` + net.JoinHostPort(srv.Addr, srv.Port)
+	if !strings.Contains(buf.String(), exp) {
+		tErrorStr(t, "Log", exp, buf.String())
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package migrate copies Tile38 collections between keys or between
+// servers.
+//
+// Objects are read from the source with SCAN cursors and written to the
+// destination in batches with Database.SetMany, keeping their fields
+// and remaining time to live. A copy can be verified afterwards by
+// comparing the number of objects and a hash of their contents on both
+// sides.
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"kreklow.us/go/t38c"
+)
+
+// defaultBatchSize is the number of objects copied per batch when
+// Migrator.BatchSize is not set.
+const defaultBatchSize = 100
+
+// ErrMismatch is returned when verification finds that the destination
+// does not hold the same objects as the source.
+var ErrMismatch = errors.New("verification failed")
+
+// Scanner reads objects from a collection, as implemented by
+// *t38c.Database.
+type Scanner interface {
+	ScanQuery(key string, q *t38c.Query) (*t38c.Response, error)
+}
+
+// Source is the database objects are copied from, as implemented by
+// *t38c.Database.
+type Source interface {
+	Scanner
+	Keys(pattern string) ([]string, error)
+	TTLMany(key string, ids []string) (map[string]time.Duration, error)
+}
+
+// Destination is the database objects are copied to, as implemented by
+// *t38c.Database.
+type Destination interface {
+	Scanner
+	SetMany(key string, items []t38c.SetItem) error
+}
+
+// Progress reports the objects copied so far from a key.
+type Progress struct {
+	Key     string
+	Objects int64
+}
+
+// Result reports the outcome of copying a key.
+type Result struct {
+	// Key is the source key and DstKey the key it was copied to.
+	Key    string
+	DstKey string
+
+	// Objects is the number of objects copied, or which would have been
+	// copied in a dry run.
+	Objects int64
+
+	// Expired is the number of objects which expired before they could
+	// be copied.
+	Expired int64
+
+	// Source and Destination summarize both keys after a verified copy.
+	Source      Summary
+	Destination Summary
+}
+
+// Migrator copies the keys matching a pattern from Src to Dst.
+type Migrator struct {
+	Src Source
+	Dst Destination
+
+	// Pattern selects the keys to copy, such as "fleet" or "tenant:*".
+	Pattern string
+
+	// Rename, if not nil, returns the destination key for a source key.
+	// Keys are copied to the same name if it is nil.
+	Rename func(key string) string
+
+	// DryRun reads the source without writing to Dst, which may be nil.
+	DryRun bool
+
+	// Rate limits the number of objects copied per second if greater
+	// than zero.
+	Rate float64
+
+	// BatchSize is the number of objects read and written per batch,
+	// 100 if not set.
+	BatchSize int
+
+	// Verify compares each key with its copy once it is written.
+	Verify bool
+
+	// Progress, if not nil, is called after each batch.
+	Progress func(Progress)
+}
+
+// Migrate copies the matching keys and returns a Result for each key
+// copied. If an error occurs, the results for the keys completed before
+// it are returned with the error.
+func (m *Migrator) Migrate() (results []Result, err error) {
+	if m.Src == nil || m.Pattern == "" || (m.Dst == nil && !m.DryRun) {
+		return nil, errors.New("migrator requires Src, Dst and Pattern")
+	}
+
+	keys, err := m.Src.Keys(m.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("listing keys: %w", err)
+	}
+
+	lim := newLimiter(m.Rate)
+
+	for _, key := range keys {
+		res, err := m.copyKey(key, lim)
+		if err != nil {
+			return results, fmt.Errorf("copying %s: %w", strconv.Quote(key), err)
+		}
+
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// copyKey copies a single key.
+func (m *Migrator) copyKey(key string, lim *limiter) (res Result, err error) {
+	res.Key = key
+	res.DstKey = key
+
+	if m.Rename != nil {
+		res.DstKey = m.Rename(key)
+	}
+
+	size := m.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	q := t38c.NewQuery().Limit(size).Output(t38c.OutputObjects)
+
+	for cursor := 0; ; {
+		r, err := m.Src.ScanQuery(key, q.Cursor(cursor))
+		if err != nil {
+			return res, err
+		}
+
+		items, err := m.items(key, r)
+		if err != nil {
+			return res, err
+		}
+
+		res.Expired += int64(len(r.Objects) - len(items))
+
+		lim.wait(len(items))
+
+		if !m.DryRun && len(items) > 0 {
+			err = m.Dst.SetMany(res.DstKey, items)
+			if err != nil {
+				return res, err
+			}
+		}
+
+		res.Objects += int64(len(items))
+
+		if m.Progress != nil {
+			m.Progress(Progress{Key: key, Objects: res.Objects})
+		}
+
+		if r.Cursor == 0 {
+			break
+		}
+
+		cursor = int(r.Cursor)
+	}
+
+	if m.Verify && !m.DryRun {
+		err = m.verify(&res, size)
+	}
+
+	return res, err
+}
+
+// items returns the SetItems copying the objects of a SCAN response,
+// leaving out objects which have expired since. Their TTLs are read in
+// a single pipeline.
+func (m *Migrator) items(key string, r *t38c.Response) ([]t38c.SetItem, error) {
+	if len(r.Objects) == 0 {
+		return nil, nil
+	}
+
+	all := make([]t38c.SetItem, len(r.Objects))
+	ids := make([]string, len(r.Objects))
+
+	for i, raw := range r.Objects {
+		all[i] = item(r.FieldNames, gjson.Parse(raw))
+		ids[i] = all[i].ID
+	}
+
+	ttls, err := m.Src.TTLMany(key, ids)
+	if err != nil {
+		return nil, fmt.Errorf("reading ttls: %w", err)
+	}
+
+	items := all[:0]
+
+	for _, it := range all {
+		ttl, ok := ttls[it.ID]
+		if !ok {
+			continue
+		}
+
+		if ttl > 0 {
+			it.Options.Expire = ttl
+		}
+
+		items = append(items, it)
+	}
+
+	return items, nil
+}
+
+// item returns the SetItem copying an element of the objects array of a
+// SCAN response, without its expiration.
+func item(names map[string]int64, obj gjson.Result) t38c.SetItem {
+	it := t38c.SetItem{ID: obj.Get("id").String(), Options: new(t38c.SetOptions)}
+
+	o := obj.Get("object")
+	if o.IsObject() {
+		it.Object = t38c.GeoJSON(o.Raw)
+	} else {
+		it.Object = t38c.String(o.String())
+	}
+
+	for name, v := range fieldValues(names, obj) {
+		it.Options.Fields = append(it.Options.Fields, t38c.Field{Name: name, Value: v})
+	}
+
+	sort.Slice(it.Options.Fields, func(i, j int) bool {
+		return it.Options.Fields[i].Name < it.Options.Fields[j].Name
+	})
+
+	return it
+}
+
+// verify summarizes the source and destination keys and compares them.
+func (m *Migrator) verify(res *Result, size int) (err error) {
+	res.Source, err = Summarize(m.Src, res.Key, size)
+	if err != nil {
+		return err
+	}
+
+	res.Destination, err = Summarize(m.Dst, res.DstKey, size)
+	if err != nil {
+		return err
+	}
+
+	if res.Source != res.Destination {
+		return fmt.Errorf("%w: source has %d objects with hash %.12s, destination has %d with hash %.12s",
+			ErrMismatch, res.Source.Count, res.Source.Hash, res.Destination.Count, res.Destination.Hash)
+	}
+
+	return nil
+}
+
+// Summary is the number of objects in a key and a hash of their ids,
+// objects and non-zero fields, independent of the order they are read
+// in and the order of the fields on the server.
+type Summary struct {
+	Count int64
+	Hash  string
+}
+
+// Summarize scans a key and returns its Summary, reading size objects
+// per SCAN, or 100 if size is not greater than zero.
+func Summarize(db Scanner, key string, size int) (Summary, error) {
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	var (
+		s   Summary
+		sum [sha256.Size / 8]uint64
+	)
+
+	q := t38c.NewQuery().Limit(size).Output(t38c.OutputObjects)
+
+	for cursor := 0; ; {
+		r, err := db.ScanQuery(key, q.Cursor(cursor))
+		if err != nil {
+			return s, err
+		}
+
+		for _, raw := range r.Objects {
+			// adding the hashes in 64-bit lanes is independent of order,
+			// unlike XOR it does not cancel out repeated objects
+			h := objectHash(r.FieldNames, gjson.Parse(raw))
+			for i := range sum {
+				sum[i] += binary.BigEndian.Uint64(h[i*8:])
+			}
+
+			s.Count++
+		}
+
+		if r.Cursor == 0 {
+			break
+		}
+
+		cursor = int(r.Cursor)
+	}
+
+	b := make([]byte, 0, sha256.Size)
+	for _, v := range sum {
+		b = binary.BigEndian.AppendUint64(b, v)
+	}
+
+	s.Hash = hex.EncodeToString(b)
+
+	return s, nil
+}
+
+// objectHash returns the hash of an element of the objects array of a
+// SCAN response.
+func objectHash(names map[string]int64, obj gjson.Result) [sha256.Size]byte {
+	fields := fieldValues(names, obj)
+
+	keys := make([]string, 0, len(fields))
+	for name := range fields {
+		keys = append(keys, name)
+	}
+
+	sort.Strings(keys)
+
+	var b strings.Builder
+
+	b.WriteString(obj.Get("id").String())
+	b.WriteByte(0)
+	b.WriteString(obj.Get("object").Raw)
+
+	for _, name := range keys {
+		b.WriteByte(0)
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.FormatFloat(fields[name], 'g', -1, 64))
+	}
+
+	return sha256.Sum256([]byte(b.String()))
+}
+
+// fieldValues returns the non-zero fields of an element of the objects
+// array of a SCAN response by name.
+func fieldValues(names map[string]int64, obj gjson.Result) map[string]float64 {
+	values := obj.Get("fields").Array()
+	fields := make(map[string]float64, len(names))
+
+	for name, i := range names {
+		if int(i) < len(values) && values[i].Num != 0 {
+			fields[name] = values[i].Num
+		}
+	}
+
+	return fields
+}
+
+// limiter paces a copy to a number of objects per second.
+type limiter struct {
+	rate  float64
+	start time.Time
+	n     int
+}
+
+// newLimiter returns a limiter for rate objects per second, which does
+// nothing if rate is not greater than zero.
+func newLimiter(rate float64) *limiter {
+	return &limiter{rate: rate, start: time.Now()}
+}
+
+// wait blocks until n more objects may be copied without exceeding the
+// rate.
+func (l *limiter) wait(n int) {
+	if l.rate <= 0 {
+		return
+	}
+
+	l.n += n
+	due := l.start.Add(time.Duration(float64(l.n) / l.rate * float64(time.Second)))
+
+	time.Sleep(time.Until(due))
+}
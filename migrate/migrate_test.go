@@ -0,0 +1,295 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package migrate_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/migrate"
+	"kreklow.us/go/t38c/t38ctest"
+)
+
+// clock is the fixed time used by the test servers.
+func clock() time.Time {
+	return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// server returns a test server with a fixed clock, running the supplied
+// commands.
+func server(t *testing.T, cmds ...[]string) *t38ctest.Server {
+	t.Helper()
+
+	srv := t38ctest.NewServer(t)
+	srv.SetClock(clock)
+
+	for _, cmd := range cmds {
+		srv.Do(cmd...)
+	}
+
+	return srv
+}
+
+// source returns a database holding two tenants and an unrelated key.
+func source(t *testing.T) *t38c.Database {
+	t.Helper()
+
+	return server(t,
+		[]string{"SET", "tenant1:fleet", "t1", "FIELD", "speed", "10", "FIELD", "age", "2", "POINT", "33.1", "-112.1"},
+		[]string{"SET", "tenant1:fleet", "t2", "EX", "30", "POINT", "33.2", "-112.2"},
+		[]string{"SET", "tenant1:fleet", "note", "STRING", "hello"},
+		[]string{"SET", "tenant1:zones", "z1", "BOUNDS", "33", "-113", "34", "-112"},
+		[]string{"SET", "tenant2:fleet", "t9", "POINT", "1", "2"},
+	).Connect(t)
+}
+
+// TestMigrate tests copying keys between servers.
+func TestMigrate(t *testing.T) {
+	src := source(t)
+	dst := server(t).Connect(t)
+
+	var progress []migrate.Progress
+
+	m := migrate.Migrator{
+		Src:       src,
+		Dst:       dst,
+		Pattern:   "tenant1:*",
+		Rename:    func(key string) string { return strings.Replace(key, "tenant1:", "tenant3:", 1) },
+		BatchSize: 2,
+		Verify:    true,
+		Progress:  func(p migrate.Progress) { progress = append(progress, p) },
+	}
+
+	results, err := m.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	if len(results) != 2 || results[0].DstKey != "tenant3:fleet" || results[0].Objects != 3 ||
+		results[1].DstKey != "tenant3:zones" || results[1].Objects != 1 {
+		t.Fatalf("Migrate: unexpected results %+v", results)
+	}
+
+	if results[0].Source.Count != 3 || results[0].Source != results[0].Destination {
+		t.Errorf("Migrate: unexpected summaries %+v", results[0])
+	}
+
+	if len(progress) != 3 || progress[1] != (migrate.Progress{Key: "tenant1:fleet", Objects: 3}) {
+		t.Errorf("Migrate: unexpected progress %v", progress)
+	}
+
+	keys, err := dst.Keys("*")
+	if err != nil || strings.Join(keys, ",") != "tenant3:fleet,tenant3:zones" {
+		t.Errorf("Keys: unexpected %v, %v", keys, err)
+	}
+
+	ttl, err := dst.TTL("tenant3:fleet", "t2")
+	if err != nil || ttl != 30 {
+		t.Errorf("TTL: expected 30, received %v, %v", ttl, err)
+	}
+
+	r, err := dst.Get("tenant3:fleet", "t1", "WITHFIELDS")
+	if err != nil || r.FieldValues[r.FieldNames["speed"]] != 10 || r.FieldValues[r.FieldNames["age"]] != 2 {
+		t.Errorf("Get: unexpected %+v, %v", r, err)
+	}
+
+	r, err = dst.Get("tenant3:fleet", "note")
+	if err != nil || r.Object != "hello" {
+		t.Errorf("Get: unexpected %+v, %v", r, err)
+	}
+}
+
+// TestMigrateDryRun tests that a dry run does not write.
+func TestMigrateDryRun(t *testing.T) {
+	m := migrate.Migrator{Src: source(t), Pattern: "tenant*", DryRun: true, Verify: true}
+
+	results, err := m.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	if len(results) != 3 || results[2].Key != "tenant2:fleet" || results[2].Objects != 1 || results[2].Source.Count != 0 {
+		t.Errorf("Migrate: unexpected results %+v", results)
+	}
+}
+
+// TestMigrateVerify tests that objects already in the destination fail
+// verification.
+func TestMigrateVerify(t *testing.T) {
+	src := source(t)
+	dst := server(t, []string{"SET", "tenant2:fleet", "extra", "POINT", "1", "2"}).Connect(t)
+
+	m := migrate.Migrator{Src: src, Dst: dst, Pattern: "tenant2:*", Verify: true}
+
+	results, err := m.Migrate()
+	if !errors.Is(err, migrate.ErrMismatch) {
+		t.Fatalf("Migrate: expected %v, received %v", migrate.ErrMismatch, err)
+	}
+
+	exp := `copying "tenant2:fleet": verification failed: source has 1 objects with hash `
+	if !strings.HasPrefix(err.Error(), exp) || !strings.Contains(err.Error(), "destination has 2 with hash") {
+		t.Errorf("Migrate: unexpected error %v", err)
+	}
+
+	if len(results) != 0 {
+		t.Errorf("Migrate: unexpected results %+v", results)
+	}
+}
+
+// TestSummarize tests that summaries do not depend on field order or
+// zero fields.
+func TestSummarize(t *testing.T) {
+	a := server(t, []string{"SET", "k", "a", "FIELD", "x", "1", "FIELD", "y", "2", "POINT", "1", "2"}).Connect(t)
+	b := server(t, []string{"SET", "k", "a", "FIELD", "z", "0", "FIELD", "y", "2", "FIELD", "x", "1", "POINT", "1", "2"}).Connect(t)
+	c := server(t, []string{"SET", "k", "a", "FIELD", "x", "1", "POINT", "1", "2"}).Connect(t)
+
+	sa, err := migrate.Summarize(a, "k", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sb, _ := migrate.Summarize(b, "k", 0)
+	sc, _ := migrate.Summarize(c, "k", 0)
+
+	if sa != sb || sa.Count != 1 || len(sa.Hash) != 64 {
+		t.Errorf("Summarize: expected %+v, received %+v", sa, sb)
+	}
+
+	if sa == sc {
+		t.Errorf("Summarize: expected different hashes, received %+v", sc)
+	}
+
+	one, _ := migrate.Summarize(objects{`{"id":"b","object":"y"}`}, "k", 0)
+	dup, _ := migrate.Summarize(objects{`{"id":"a","object":"x"}`, `{"id":"b","object":"y"}`, `{"id":"a","object":"x"}`}, "k", 0)
+
+	if one.Hash == dup.Hash {
+		t.Errorf("Summarize: expected a repeated object to change the hash, received %+v", dup)
+	}
+}
+
+// objects is a Scanner returning a fixed set of objects.
+type objects []string
+
+// ScanQuery implements migrate.Scanner.
+func (o objects) ScanQuery(string, *t38c.Query) (*t38c.Response, error) {
+	return &t38c.Response{Ok: true, Objects: o}, nil
+}
+
+// TestMigrateRate tests rate limiting.
+func TestMigrateRate(t *testing.T) {
+	start := time.Now()
+
+	m := migrate.Migrator{Src: source(t), Pattern: "tenant1:fleet", DryRun: true, Rate: 50, BatchSize: 1}
+
+	_, err := m.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := time.Since(start); d < 60*time.Millisecond {
+		t.Errorf("Migrate: expected 3 objects at 50/s to take at least 60ms, took %v", d)
+	}
+}
+
+// expiring wraps a Source, reporting that t1 has expired or that
+// reading TTLs fails with err.
+type expiring struct {
+	migrate.Source
+	err error
+}
+
+// TTLMany implements migrate.Source.
+func (e expiring) TTLMany(key string, ids []string) (map[string]time.Duration, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	ttls, err := e.Source.TTLMany(key, ids)
+	delete(ttls, "t1")
+
+	return ttls, err
+}
+
+// dropping wraps a Source, deleting the scanned objects before their
+// TTLs are read.
+type dropping struct {
+	migrate.Source
+	srv *t38ctest.Server
+}
+
+// ScanQuery implements migrate.Source.
+func (d dropping) ScanQuery(key string, q *t38c.Query) (*t38c.Response, error) {
+	r, err := d.Source.ScanQuery(key, q)
+	d.srv.Do("PDEL", key, "*")
+
+	return r, err
+}
+
+// TestMigrateErrors tests failures reading and writing.
+func TestMigrateErrors(t *testing.T) {
+	src := source(t)
+	dst := server(t).Connect(t)
+
+	m := migrate.Migrator{Src: expiring{src, nil}, Dst: dst, Pattern: "tenant1:fleet"}
+
+	results, err := m.Migrate()
+	if err != nil || results[0].Objects != 2 || results[0].Expired != 1 {
+		t.Errorf("Migrate: unexpected %+v, %v", results, err)
+	}
+
+	srv := server(t, []string{"SET", "fleet", "a", "POINT", "1", "2"}, []string{"SET", "fleet", "b", "POINT", "1", "2"})
+	m = migrate.Migrator{Src: dropping{srv.Connect(t), srv}, Dst: dst, Pattern: "fleet"}
+
+	results, err = m.Migrate()
+	if err != nil || results[0].Objects != 0 || results[0].Expired != 2 {
+		t.Errorf("Migrate: expected dropped key to be skipped, received %+v, %v", results, err)
+	}
+
+	fail := t38c.TransportFunc(func(*t38c.Response, string, ...string) error {
+		return errors.New("connection refused")
+	})
+
+	down, err := t38c.NewDatabase(fail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]migrate.Migrator{
+		"migrator requires Src, Dst and Pattern":           {Src: src, Pattern: "*"},
+		"listing keys: database error: connection refused": {Src: down, Dst: dst, Pattern: "*"},
+		`copying "tenant1:fleet": reading ttls: timeout`: {
+			Src: expiring{src, errors.New("timeout")}, Dst: dst, Pattern: "tenant1:fleet",
+		},
+		`copying "tenant1:fleet": database error: connection refused`: {Src: src, Dst: down, Pattern: "tenant1:fleet"},
+	}
+
+	for exp, m := range tests {
+		_, err := m.Migrate()
+		if err == nil || err.Error() != exp {
+			t.Errorf("expected %q, received %v", exp, err)
+		}
+	}
+}
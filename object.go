@@ -0,0 +1,190 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"math"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"kreklow.us/go/t38c/internal/num"
+)
+
+// Coordinate limits.
+const (
+	geohashChars = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+	maxLat = 90
+	maxLon = 180
+)
+
+// Object is a value which can be stored with SetObject. ObjectArgs
+// validates the value and returns it as command arguments, for example
+// POINT lat lon.
+type Object interface {
+	ObjectArgs() ([]string, error)
+}
+
+// Point is a latitude and longitude.
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// ObjectArgs implements Object.
+func (p Point) ObjectArgs() ([]string, error) {
+	err := checkLatLon(p.Lat, p.Lon)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{"POINT", num.Format(p.Lat), num.Format(p.Lon)}, nil
+}
+
+// PointZ is a latitude and longitude with a Z coordinate, which may
+// hold any value such as an altitude or timestamp.
+type PointZ struct {
+	Lat float64
+	Lon float64
+	Z   float64
+}
+
+// ObjectArgs implements Object.
+func (p PointZ) ObjectArgs() ([]string, error) {
+	err := checkLatLon(p.Lat, p.Lon)
+	if err != nil {
+		return nil, err
+	}
+
+	if math.IsNaN(p.Z) || math.IsInf(p.Z, 0) {
+		return nil, newError(nil, "invalid object: Z must be a finite number")
+	}
+
+	return []string{"POINT", num.Format(p.Lat), num.Format(p.Lon), num.Format(p.Z)}, nil
+}
+
+// Bounds is a rectangle defined by its southwest and northeast
+// corners.
+type Bounds struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// ObjectArgs implements Object.
+func (b Bounds) ObjectArgs() ([]string, error) {
+	err := b.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string{"BOUNDS"}, b.args()...), nil
+}
+
+// check validates the corners of the rectangle.
+func (b Bounds) check() error {
+	err := checkLatLon(b.MinLat, b.MinLon)
+	if err != nil {
+		return err
+	}
+
+	err = checkLatLon(b.MaxLat, b.MaxLon)
+	if err != nil {
+		return err
+	}
+
+	if b.MinLat > b.MaxLat || b.MinLon > b.MaxLon {
+		return newError(nil, "invalid object: bounds minimum is greater than maximum")
+	}
+
+	return nil
+}
+
+// args returns the corners as command arguments.
+func (b Bounds) args() []string {
+	return []string{
+		num.Format(b.MinLat), num.Format(b.MinLon),
+		num.Format(b.MaxLat), num.Format(b.MaxLon),
+	}
+}
+
+// Hash is a geohash.
+type Hash string
+
+// ObjectArgs implements Object.
+func (h Hash) ObjectArgs() ([]string, error) {
+	err := h.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{"HASH", string(h)}, nil
+}
+
+// check validates the length and characters of the geohash.
+func (h Hash) check() error {
+	if len(h) < 1 || len(h) > maxHashPrecision {
+		return newError(nil, "invalid object: geohash must be 1 to 12 characters")
+	}
+
+	if strings.Trim(string(h), geohashChars) != "" {
+		return newErrorf(nil, "invalid object: invalid geohash %q", string(h))
+	}
+
+	return nil
+}
+
+// GeoJSON is a GeoJSON object.
+type GeoJSON string
+
+// ObjectArgs implements Object.
+func (g GeoJSON) ObjectArgs() ([]string, error) {
+	if !gjson.Valid(string(g)) || !gjson.Get(string(g), "type").Exists() {
+		return nil, newError(nil, "invalid object: not a GeoJSON object")
+	}
+
+	return []string{"OBJECT", string(g)}, nil
+}
+
+// String is a string value.
+type String string
+
+// ObjectArgs implements Object.
+func (s String) ObjectArgs() ([]string, error) {
+	return []string{"STRING", string(s)}, nil
+}
+
+// checkLatLon validates a latitude and longitude.
+func checkLatLon(lat float64, lon float64) error {
+	if math.IsNaN(lat) || lat < -maxLat || lat > maxLat {
+		return newErrorf(nil, "invalid object: latitude %v out of range", lat)
+	}
+
+	if math.IsNaN(lon) || lon < -maxLon || lon > maxLon {
+		return newErrorf(nil, "invalid object: longitude %v out of range", lon)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/t38c"
+)
+
+// TestObjectArgs tests rendering valid objects.
+func TestObjectArgs(t *testing.T) {
+	tests := map[string]struct {
+		obj t38c.Object
+		exp string
+	}{
+		"Point":   {t38c.Point{Lat: 33.5, Lon: -112.25}, "POINT 33.5 -112.25"},
+		"PointZ":  {t38c.PointZ{Lat: -90, Lon: 180, Z: 100}, "POINT -90 180 100"},
+		"Bounds":  {t38c.Bounds{MinLat: 33, MinLon: -113, MaxLat: 34, MaxLon: -112}, "BOUNDS 33 -113 34 -112"},
+		"Hash":    {t38c.Hash("9tbq"), "HASH 9tbq"},
+		"GeoJSON": {t38c.GeoJSON(`{"type":"Point","coordinates":[1,2]}`), `OBJECT {"type":"Point","coordinates":[1,2]}`},
+		"String":  {t38c.String("hello"), "STRING hello"},
+	}
+
+	for name, tc := range tests {
+		args, err := tc.obj.ObjectArgs()
+		if err != nil {
+			tErrorStr(t, name, "nil", err)
+
+			continue
+		}
+
+		act := strings.Join(args, " ")
+		if act != tc.exp {
+			tErrorStr(t, name, tc.exp, act)
+		}
+	}
+}
+
+// TestObjectErrors tests validating invalid objects.
+func TestObjectErrors(t *testing.T) {
+	tests := map[string]struct {
+		obj t38c.Object
+		exp string
+	}{
+		"Latitude":    {t38c.Point{Lat: 91}, "latitude 91 out of range"},
+		"Longitude":   {t38c.Point{Lon: -181}, "longitude -181 out of range"},
+		"NaN":         {t38c.Point{Lat: math.NaN()}, "latitude NaN out of range"},
+		"PointZ":      {t38c.PointZ{Lat: 91}, "latitude 91 out of range"},
+		"Z":           {t38c.PointZ{Z: math.Inf(1)}, "Z must be a finite number"},
+		"Bounds Min":  {t38c.Bounds{MinLat: 95}, "latitude 95 out of range"},
+		"Bounds Max":  {t38c.Bounds{MaxLon: 195}, "longitude 195 out of range"},
+		"Bounds":      {t38c.Bounds{MinLat: 2, MaxLat: 1}, "bounds minimum is greater than maximum"},
+		"Hash Empty":  {t38c.Hash(""), "geohash must be 1 to 12 characters"},
+		"Hash Long":   {t38c.Hash("0123456789bcd"), "geohash must be 1 to 12 characters"},
+		"Hash Chars":  {t38c.Hash("9tbqa"), `invalid geohash "9tbqa"`},
+		"GeoJSON":     {t38c.GeoJSON(`{"coordinates":[1,2]}`), "not a GeoJSON object"},
+		"GeoJSON Bad": {t38c.GeoJSON(`{`), "not a GeoJSON object"},
+	}
+
+	for name, tc := range tests {
+		_, err := tc.obj.ObjectArgs()
+		if err == nil {
+			tErrorStr(t, name, "error", "nil")
+
+			continue
+		}
+
+		exp := "invalid object: " + tc.exp
+		if err.Error() != exp {
+			tErrorStr(t, name, exp, err)
+		}
+	}
+}
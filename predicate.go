@@ -0,0 +1,212 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+// AreaIntersects reports whether area a intersects area b without
+// contacting the server, using the same planar geometry as Tile38.
+// Points, Bounds and Polygons are supported. Boundaries are considered
+// part of an area.
+func AreaIntersects(a Area, b Area) (bool, error) {
+	as, err := toShape(a)
+	if err != nil {
+		return false, err
+	}
+
+	bs, err := toShape(b)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case as.ring == nil && bs.ring == nil:
+		return as.point == bs.point, nil
+	case as.ring == nil:
+		return inRing(as.point, bs.ring), nil
+	case bs.ring == nil:
+		return inRing(bs.point, as.ring), nil
+	}
+
+	if ringsCross(as.ring, bs.ring) {
+		return true, nil
+	}
+
+	return inRing(as.ring[0], bs.ring) || inRing(bs.ring[0], as.ring), nil
+}
+
+// AreaWithin reports whether area a is fully contained within area b
+// without contacting the server, using the same planar geometry as
+// Tile38. Points, Bounds and Polygons are supported. Boundaries are
+// considered part of an area.
+func AreaWithin(a Area, b Area) (bool, error) {
+	as, err := toShape(a)
+	if err != nil {
+		return false, err
+	}
+
+	bs, err := toShape(b)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case bs.ring == nil:
+		return as.ring == nil && as.point == bs.point, nil
+	case as.ring == nil:
+		return inRing(as.point, bs.ring), nil
+	}
+
+	for i, pt := range as.ring {
+		next := as.ring[(i+1)%len(as.ring)]
+		mid := Point{(pt.Lat + next.Lat) / 2, (pt.Lon + next.Lon) / 2} //nolint:mnd // midpoint
+
+		if !inRing(pt, bs.ring) || !inRing(mid, bs.ring) {
+			return false, nil
+		}
+	}
+
+	return !ringsCrossProperly(as.ring, bs.ring), nil
+}
+
+// shape is an area reduced to either a single point or a ring of
+// points.
+type shape struct {
+	point Point
+	ring  []Point
+}
+
+// toShape converts a supported Area to a shape.
+func toShape(a Area) (shape, error) {
+	switch v := a.(type) {
+	case Point:
+		err := checkLatLon(v.Lat, v.Lon)
+
+		return shape{point: v}, err
+	case Bounds:
+		err := v.check()
+
+		return shape{ring: []Point{
+			{v.MinLat, v.MinLon},
+			{v.MinLat, v.MaxLon},
+			{v.MaxLat, v.MaxLon},
+			{v.MaxLat, v.MinLon},
+		}}, err
+	case Polygon:
+		err := v.check()
+
+		return shape{ring: v.ring()}, err
+	default:
+		return shape{}, newErrorf(nil, "invalid area: unsupported type %T", a)
+	}
+}
+
+// inRing reports whether p lies inside or on the boundary of ring.
+func inRing(p Point, ring []Point) bool {
+	in := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[j], ring[i]
+
+		if orientation(a, b, p) == 0 && onSegment(a, b, p) {
+			return true
+		}
+
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			in = !in
+		}
+	}
+
+	return in
+}
+
+// ringsCross reports whether any edge of ring a touches any edge of
+// ring b.
+func ringsCross(a []Point, b []Point) bool {
+	for i := range a {
+		for j := range b {
+			if segmentsIntersect(a[i], a[(i+1)%len(a)], b[j], b[(j+1)%len(b)]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ringsCrossProperly reports whether any edge of ring a crosses an edge
+// of ring b at a single point interior to both edges.
+func ringsCrossProperly(a []Point, b []Point) bool {
+	for i := range a {
+		for j := range b {
+			p1, p2 := a[i], a[(i+1)%len(a)]
+			q1, q2 := b[j], b[(j+1)%len(b)]
+
+			o1, o2 := orientation(p1, p2, q1), orientation(p1, p2, q2)
+			o3, o4 := orientation(q1, q2, p1), orientation(q1, q2, p2)
+
+			if o1*o2 < 0 && o3*o4 < 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// segmentsIntersect reports whether segment p1-p2 touches segment
+// q1-q2.
+func segmentsIntersect(p1, p2, q1, q2 Point) bool {
+	o1, o2 := orientation(p1, p2, q1), orientation(p1, p2, q2)
+	o3, o4 := orientation(q1, q2, p1), orientation(q1, q2, p2)
+
+	if o1*o2 < 0 && o3*o4 < 0 {
+		return true
+	}
+
+	return (o1 == 0 && onSegment(p1, p2, q1)) ||
+		(o2 == 0 && onSegment(p1, p2, q2)) ||
+		(o3 == 0 && onSegment(q1, q2, p1)) ||
+		(o4 == 0 && onSegment(q1, q2, p2))
+}
+
+// orientation returns the sign of the turn from a-b to a-c: positive
+// for counterclockwise, negative for clockwise and zero if collinear.
+func orientation(a, b, c Point) int {
+	v := (b.Lon-a.Lon)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lon-a.Lon)
+
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// onSegment reports whether c, known to be collinear with a and b, lies
+// between them.
+func onSegment(a, b, c Point) bool {
+	return min(a.Lat, b.Lat) <= c.Lat && c.Lat <= max(a.Lat, b.Lat) &&
+		min(a.Lon, b.Lon) <= c.Lon && c.Lon <= max(a.Lon, b.Lon)
+}
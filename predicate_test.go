@@ -0,0 +1,109 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"testing"
+
+	"kreklow.us/go/t38c"
+)
+
+// TestAreaPredicates tests the local spatial predicates.
+func TestAreaPredicates(t *testing.T) {
+	notch := t38c.Polygon{
+		{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}, {Lat: 10, Lon: 10},
+		{Lat: 5, Lon: 5}, {Lat: 10, Lon: 0},
+	}
+	inner := t38c.Bounds{MinLat: 2, MinLon: 2, MaxLat: 4, MaxLon: 4}
+	outside := t38c.Bounds{MinLat: 20, MinLon: 20, MaxLat: 30, MaxLon: 30}
+	overlap := t38c.Bounds{MinLat: 5, MinLon: 5, MaxLat: 15, MaxLon: 15}
+	cover := t38c.Bounds{MinLat: -1, MinLon: -1, MaxLat: 11, MaxLon: 11}
+
+	tests := map[string]struct {
+		a, b       t38c.Area
+		intersects bool
+		within     bool
+	}{
+		"Point Equal":     {t38c.Point{Lat: 1, Lon: 1}, t38c.Point{Lat: 1, Lon: 1}, true, true},
+		"Point Differ":    {t38c.Point{Lat: 1, Lon: 1}, t38c.Point{Lat: 1, Lon: 2}, false, false},
+		"Point Inside":    {t38c.Point{Lat: 5, Lon: 5}, testSquare, true, true},
+		"Point Edge":      {t38c.Point{Lat: 0, Lon: 5}, testSquare, true, true},
+		"Point Outside":   {t38c.Point{Lat: 50, Lon: 5}, testSquare, false, false},
+		"Point Notch":     {t38c.Point{Lat: 8, Lon: 5}, notch, false, false},
+		"Area Point":      {testSquare, t38c.Point{Lat: 5, Lon: 5}, true, false},
+		"Bounds Inside":   {inner, testSquare, true, true},
+		"Bounds Outside":  {outside, testSquare, false, false},
+		"Bounds Overlap":  {overlap, testSquare, true, false},
+		"Bounds Cover":    {testSquare, cover, true, true},
+		"Bounds Contain":  {cover, testSquare, true, false},
+		"Same Square":     {testSquare, testSquare, true, true},
+		"Across Notch":    {t38c.Bounds{MinLat: 7, MinLon: 2, MaxLat: 8, MaxLon: 8}, notch, true, false},
+		"Touching Corner": {t38c.Bounds{MinLat: 10, MinLon: 10, MaxLat: 12, MaxLon: 12}, testSquare, true, false},
+	}
+
+	for name, tc := range tests {
+		ok, err := t38c.AreaIntersects(tc.a, tc.b)
+		if err != nil {
+			tErrorStr(t, name, "nil", err)
+		} else if ok != tc.intersects {
+			tErrorVal(t, name+" intersects", tc.intersects, ok)
+		}
+
+		ok, err = t38c.AreaWithin(tc.a, tc.b)
+		if err != nil {
+			tErrorStr(t, name, "nil", err)
+		} else if ok != tc.within {
+			tErrorVal(t, name+" within", tc.within, ok)
+		}
+	}
+}
+
+// TestAreaPredicateErrors tests errors from the local spatial
+// predicates.
+func TestAreaPredicateErrors(t *testing.T) {
+	tests := map[string]struct {
+		a, b t38c.Area
+		exp  string
+	}{
+		"Unsupported A": {t38c.Hash("9t"), testSquare, "invalid area: unsupported type t38c.Hash"},
+		"Unsupported B": {testSquare, t38c.Circle{}, "invalid area: unsupported type t38c.Circle"},
+		"Invalid Point": {t38c.Point{Lat: 91}, testSquare, "invalid object: latitude 91 out of range"},
+		"Invalid Bounds": {
+			t38c.Bounds{MinLat: 1}, testSquare,
+			"invalid object: bounds minimum is greater than maximum",
+		},
+		"Invalid Polygon": {testSquare, t38c.Polygon{}, "invalid object: polygon requires at least 3 points"},
+	}
+
+	for name, tc := range tests {
+		_, err := t38c.AreaIntersects(tc.a, tc.b)
+		if err == nil || err.Error() != tc.exp {
+			tErrorStr(t, name+" intersects", tc.exp, err)
+		}
+
+		_, err = t38c.AreaWithin(tc.a, tc.b)
+		if err == nil || err.Error() != tc.exp {
+			tErrorStr(t, name+" within", tc.exp, err)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"kreklow.us/go/t38c/internal/num"
+)
+
+// Output is the output format of a query.
+type Output string
+
+// Output formats accepted by Query.Output. Use Query.Hashes for the
+// HASHES output format.
+const (
+	OutputCount   Output = "COUNT"
+	OutputIDs     Output = "IDS"
+	OutputObjects Output = "OBJECTS"
+	OutputPoints  Output = "POINTS"
+	OutputBounds  Output = "BOUNDS"
+)
+
+// Limits on query option values.
+const (
+	maxSparse        = 8
+	maxHashPrecision = 12
+)
+
+// Query builds the options of a SCAN, SEARCH, NEARBY, WITHIN or
+// INTERSECTS command. Options may be set in any order and are rendered
+// in the order expected by Tile38. The first invalid option is reported
+// by Args.
+//
+// The zero value is an empty query ready to use.
+type Query struct {
+	cursor   int
+	limit    int
+	match    string
+	order    string
+	sparse   int
+	distance bool
+	wheres   [][]string
+	nofields bool
+	clip     bool
+	output   []string
+	timeout  time.Duration
+	err      error
+}
+
+// NewQuery returns an empty Query.
+func NewQuery() *Query {
+	return new(Query)
+}
+
+// Cursor sets the position to start returning results from.
+func (q *Query) Cursor(start int) *Query {
+	if start < 0 {
+		q.fail("CURSOR must not be negative")
+	}
+
+	q.cursor = start
+
+	return q
+}
+
+// Limit sets the maximum number of results returned.
+func (q *Query) Limit(count int) *Query {
+	if count < 1 {
+		q.fail("LIMIT must be greater than zero")
+	}
+
+	q.limit = count
+
+	return q
+}
+
+// Match limits results to ids matching a glob pattern.
+func (q *Query) Match(pattern string) *Query {
+	if pattern == "" {
+		q.fail("MATCH pattern must not be empty")
+	}
+
+	q.match = pattern
+
+	return q
+}
+
+// Asc returns results in ascending order. Only valid for SCAN and
+// SEARCH.
+func (q *Query) Asc() *Query {
+	q.order = "ASC"
+
+	return q
+}
+
+// Desc returns results in descending order. Only valid for SCAN and
+// SEARCH.
+func (q *Query) Desc() *Query {
+	q.order = "DESC"
+
+	return q
+}
+
+// Sparse limits results to one object per spread subdivision of the
+// search area. Spread must be between 1 and 8. Only valid for WITHIN
+// and INTERSECTS.
+func (q *Query) Sparse(spread int) *Query {
+	if spread < 1 || spread > maxSparse {
+		q.fail("SPARSE must be between 1 and 8")
+	}
+
+	q.sparse = spread
+
+	return q
+}
+
+// Distance includes the distance from the search point in each result.
+// Only valid for NEARBY.
+func (q *Query) Distance() *Query {
+	q.distance = true
+
+	return q
+}
+
+// Where limits results to objects with a field value between min and
+// max, inclusive. Use math.Inf for an open range.
+func (q *Query) Where(field string, minval, maxval float64) *Query {
+	switch {
+	case field == "":
+		q.fail("WHERE field must not be empty")
+	case math.IsNaN(minval) || math.IsNaN(maxval):
+		q.fail("WHERE range must not be NaN")
+	case minval > maxval:
+		q.fail("WHERE " + field + " min is greater than max")
+	}
+
+	q.wheres = append(q.wheres, []string{"WHERE", field, num.Format(minval), num.Format(maxval)})
+
+	return q
+}
+
+// WhereExpr limits results to objects matching a Tile38 expression,
+// such as "speed > 10 && name == 'truck'".
+func (q *Query) WhereExpr(expr string) *Query {
+	if expr == "" {
+		q.fail("WHERE expression must not be empty")
+	}
+
+	q.wheres = append(q.wheres, []string{"WHERE", expr})
+
+	return q
+}
+
+// WhereIn limits results to objects with a field value equal to one of
+// the supplied values.
+func (q *Query) WhereIn(field string, values ...float64) *Query {
+	if field == "" {
+		q.fail("WHEREIN field must not be empty")
+	}
+
+	if len(values) == 0 {
+		q.fail("WHEREIN " + field + " requires at least one value")
+	}
+
+	w := []string{"WHEREIN", field, strconv.Itoa(len(values))}
+	for _, v := range values {
+		w = append(w, num.Format(v))
+	}
+
+	q.wheres = append(q.wheres, w)
+
+	return q
+}
+
+// WhereEval limits results to objects for which a Lua script returns
+// true.
+func (q *Query) WhereEval(script string, args ...string) *Query {
+	if script == "" {
+		q.fail("WHEREEVAL script must not be empty")
+	}
+
+	q.wheres = append(q.wheres, append([]string{"WHEREEVAL", script, strconv.Itoa(len(args))}, args...))
+
+	return q
+}
+
+// WhereEvalSHA limits results to objects for which a previously loaded
+// Lua script returns true.
+func (q *Query) WhereEvalSHA(sha string, args ...string) *Query {
+	if sha == "" {
+		q.fail("WHEREEVALSHA hash must not be empty")
+	}
+
+	q.wheres = append(q.wheres, append([]string{"WHEREEVALSHA", sha, strconv.Itoa(len(args))}, args...))
+
+	return q
+}
+
+// NoFields omits field values from the results.
+func (q *Query) NoFields() *Query {
+	q.nofields = true
+
+	return q
+}
+
+// Clip clips returned objects to the search area. Only valid for
+// INTERSECTS.
+func (q *Query) Clip() *Query {
+	q.clip = true
+
+	return q
+}
+
+// Output sets the output format of the results.
+func (q *Query) Output(o Output) *Query {
+	switch o {
+	case OutputCount, OutputIDs, OutputObjects, OutputPoints, OutputBounds:
+	default:
+		q.fail("unknown output format " + string(o))
+	}
+
+	q.output = []string{string(o)}
+
+	return q
+}
+
+// Hashes returns results as geohashes of the given precision, which
+// must be between 1 and 12.
+func (q *Query) Hashes(precision int) *Query {
+	if precision < 1 || precision > maxHashPrecision {
+		q.fail("HASHES precision must be between 1 and 12")
+	}
+
+	q.output = []string{"HASHES", strconv.Itoa(precision)}
+
+	return q
+}
+
+// Timeout sets a server-side timeout on the query using the Tile38
+// TIMEOUT command. A query exceeding the timeout returns ErrTimeout.
+func (q *Query) Timeout(d time.Duration) *Query {
+	if d <= 0 {
+		q.fail("TIMEOUT must be greater than zero")
+	}
+
+	q.timeout = d
+
+	return q
+}
+
+// Args validates the query for the given command and returns the
+// options as command arguments. A nil Query returns no arguments. Any
+// timeout is not included, since it must precede the command.
+func (q *Query) Args(cmd string) ([]string, error) {
+	if q == nil {
+		return nil, nil
+	}
+
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	err := q.validate(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+
+	if q.cursor > 0 {
+		args = append(args, "CURSOR", strconv.Itoa(q.cursor))
+	}
+
+	if q.limit > 0 {
+		args = append(args, "LIMIT", strconv.Itoa(q.limit))
+	}
+
+	if q.sparse > 0 {
+		args = append(args, "SPARSE", strconv.Itoa(q.sparse))
+	}
+
+	if q.match != "" {
+		args = append(args, "MATCH", q.match)
+	}
+
+	if q.order != "" {
+		args = append(args, q.order)
+	}
+
+	if q.distance {
+		args = append(args, "DISTANCE")
+	}
+
+	for _, w := range q.wheres {
+		args = append(args, w...)
+	}
+
+	if q.nofields {
+		args = append(args, "NOFIELDS")
+	}
+
+	if q.clip {
+		args = append(args, "CLIP")
+	}
+
+	return append(args, q.output...), nil
+}
+
+// validate checks that the options set are accepted by cmd.
+func (q *Query) validate(cmd string) error {
+	spatial := cmd == "NEARBY" || cmd == "WITHIN" || cmd == "INTERSECTS"
+
+	switch {
+	case cmd != "SCAN" && cmd != "SEARCH" && !spatial:
+		return newErrorf(nil, "invalid query: unsupported command %s", cmd)
+	case q.order != "" && spatial:
+		return newErrorf(nil, "invalid query: %s not valid for %s", q.order, cmd)
+	case q.sparse > 0 && cmd != "WITHIN" && cmd != "INTERSECTS":
+		return newErrorf(nil, "invalid query: SPARSE not valid for %s", cmd)
+	case q.distance && cmd != "NEARBY":
+		return newErrorf(nil, "invalid query: DISTANCE not valid for %s", cmd)
+	case q.clip && cmd != "INTERSECTS":
+		return newErrorf(nil, "invalid query: CLIP not valid for %s", cmd)
+	case cmd == "SEARCH" && len(q.output) > 0 && q.output[0] != string(OutputCount) && q.output[0] != string(OutputIDs):
+		return newErrorf(nil, "invalid query: %s not valid for SEARCH", q.output[0])
+	}
+
+	return nil
+}
+
+// fail records the first error encountered while building the query.
+func (q *Query) fail(msg string) {
+	if q.err == nil {
+		q.err = newError(nil, "invalid query: "+msg)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tidwall/resp"
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// TestQueryArgs tests rendering query options.
+func TestQueryArgs(t *testing.T) {
+	tests := map[string]struct {
+		cmd string
+		q   *t38c.Query
+		exp string
+	}{
+		"Nil":   {"SCAN", nil, ""},
+		"Empty": {"SCAN", t38c.NewQuery(), ""},
+		"Scan": {
+			"SCAN",
+			t38c.NewQuery().Output(t38c.OutputIDs).Desc().Where("speed", 10, math.Inf(1)).Match("truck*").Limit(5).Cursor(10),
+			"CURSOR 10 LIMIT 5 MATCH truck* DESC WHERE speed 10 +inf IDS",
+		},
+		"Search": {
+			"SEARCH",
+			t38c.NewQuery().Output(t38c.OutputCount).WhereExpr("age > 2").Asc(),
+			"ASC WHERE age > 2 COUNT",
+		},
+		"Nearby": {
+			"NEARBY",
+			t38c.NewQuery().Hashes(6).Distance().NoFields().WhereIn("type", 1, 2.5),
+			"DISTANCE WHEREIN type 2 1 2.5 NOFIELDS HASHES 6",
+		},
+		"Within": {
+			"WITHIN",
+			t38c.NewQuery().WhereEval("return FIELDS.speed > ARGV[1]", "10").Sparse(2),
+			"SPARSE 2 WHEREEVAL return FIELDS.speed > ARGV[1] 1 10",
+		},
+		"Intersects": {
+			"INTERSECTS",
+			t38c.NewQuery().Clip().WhereEvalSHA("abc").Output(t38c.OutputBounds),
+			"WHEREEVALSHA abc 0 CLIP BOUNDS",
+		},
+	}
+
+	for name, tc := range tests {
+		args, err := tc.q.Args(tc.cmd)
+		if err != nil {
+			tErrorStr(t, name, "nil", err)
+
+			continue
+		}
+
+		act := strings.Join(args, " ")
+		if act != tc.exp {
+			tErrorStr(t, name, tc.exp, act)
+		}
+	}
+}
+
+// TestQueryErrors tests query validation.
+func TestQueryErrors(t *testing.T) {
+	tests := map[string]struct {
+		cmd string
+		q   *t38c.Query
+		exp string
+	}{
+		"Cursor":      {"SCAN", t38c.NewQuery().Cursor(-1), "CURSOR must not be negative"},
+		"Limit":       {"SCAN", t38c.NewQuery().Limit(0), "LIMIT must be greater than zero"},
+		"Match":       {"SCAN", t38c.NewQuery().Match(""), "MATCH pattern must not be empty"},
+		"Where":       {"SCAN", t38c.NewQuery().Where("speed", 10, 5), "WHERE speed min is greater than max"},
+		"Where NaN":   {"SCAN", t38c.NewQuery().Where("speed", math.NaN(), 5), "WHERE range must not be NaN"},
+		"Where Empty": {"SCAN", t38c.NewQuery().Where("", 0, 5), "WHERE field must not be empty"},
+		"Expr":        {"SCAN", t38c.NewQuery().WhereExpr(""), "WHERE expression must not be empty"},
+		"WhereIn":     {"SCAN", t38c.NewQuery().WhereIn("type"), "WHEREIN type requires at least one value"},
+		"Eval":        {"SCAN", t38c.NewQuery().WhereEval(""), "WHEREEVAL script must not be empty"},
+		"EvalSHA":     {"SCAN", t38c.NewQuery().WhereEvalSHA(""), "WHEREEVALSHA hash must not be empty"},
+		"Sparse":      {"WITHIN", t38c.NewQuery().Sparse(9), "SPARSE must be between 1 and 8"},
+		"Hashes":      {"SCAN", t38c.NewQuery().Hashes(0), "HASHES precision must be between 1 and 12"},
+		"Output":      {"SCAN", t38c.NewQuery().Output("BAD"), "unknown output format BAD"},
+		"First":       {"SCAN", t38c.NewQuery().Limit(0).Cursor(-1), "LIMIT must be greater than zero"},
+		"Command":     {"GET", t38c.NewQuery(), "unsupported command GET"},
+		"Order":       {"NEARBY", t38c.NewQuery().Asc(), "ASC not valid for NEARBY"},
+		"No Sparse":   {"SCAN", t38c.NewQuery().Sparse(1), "SPARSE not valid for SCAN"},
+		"No Dist":     {"WITHIN", t38c.NewQuery().Distance(), "DISTANCE not valid for WITHIN"},
+		"No Clip":     {"WITHIN", t38c.NewQuery().Clip(), "CLIP not valid for WITHIN"},
+		"No Objects":  {"SEARCH", t38c.NewQuery().Output(t38c.OutputObjects), "OBJECTS not valid for SEARCH"},
+	}
+
+	for name, tc := range tests {
+		_, err := tc.q.Args(tc.cmd)
+		if err == nil {
+			tErrorStr(t, name, "error", "nil")
+
+			continue
+		}
+
+		exp := "invalid query: " + tc.exp
+		if err.Error() != exp {
+			tErrorStr(t, name, exp, err)
+		}
+	}
+}
+
+// TestQueryCommands tests the Query methods of Database.
+func TestQueryCommands(t *testing.T) {
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	q := t38c.NewQuery().Limit(5)
+
+	tests := map[string]func() (*t38c.Response, error){
+		"SCAN test LIMIT 5":   func() (*t38c.Response, error) { return db.ScanQuery("test", q) },
+		"SEARCH test LIMIT 5": func() (*t38c.Response, error) { return db.SearchQuery("test", q) },
+		"NEARBY test LIMIT 5 POINT 1 2": func() (*t38c.Response, error) {
+			return db.NearbyQuery("test", q, "POINT", "1", "2")
+		},
+		"WITHIN test LIMIT 5 HASH 9t": func() (*t38c.Response, error) {
+			return db.WithinQuery("test", q, "HASH", "9t")
+		},
+		"INTERSECTS test LIMIT 5 TILE 1 2 3": func() (*t38c.Response, error) {
+			return db.IntersectsQuery("test", q, "TILE", "1", "2", "3")
+		},
+		"WITHIN test HASH 9tbq": func() (*t38c.Response, error) {
+			return db.WithinArea("test", nil, t38c.Hash("9tbq"))
+		},
+		"INTERSECTS test LIMIT 5 CIRCLE 33 -112 100": func() (*t38c.Response, error) {
+			return db.IntersectsArea("test", q, t38c.Circle{Center: t38c.Point{Lat: 33, Lon: -112}, Meters: 100})
+		},
+	}
+
+	for exp, f := range tests {
+		srv.HandleFunc(strings.Fields(exp)[0], srv.ReturnOkTrue)
+		srv.ResetCommands()
+
+		r, err := f()
+		if err != nil {
+			tErrorStr(t, exp, "nil", err)
+
+			continue
+		}
+
+		if r.Object != mock.TestObject {
+			tErrorStr(t, exp, mock.TestObject, r.Object)
+		}
+
+		tCommands(t, exp, exp)
+	}
+
+	_, err = db.NearbyQuery("test", q)
+	if err == nil || err.Error() != "invalid arguments" {
+		tErrorStr(t, "No Area", "invalid arguments", err)
+	}
+
+	_, err = db.WithinArea("test", q, nil)
+	if err == nil || err.Error() != "invalid arguments" {
+		tErrorStr(t, "Nil Area", "invalid arguments", err)
+	}
+
+	_, err = db.IntersectsArea("test", q, t38c.Hash("a"))
+	if err == nil || err.Error() != `invalid object: invalid geohash "a"` {
+		tErrorStr(t, "Invalid Area", `invalid object: invalid geohash "a"`, err)
+	}
+
+	_, err = db.ScanQuery("test", t38c.NewQuery().Clip())
+	if err == nil || err.Error() != "invalid query: CLIP not valid for SCAN" {
+		tErrorStr(t, "Invalid", "invalid query: CLIP not valid for SCAN", err)
+	}
+}
+
+// TestQueryOutput tests decoding replies to queries using the POINTS,
+// BOUNDS and HASHES output formats.
+func TestQueryOutput(t *testing.T) {
+	point := `{"id":"truck1","point":{"lat":33.5,"lon":-112.2},"distance":12.5}`
+	bounds := `{"id":"truck1","bounds":{"sw":{"lat":33.5,"lon":-112.2},"ne":{"lat":33.5,"lon":-112.2}}}`
+	hash := `{"id":"truck1","hash":"9tbnwg"}`
+
+	replies := map[string]string{
+		"POINTS": `{"ok":true,"points":[` + point + `],"count":1,"cursor":0,"elapsed":"1µs"}`,
+		"BOUNDS": `{"ok":true,"bounds":[` + bounds + `],"count":1,"cursor":0,"elapsed":"1µs"}`,
+		"HASHES": `{"ok":true,"hashes":[` + hash + `],"count":1,"cursor":0,"elapsed":"1µs"}`,
+	}
+
+	db, err := t38c.NewDatabase(t38c.TransportFunc(func(r *t38c.Response, _ string, args ...string) error {
+		for _, a := range args {
+			if reply, ok := replies[a]; ok {
+				return r.UnmarshalText([]byte(reply))
+			}
+		}
+
+		return errors.New("no output format")
+	}))
+	if err != nil {
+		tFatalErr(t, "NewDatabase", err)
+	}
+
+	r, err := db.NearbyQuery("fleet", t38c.NewQuery().Distance().Output(t38c.OutputPoints), "POINT", "33.5", "-112.2")
+	if err != nil {
+		tFatalErr(t, "NearbyQuery", err)
+	}
+
+	if !reflect.DeepEqual(r.Points, []string{point}) {
+		tErrorStr(t, "Points", point, strings.Join(r.Points, ","))
+	}
+
+	r, err = db.ScanQuery("fleet", t38c.NewQuery().Output(t38c.OutputBounds))
+	if err != nil {
+		tFatalErr(t, "ScanQuery", err)
+	}
+
+	if !reflect.DeepEqual(r.Bounds, []string{bounds}) {
+		tErrorStr(t, "Bounds", bounds, strings.Join(r.Bounds, ","))
+	}
+
+	r, err = db.WithinQuery("fleet", t38c.NewQuery().Hashes(6), "BOUNDS", "33", "-113", "34", "-112")
+	if err != nil {
+		tFatalErr(t, "WithinQuery", err)
+	}
+
+	if !reflect.DeepEqual(r.Hashes, []string{hash}) || r.Count != 1 {
+		tErrorStr(t, "Hashes", hash, strings.Join(r.Hashes, ","))
+	}
+}
+
+// TestQueryTimeout tests queries with a server-side timeout.
+func TestQueryTimeout(t *testing.T) {
+	var cmds []string
+
+	in := t38c.InstrumentFunc(func(e *t38c.CommandEvent) {
+		cmds = append(cmds, e.Command+" "+e.Key+" "+string(e.Class))
+	})
+
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1, t38c.WithInstrument(in))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	q := t38c.NewQuery().Limit(5).Timeout(1500 * time.Millisecond)
+
+	srv.HandleFunc("TIMEOUT", srv.ReturnOkTrue)
+	srv.ResetCommands()
+
+	_, err = db.WithinQuery("fleet", q, "HASH", "9t")
+	if err != nil {
+		tFatalErr(t, "WithinQuery", err)
+	}
+
+	exp := "TIMEOUT 1.5 WITHIN fleet LIMIT 5 HASH 9t"
+	tCommands(t, "WithinQuery", exp)
+
+	srv.HandleFunc("TIMEOUT", func(c *resp.Conn, _ []resp.Value) bool {
+		return c.WriteSimpleString(`{"ok":false,"err":"timeout"}`) == nil
+	})
+
+	_, err = db.ScanQuery("fleet", q)
+	if !errors.Is(err, t38c.ErrTimeout) {
+		tErrorStr(t, "ScanQuery", t38c.ErrTimeout, err)
+	}
+
+	if err == nil || err.Error() != "received error: timeout" {
+		tErrorStr(t, "ScanQuery", "received error: timeout", err)
+	}
+
+	expCmds := []string{"WITHIN fleet none", "SCAN fleet timeout"}
+	if !reflect.DeepEqual(expCmds, cmds) {
+		tErrorVal(t, "Events", expCmds, cmds)
+	}
+}
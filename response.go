@@ -23,22 +23,44 @@
 package t38c
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/tidwall/gjson"
 )
 
-// Response represents a database response.
+// Response decoding errors.
+var (
+	errInvalidJSON  = newError(nil, "error unmarshaling response: not valid JSON")
+	errNotObject    = newError(nil, "error unmarshaling response: not a JSON object")
+	errUnknownValue = newError(nil, "error unmarshaling response: unknown response value")
+	errFieldType    = newError(nil, "error unmarshaling response: unknown field type")
+)
+
+// Response represents a database response. Objects, Points, Bounds and
+// Hashes hold the raw JSON elements returned by queries with the
+// OBJECTS, POINTS, BOUNDS and HASHES output formats. A GET with the
+// POINT, BOUNDS or HASH format stores the value in Object.
+//
+// Geofence notifications set Command, Group, Detect, Key and Time, and
+// Hook for notifications sent to a hook. Roaming notifications hold
+// the raw JSON describing the other object in Nearby or Faraway, and
+// Meta holds the raw JSON of a hook's META values.
 type Response struct {
 	ID          string
 	Object      string
 	IDs         []string
+	Keys        []string
 	Objects     []string
+	Points      []string
+	Bounds      []string
+	Hashes      []string
 	FieldNames  map[string]int64
 	FieldValues []float64
 	Count       int64
 	Cursor      int64
 	TTL         float64
+	Result      string
 	Err         string
 	Elapsed     string
 	Ok          bool
@@ -47,122 +69,316 @@ type Response struct {
 	Command string
 	Group   string
 	Detect  string
+	Hook    string
 	Key     string
 	Time    time.Time
-
-	fields int64
+	Nearby  string
+	Faraway string
+	Meta    string
 }
 
 // UnmarshalText implements the ability to unmarshal a database
-// response.
-func (r *Response) UnmarshalText(b []byte) (err error) {
-	defer func() {
-		// since gjson.ForEach doesn't return errors, catch panics and
-		// return them as errors
-		r := recover()
-		if r != nil {
-			s, ok := r.(string)
-			if ok {
-				err = newErrorf(nil, "error unmarshaling response: %s", s)
-			} else {
-				err = newError(nil, "error unmarshaling response")
-			}
-		}
-	}()
-
+// response. If the response is malformed, r is left unchanged.
+func (r *Response) UnmarshalText(b []byte) error {
 	if !gjson.ValidBytes(b) {
-		return newError(nil, "error unmarshaling response: not valid JSON")
+		return errInvalidJSON
 	}
 
-	gjson.ParseBytes(b).ForEach(r.parse)
+	v := gjson.ParseBytes(b)
+	if !v.IsObject() {
+		return errNotObject
+	}
+
+	t := *r
+
+	var err error
+
+	v.ForEach(func(k, v gjson.Result) bool {
+		err = t.parse(k.Str, v)
+
+		return err == nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	*r = t
 
 	return nil
 }
 
-// parse is an iterator function used in gjson.ForEach to parse the
-// response JSON into the Response fields.
-func (r *Response) parse(k, v gjson.Result) bool { //nolint:cyclop // switch case not collapsable
-	switch k.Str {
+// DecodeResult unmarshals the JSON value of the Result field, returned
+// by scripting commands, into v.
+func (r *Response) DecodeResult(v any) error {
+	if r.Result == "" {
+		return newError(nil, "error decoding result: no result")
+	}
+
+	err := json.Unmarshal([]byte(r.Result), v)
+	if err != nil {
+		return newError(err, "error decoding result")
+	}
+
+	return nil
+}
+
+// TTLDuration returns the TTL of a TTL response as a time.Duration, or
+// NoExpiry if the object does not expire.
+func (r *Response) TTLDuration() time.Duration {
+	if r.TTL < 0 {
+		return NoExpiry
+	}
+
+	return time.Duration(r.TTL * float64(time.Second))
+}
+
+// parse decodes the value of a member of the response JSON into the
+// Response fields. A null value leaves the field unset.
+func (r *Response) parse(k string, v gjson.Result) error { //nolint:cyclop,funlen // switch case not collapsable
+	if v.Type == gjson.Null {
+		if knownKey(k) {
+			return nil
+		}
+
+		return errUnknownValue
+	}
+
+	switch k {
 	case "ok":
-		r.Ok = v.Bool()
+		return parseBool(k, v, &r.Ok)
 	case "id":
-		r.ID = v.Str
+		return parseString(k, v, &r.ID)
 	case "object":
-		if v.Type == gjson.JSON {
+		switch {
+		case v.IsObject():
 			r.Object = v.Raw
-		} else {
+		case v.Type == gjson.String:
 			r.Object = v.Str
+		default:
+			return invalidValue(k)
 		}
 	case "ids":
-		v.ForEach(func(_, x gjson.Result) bool {
-			r.IDs = append(r.IDs, x.Str)
+		return parseStrings(k, v, &r.IDs)
+	case "keys":
+		return parseStrings(k, v, &r.Keys)
+	case "point":
+		if !v.IsObject() {
+			return invalidValue(k)
+		}
 
-			return true
-		})
+		r.Object = v.Raw
+	case "hash":
+		return parseString(k, v, &r.Object)
 	case "objects":
-		v.ForEach(func(_, x gjson.Result) bool {
-			r.Objects = append(r.Objects, x.Raw)
+		return parseRaw(k, v, &r.Objects)
+	case "points":
+		return parseRaw(k, v, &r.Points)
+	case "bounds":
+		if v.IsObject() {
+			r.Object = v.Raw
 
-			return true
-		})
+			return nil
+		}
+
+		return parseRaw(k, v, &r.Bounds)
+	case "hashes":
+		return parseRaw(k, v, &r.Hashes)
 	case "fields":
-		r.parsefields(v)
+		return r.parsefields(v)
 	case "count":
-		r.Count = int64(v.Num)
+		return parseInt(k, v, &r.Count)
 	case "cursor":
-		r.Cursor = int64(v.Num)
+		return parseInt(k, v, &r.Cursor)
 	case "ttl":
+		if v.Type != gjson.Number {
+			return invalidValue(k)
+		}
+
 		r.TTL = v.Num
+	case "result":
+		r.Result = v.Raw
 	case "err":
-		r.Err = v.Str
+		return parseString(k, v, &r.Err)
 	case "elapsed":
-		r.Elapsed = v.Str
+		return parseString(k, v, &r.Elapsed)
 	case "live":
-		r.Live = v.Bool()
+		return parseBool(k, v, &r.Live)
 	case "command":
-		r.Command = v.Str
+		return parseString(k, v, &r.Command)
 	case "group":
-		r.Group = v.Str
+		return parseString(k, v, &r.Group)
 	case "detect":
-		r.Detect = v.Str
+		return parseString(k, v, &r.Detect)
+	case "hook":
+		return parseString(k, v, &r.Hook)
 	case "key":
-		r.Key = v.Str
+		return parseString(k, v, &r.Key)
+	case "nearby":
+		return parseObject(k, v, &r.Nearby)
+	case "faraway":
+		return parseObject(k, v, &r.Faraway)
+	case "meta":
+		return parseObject(k, v, &r.Meta)
 	case "time":
-		r.Time, _ = time.Parse(time.RFC3339Nano, v.Str)
+		t, err := time.Parse(time.RFC3339Nano, v.Str)
+		if err != nil || v.Type != gjson.String {
+			return newErrorf(nil, "error unmarshaling response: invalid time %s", v.Raw)
+		}
+
+		r.Time = t
+	case "ping":
+		// reply to PING, which carries no data beyond ok
 	default:
-		panic("unknown response value")
+		return errUnknownValue
 	}
 
-	return true
+	return nil
 }
 
-// parsefields is an iterator function used in gjson.ForEach to parse
-// the fields array or object into a map.
-func (r *Response) parsefields(v gjson.Result) {
-	r.FieldNames = make(map[string]int64)
+// parsefields parses the fields array of names or object of names and
+// values into a map, replacing any fields already parsed. Values other
+// than numbers, such as the string and JSON fields stored by newer
+// versions of Tile38, are skipped.
+func (r *Response) parsefields(v gjson.Result) (err error) {
+	if !v.IsArray() && !v.IsObject() {
+		return errFieldType
+	}
 
-	if v.IsArray() {
-		v.ForEach(func(_, x gjson.Result) bool {
-			r.FieldNames[x.Str] = r.fields
-			r.fields++
+	names := make(map[string]int64)
 
+	var (
+		values []float64
+		i      int64
+	)
+
+	v.ForEach(func(l, x gjson.Result) bool {
+		switch {
+		case v.IsArray() && x.Type == gjson.String:
+			names[x.Str] = i
+		case v.IsObject() && x.Type == gjson.Number:
+			names[l.Str] = i
+			values = append(values, x.Num)
+		case v.IsObject():
 			return true
-		})
+		default:
+			err = invalidValue("fields")
+
+			return false
+		}
+
+		i++
+
+		return true
+	})
 
-		return
+	if err != nil {
+		return err
 	}
 
-	if v.IsObject() {
-		v.ForEach(func(l, x gjson.Result) bool {
-			r.FieldNames[l.Str] = r.fields
-			r.fields++
-			r.FieldValues = append(r.FieldValues, x.Num)
+	r.FieldNames = names
+	r.FieldValues = values
 
-			return true
-		})
+	return nil
+}
+
+// knownKey reports whether k is a member of a response handled by
+// parse.
+func knownKey(k string) bool {
+	switch k {
+	case "ok", "id", "object", "point", "hash", "ids", "keys", "objects", "points", "bounds", "hashes",
+		"fields", "count", "cursor", "ttl", "result", "err", "elapsed", "live", "command", "group",
+		"detect", "hook", "key", "time", "nearby", "faraway", "meta", "ping":
+		return true
+	default:
+		return false
+	}
+}
+
+// invalidValue returns an error for a member of a response with a value
+// of the wrong type.
+func invalidValue(k string) error {
+	return newErrorf(nil, "error unmarshaling response: invalid value for %q", k)
+}
 
-		return
+// parseBool sets *b to the boolean value of member k.
+func parseBool(k string, v gjson.Result, b *bool) error {
+	if !v.IsBool() {
+		return invalidValue(k)
 	}
 
-	panic("unknown field type")
+	*b = v.Bool()
+
+	return nil
+}
+
+// parseString sets *s to the string value of member k.
+func parseString(k string, v gjson.Result, s *string) error {
+	if v.Type != gjson.String {
+		return invalidValue(k)
+	}
+
+	*s = v.Str
+
+	return nil
+}
+
+// parseObject sets *s to the raw JSON of the object in member k.
+func parseObject(k string, v gjson.Result, s *string) error {
+	if !v.IsObject() {
+		return invalidValue(k)
+	}
+
+	*s = v.Raw
+
+	return nil
+}
+
+// parseInt sets *n to the integer value of member k.
+func parseInt(k string, v gjson.Result, n *int64) error {
+	if v.Type != gjson.Number || float64(v.Int()) != v.Num {
+		return invalidValue(k)
+	}
+
+	*n = v.Int()
+
+	return nil
+}
+
+// parseStrings appends the elements of the array of strings in member k
+// to *s.
+func parseStrings(k string, v gjson.Result, s *[]string) (err error) {
+	if !v.IsArray() {
+		return invalidValue(k)
+	}
+
+	v.ForEach(func(_, x gjson.Result) bool {
+		if x.Type != gjson.String {
+			err = invalidValue(k)
+
+			return false
+		}
+
+		*s = append(*s, x.Str)
+
+		return true
+	})
+
+	return err
+}
+
+// parseRaw appends the raw JSON elements of the array in member k to
+// *s.
+func parseRaw(k string, v gjson.Result, s *[]string) error {
+	if !v.IsArray() {
+		return invalidValue(k)
+	}
+
+	v.ForEach(func(_, x gjson.Result) bool {
+		*s = append(*s, x.Raw)
+
+		return true
+	})
+
+	return nil
 }
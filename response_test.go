@@ -23,7 +23,11 @@
 package t38c_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"kreklow.us/go/t38c"
 )
@@ -33,6 +37,10 @@ func TestResponseErrors(t *testing.T) {
 	t.Run("Invalid JSON", testResponseErrJSON)
 	t.Run("Unknown Value", testResponseErrValue)
 	t.Run("Unknown Type", testResponseErrType)
+	t.Run("Invalid Time", testResponseErrTime)
+	t.Run("Not Object", testResponseErrObject)
+	t.Run("Invalid Types", testResponseErrTypes)
+	t.Run("Unchanged", testResponseErrUnchanged)
 }
 
 func testResponseErrJSON(t *testing.T) {
@@ -56,6 +64,63 @@ func testResponseErrType(t *testing.T) {
 	testResponseErr(t, json, err)
 }
 
+func testResponseErrTime(t *testing.T) {
+	json := []byte(`{"command":"set","time":"2018-08-27 19:07"}`)
+	err := `error unmarshaling response: invalid time "2018-08-27 19:07"`
+
+	testResponseErr(t, json, err)
+}
+
+func testResponseErrObject(t *testing.T) {
+	json := []byte(`["ok",true]`)
+	err := "error unmarshaling response: not a JSON object"
+
+	testResponseErr(t, json, err)
+}
+
+func testResponseErrTypes(t *testing.T) {
+	tests := map[string]string{
+		`{"ok":"true"}`:                     `invalid value for "ok"`,
+		`{"ok":true,"id":1}`:                `invalid value for "id"`,
+		`{"ok":true,"object":1}`:            `invalid value for "object"`,
+		`{"ok":true,"ids":["a",1]}`:         `invalid value for "ids"`,
+		`{"ok":true,"keys":"a"}`:            `invalid value for "keys"`,
+		`{"ok":true,"objects":{}}`:          `invalid value for "objects"`,
+		`{"ok":true,"fields":["a",1]}`:      `invalid value for "fields"`,
+		`{"ok":true,"count":1.5}`:           `invalid value for "count"`,
+		`{"ok":true,"cursor":1e30}`:         `invalid value for "cursor"`,
+		`{"ok":true,"ttl":"60"}`:            `invalid value for "ttl"`,
+		`{"command":"set","time":20180827}`: `invalid time 20180827`,
+		`{"ok":true,"zzz":null}`:            `unknown response value`,
+		`{"ok":true,"elapsed":["1µs"]}`:     `invalid value for "elapsed"`,
+		`{"live":1,"command":"set"}`:        `invalid value for "live"`,
+	}
+
+	for json, e := range tests {
+		testResponseErr(t, []byte(json), "error unmarshaling response: "+e)
+	}
+}
+
+func testResponseErrUnchanged(t *testing.T) {
+	r := new(t38c.Response)
+
+	err := r.UnmarshalText([]byte(`{"ok":true,"id":"a","ids":["b"],"fields":["c"]}`))
+	if err != nil {
+		tFatalErr(t, "UnmarshalText", err)
+	}
+
+	exp := *r
+
+	err = r.UnmarshalText([]byte(`{"ok":false,"id":"x","ids":["y"],"fields":{"z":1},"count":"1"}`))
+	if err == nil {
+		tFatalNoErr(t, "UnmarshalText")
+	}
+
+	if !reflect.DeepEqual(*r, exp) {
+		tErrorStr(t, "Response", fmt.Sprint(exp), fmt.Sprint(*r))
+	}
+}
+
 func testResponseErr(t *testing.T, json []byte, e string) {
 	t.Helper()
 
@@ -67,6 +132,10 @@ func testResponseErr(t *testing.T, json []byte, e string) {
 	} else if e != err.Error() {
 		tErrorStr(t, "UnmarshalText", e, err)
 	}
+
+	if !reflect.DeepEqual(r, new(t38c.Response)) {
+		tErrorStr(t, "UnmarshalText", "empty Response", fmt.Sprint(*r))
+	}
 }
 
 // TestResponseValid tests valid Responses.
@@ -76,7 +145,31 @@ func TestResponseValid(t *testing.T) {
 	t.Run("Multiple Objects", testResponseMultObj)
 	t.Run("Multiple IDs", testResponseMultID)
 	t.Run("Geofence", testResponseFence)
+	t.Run("No Expiry", testResponseNoExpiry)
 	t.Run("Server Error", testResponseSrvErr)
+	t.Run("Null Values", testResponseNull)
+	t.Run("Get Formats", testResponseGetFormats)
+	t.Run("Field Types", testResponseFieldTypes)
+}
+
+// Test skipping string and JSON field values.
+func testResponseFieldTypes(t *testing.T) {
+	r := new(t38c.Response)
+
+	err := r.UnmarshalText([]byte(`{"ok":true,"id":"a","fields":{"name":"truck","speed":55,"meta":{"b":1}}}`))
+	if err != nil {
+		tFatalErr(t, "UnmarshalText", err)
+	}
+
+	expNames := map[string]int64{"speed": 0}
+	if !reflect.DeepEqual(r.FieldNames, expNames) {
+		tErrorStr(t, "FieldNames", fmt.Sprint(expNames), fmt.Sprint(r.FieldNames))
+	}
+
+	expValues := []float64{55}
+	if !reflect.DeepEqual(r.FieldValues, expValues) {
+		tErrorStr(t, "FieldValues", fmt.Sprint(expValues), fmt.Sprint(r.FieldValues))
+	}
 }
 
 func testResponseSingleJSON(t *testing.T) {
@@ -126,6 +219,10 @@ func testResponseSingle(t *testing.T, json []byte, obj string) {
 		tErrorVal(t, "TTL", expTTL, r.TTL)
 	}
 
+	if r.TTLDuration() != 500*time.Second {
+		tErrorVal(t, "TTLDuration", 500*time.Second, r.TTLDuration())
+	}
+
 	expElapsed := "100ms"
 	if r.Elapsed != expElapsed {
 		tErrorStr(t, "Elapsed", expElapsed, r.Elapsed)
@@ -226,6 +323,23 @@ func testResponseFence(t *testing.T) {
 		if !r.Ok && r.Command == "" {
 			t.Error("Ok not true and Command not populated")
 		}
+
+		if r.Command != "" && r.Time.Year() != 2018 {
+			tErrorVal(t, "Time", 2018, r.Time.Year())
+		}
+	}
+}
+
+func testResponseNoExpiry(t *testing.T) {
+	r := new(t38c.Response)
+
+	err := r.UnmarshalText([]byte(`{"ok":true,"ttl":-1,"elapsed":"1µs"}`))
+	if err != nil {
+		tFatalErr(t, "UnmarshalText", err)
+	}
+
+	if r.TTLDuration() != t38c.NoExpiry {
+		tErrorVal(t, "TTLDuration", t38c.NoExpiry, r.TTLDuration())
 	}
 }
 
@@ -248,3 +362,80 @@ func testResponseSrvErr(t *testing.T) {
 		tErrorStr(t, "Err", expErr, r.Err)
 	}
 }
+
+func testResponseNull(t *testing.T) {
+	json := []byte(`{"ok":true,"id":null,"ids":null,"fields":null,"count":null,"elapsed":"1µs"}`)
+
+	r := new(t38c.Response)
+
+	err := r.UnmarshalText(json)
+	if err != nil {
+		tFatalErr(t, "UnmarshalText", err)
+	}
+
+	exp := t38c.Response{Ok: true, Elapsed: "1µs"}
+	if !reflect.DeepEqual(*r, exp) {
+		tErrorStr(t, "Response", fmt.Sprint(exp), fmt.Sprint(*r))
+	}
+}
+
+func testResponseGetFormats(t *testing.T) {
+	tests := map[string]string{
+		`{"ok":true,"point":{"lat":33.5,"lon":-112.2},"elapsed":"1µs"}`:                        `{"lat":33.5,"lon":-112.2}`,
+		`{"ok":true,"bounds":{"sw":{"lat":1,"lon":2},"ne":{"lat":3,"lon":4}},"elapsed":"1µs"}`: `{"sw":{"lat":1,"lon":2},"ne":{"lat":3,"lon":4}}`,
+		`{"ok":true,"hash":"9tbnwg","elapsed":"1µs"}`:                                          `9tbnwg`,
+	}
+
+	for json, exp := range tests {
+		r := new(t38c.Response)
+
+		err := r.UnmarshalText([]byte(json))
+		if err != nil {
+			tFatalErr(t, "UnmarshalText", err)
+		}
+
+		if r.Object != exp {
+			tErrorStr(t, "Object", exp, r.Object)
+		}
+	}
+}
+
+// FuzzUnmarshalText checks that decoding arbitrary input either
+// succeeds or fails without changing the Response.
+func FuzzUnmarshalText(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add([]byte(s))
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r := new(t38c.Response)
+
+		err := r.UnmarshalText(b)
+		if err != nil && !reflect.DeepEqual(r, new(t38c.Response)) {
+			t.Errorf("%q: Response changed on error %v: %+v", b, err, *r)
+		}
+
+		if err == nil && !json.Valid(b) {
+			t.Errorf("%q: invalid JSON accepted", b)
+		}
+	})
+}
+
+// fuzzSeeds are the seed corpus for the Response fuzz targets.
+//
+//nolint:gochecknoglobals // shared between fuzz targets
+var fuzzSeeds = []string{
+	`{"ok":true,"object":{"type":"Point","coordinates":[0,0]},"fields":{"fY":999.999,"fZ":123},"ttl":500,"elapsed":"100ms"}`,
+	`{"ok":true,"fields":["fZ","fY"],"objects":[{"id":"value1","object":"x","fields":[123,999]}],"count":1,"cursor":0,"elapsed":"1µs"}`,
+	`{"ok":true,"ids":["value1","value2"],"keys":["fleet"],"count":2,"cursor":0}`,
+	`{"ok":true,"id":"a","fields":{"name":"truck","speed":55,"meta":{"b":1}}}`,
+	`{"ok":true,"points":[{"id":"a","point":{"lat":1,"lon":2}}],"hashes":[],"bounds":[]}`,
+	`{"command":"set","group":"g","detect":"enter","key":"test","time":"2018-08-27T19:07:23.578553343Z","id":"v"}`,
+	`{"ok":false,"err":"id not found","elapsed":"1µs"}`,
+	`{"command":"set","detect":"roam","hook":"h","id":"a","nearby":{"id":"b","meters":1},"meta":{"k":"v"}}`,
+	`{"ok":true,"result":{"a":[1,"b"]},"live":true,"ping":"pong"}`,
+	`{"ok":true,"id":null,"objects":["a\"]",1,null]}`,
+	`{"fields":true}`,
+	`["ok",true]`,
+	`{"ok":true,"object":`,
+}
@@ -0,0 +1,207 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Default values used by DefaultRetryPolicy.
+const (
+	defaultMaxAttempts = 3
+	defaultBaseDelay   = 50 * time.Millisecond
+	defaultMaxDelay    = time.Second
+)
+
+// idempotent lists the commands which are retried by default. Repeating
+// any of these commands leaves the database in the same state as
+// running it once.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var idempotent = map[string]bool{
+	"DEL":        true,
+	"EVALRO":     true,
+	"EVALROSHA":  true,
+	"EXPIRE":     true,
+	"GET":        true,
+	"INTERSECTS": true,
+	"KEYS":       true,
+	"NEARBY":     true,
+	"PDEL":       true,
+	"PERSIST":    true,
+	"PING":       true,
+	"SCAN":       true,
+	"SCRIPT":     true,
+	"SEARCH":     true,
+	"SET":        true,
+	"TEST":       true,
+	"TTL":        true,
+	"WITHIN":     true,
+}
+
+// RetryPolicy controls how commands are retried after a transient
+// failure such as a dropped connection or a server restart. Errors
+// reported by Tile38 itself are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a command,
+	// including the first. Values less than 2 disable retries.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. The delay doubles
+	// on each subsequent retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between any two attempts.
+	MaxDelay time.Duration
+
+	// AllCommands enables retries for commands which are not known to
+	// be idempotent.
+	AllCommands bool
+
+	// Retryable optionally replaces IsRetryable to decide whether an
+	// error is transient.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy making up to 3 attempts with
+// a delay starting at 50ms and capped at 1s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultMaxAttempts,
+		BaseDelay:   defaultBaseDelay,
+		MaxDelay:    defaultMaxDelay,
+	}
+}
+
+// WithRetry returns an Option enabling retries using the supplied
+// policy.
+func WithRetry(p RetryPolicy) Option {
+	return func(db *Database) {
+		db.retry = &p
+	}
+}
+
+// IsRetryable reports whether err is a transient network error which
+// may succeed if the command is repeated. Errors returned by Tile38,
+// such as a response with "ok" set to false, are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, errResponse) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var nerr net.Error
+
+	return errors.As(err, &nerr)
+}
+
+// allowed reports whether another attempt should be made after the
+// given attempt failed with err.
+func (p *RetryPolicy) allowed(cmd string, args []string, attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if !p.AllCommands && !isIdempotent(cmd, args) {
+		return false
+	}
+
+	return p.retryable(err)
+}
+
+// retryable reports whether err is transient, using p.Retryable if it
+// is set and IsRetryable otherwise.
+func (p *RetryPolicy) retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if p != nil && p.Retryable != nil {
+		return p.Retryable(err)
+	}
+
+	return IsRetryable(err)
+}
+
+// isIdempotent reports whether a command may safely be repeated. A SET
+// using NX or XX is not, since a repeated attempt could report that the
+// condition failed when the first attempt had written the object.
+func isIdempotent(cmd string, args []string) bool {
+	if !idempotent[cmd] {
+		return false
+	}
+
+	if cmd != "SET" || len(args) < 2 {
+		return true
+	}
+
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX", "XX":
+			return false
+		case "FIELD":
+			i += 2
+		case "EX":
+			i++
+		default:
+			return true
+		}
+	}
+
+	return true
+}
+
+// backoff returns the delay to wait after the given attempt. The delay
+// grows exponentially from BaseDelay up to MaxDelay, and the upper half
+// of it is randomized to avoid synchronized retries from many clients.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	half := d / 2 //nolint:mnd // split delay into fixed and random halves
+	if half <= 0 {
+		return d
+	}
+
+	return half + rand.N(d-half) //nolint:gosec // jitter does not need a secure source
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tidwall/resp"
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// TestRetry tests retrying commands after transient errors.
+func TestRetry(t *testing.T) {
+	t.Run("Recover", testRetryRecover)
+	t.Run("Exhausted", testRetryExhausted)
+	t.Run("Response Error", testRetryResponseErr)
+	t.Run("Custom Classifier", testRetryCustom)
+	t.Run("Conditional Set", testRetryConditional)
+	t.Run("Classify", testRetryClassify)
+}
+
+// retryPolicy is a short-delay policy used by the retry tests.
+func retryPolicy() t38c.RetryPolicy {
+	return t38c.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+	}
+}
+
+// dropFirst returns a handler which drops the connection for the first
+// n calls and then returns Ok:true, counting calls in calls.
+func dropFirst(n int64, calls *atomic.Int64) func(*resp.Conn, []resp.Value) bool {
+	return func(c *resp.Conn, args []resp.Value) bool {
+		if calls.Add(1) <= n {
+			return srv.ReturnDrop(c, args)
+		}
+
+		return srv.ReturnOkTrue(c, args)
+	}
+}
+
+// retryConnect connects to the mock server with the supplied policy.
+func retryConnect(t *testing.T, p t38c.RetryPolicy) *t38c.Database {
+	t.Helper()
+
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1, t38c.WithRetry(p))
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	return db
+}
+
+// Test a command succeeding after a dropped connection.
+func testRetryRecover(t *testing.T) {
+	var calls atomic.Int64
+
+	db := retryConnect(t, retryPolicy())
+	defer db.Close()
+
+	srv.HandleFunc("GET", dropFirst(1, &calls))
+
+	r, err := db.Get("test", "obj1")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	if r.Object != mock.TestObject {
+		tErrorStr(t, "Get", mock.TestObject, r.Object)
+	}
+
+	if calls.Load() != 2 {
+		tErrorVal(t, "Attempts", 2, calls.Load())
+	}
+}
+
+// Test a command failing after all attempts are used.
+func testRetryExhausted(t *testing.T) {
+	var calls atomic.Int64
+
+	db := retryConnect(t, retryPolicy())
+	defer db.Close()
+
+	srv.HandleFunc("TTL", dropFirst(5, &calls))
+
+	_, err := db.TTL("test", "obj1")
+	if err == nil {
+		tFatalNoErr(t, "TTL")
+	}
+
+	if !t38c.IsRetryable(err) {
+		tErrorVal(t, "IsRetryable", true, false)
+	}
+
+	if calls.Load() != 3 {
+		tErrorVal(t, "Attempts", 3, calls.Load())
+	}
+}
+
+// Test that errors reported by the server are not retried.
+func testRetryResponseErr(t *testing.T) {
+	var calls atomic.Int64
+
+	db := retryConnect(t, retryPolicy())
+	defer db.Close()
+
+	srv.HandleFunc("SCAN", func(c *resp.Conn, args []resp.Value) bool {
+		calls.Add(1)
+
+		return srv.ReturnOkFalse(c, args)
+	})
+
+	_, err := db.Scan("test")
+	if err == nil {
+		tFatalNoErr(t, "Scan")
+	}
+
+	if t38c.IsRetryable(err) {
+		tErrorVal(t, "IsRetryable", false, true)
+	}
+
+	if calls.Load() != 1 {
+		tErrorVal(t, "Attempts", 1, calls.Load())
+	}
+}
+
+// Test a custom Retryable function.
+func testRetryCustom(t *testing.T) {
+	var calls atomic.Int64
+
+	p := retryPolicy()
+	p.Retryable = func(err error) bool { return errors.Is(err, io.EOF) }
+
+	db := retryConnect(t, p)
+	defer db.Close()
+
+	srv.HandleFunc("GET", dropFirst(1, &calls))
+
+	_, err := db.Get("test", "obj1")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	if calls.Load() != 2 {
+		tErrorVal(t, "Attempts", 2, calls.Load())
+	}
+}
+
+// Test that SET with NX or XX is not retried.
+func testRetryConditional(t *testing.T) {
+	var calls atomic.Int64
+
+	db := retryConnect(t, retryPolicy())
+	defer db.Close()
+
+	srv.HandleFunc("SET", dropFirst(1, &calls))
+
+	_, err := db.SetObject("test", "obj1", t38c.String("x"), &t38c.SetOptions{
+		Fields: []t38c.Field{{Name: "NX", Value: 1}},
+		XX:     true,
+	})
+	if !t38c.IsRetryable(err) {
+		tErrorStr(t, "SetObject", "network error", err)
+	}
+
+	if calls.Load() != 1 {
+		tErrorVal(t, "Attempts", 1, calls.Load())
+	}
+
+	calls.Store(0)
+
+	_, err = db.SetObject("test", "obj1", t38c.String("NX"), &t38c.SetOptions{Expire: time.Second})
+	if err != nil {
+		tErrorStr(t, "SetObject", "nil", err)
+	}
+
+	if calls.Load() != 2 {
+		tErrorVal(t, "Attempts", 2, calls.Load())
+	}
+}
+
+// Test error classification.
+func testRetryClassify(t *testing.T) {
+	tests := map[string]struct {
+		err error
+		exp bool
+	}{
+		"nil":         {nil, false},
+		"EOF":         {io.EOF, true},
+		"Unexpected":  {io.ErrUnexpectedEOF, true},
+		"Other Error": {errors.New("other"), false},
+	}
+
+	for name, tc := range tests {
+		if t38c.IsRetryable(tc.err) != tc.exp {
+			tErrorVal(t, name, tc.exp, !tc.exp)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"crypto/sha1" //nolint:gosec // SHA1 identifies scripts, as in Tile38
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Eval runs a Lua script with the supplied keys and arguments.
+func (db *Database) Eval(script string, keys []string, args ...string) (r *Response, err error) {
+	return db.eval("EVAL", script, keys, args)
+}
+
+// EvalSHA runs a previously loaded Lua script identified by its SHA1
+// hash.
+func (db *Database) EvalSHA(sha string, keys []string, args ...string) (r *Response, err error) {
+	return db.eval("EVALSHA", sha, keys, args)
+}
+
+// EvalRO runs a Lua script which may only read from the database.
+func (db *Database) EvalRO(script string, keys []string, args ...string) (r *Response, err error) {
+	return db.eval("EVALRO", script, keys, args)
+}
+
+// EvalROSHA runs a previously loaded Lua script which may only read
+// from the database.
+func (db *Database) EvalROSHA(sha string, keys []string, args ...string) (r *Response, err error) {
+	return db.eval("EVALROSHA", sha, keys, args)
+}
+
+// EvalNA runs a Lua script without locking the database. The script
+// must not access the database.
+func (db *Database) EvalNA(script string, keys []string, args ...string) (r *Response, err error) {
+	return db.eval("EVALNA", script, keys, args)
+}
+
+// EvalNASHA runs a previously loaded Lua script without locking the
+// database.
+func (db *Database) EvalNASHA(sha string, keys []string, args ...string) (r *Response, err error) {
+	return db.eval("EVALNASHA", sha, keys, args)
+}
+
+// ScriptLoad loads a Lua script into the script cache and returns its
+// SHA1 hash.
+func (db *Database) ScriptLoad(script string) (sha string, err error) {
+	if db.tr == nil {
+		return "", errUninitialized
+	}
+
+	r, err := db.runcmd("SCRIPT", "LOAD", script)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.DecodeResult(&sha)
+	if err != nil {
+		return "", err
+	}
+
+	return sha, nil
+}
+
+// ScriptExists reports whether each of the supplied SHA1 hashes is in
+// the script cache.
+func (db *Database) ScriptExists(shas ...string) (exists []bool, err error) {
+	if db.tr == nil {
+		return nil, errUninitialized
+	}
+
+	if len(shas) == 0 {
+		return nil, errArgs
+	}
+
+	r, err := db.runcmd("SCRIPT", append([]string{"EXISTS"}, shas...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []int
+
+	err = r.DecodeResult(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	exists = make([]bool, len(res))
+	for i, v := range res {
+		exists[i] = v == 1
+	}
+
+	return exists, nil
+}
+
+// ScriptFlush removes all scripts from the script cache.
+func (db *Database) ScriptFlush() (err error) {
+	if db.tr == nil {
+		return errUninitialized
+	}
+
+	_, err = db.runcmd("SCRIPT", "FLUSH")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// eval runs one of the EVAL family of commands.
+func (db *Database) eval(cmd string, script string, keys []string, args []string) (r *Response, err error) {
+	if db.tr == nil {
+		return nil, errUninitialized
+	}
+
+	cmdargs := make([]string, 0, len(keys)+len(args)+2) //nolint:mnd // script and key count
+	cmdargs = append(cmdargs, script, strconv.Itoa(len(keys)))
+	cmdargs = append(cmdargs, keys...)
+	cmdargs = append(cmdargs, args...)
+
+	r, err = db.runcmd(cmd, cmdargs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Script is a Lua script which is run by its SHA1 hash, loading the
+// script only when the server does not already have it cached.
+type Script struct {
+	src string
+	sha string
+}
+
+// NewScript returns a Script for the supplied Lua source.
+func NewScript(src string) *Script {
+	sum := sha1.Sum([]byte(src)) //nolint:gosec // SHA1 identifies scripts, as in Tile38
+
+	return &Script{
+		src: src,
+		sha: hex.EncodeToString(sum[:]),
+	}
+}
+
+// SHA returns the SHA1 hash of the script.
+func (s *Script) SHA() string {
+	return s.sha
+}
+
+// Run runs the script with EVALSHA, falling back to EVAL if the script
+// is not cached by the server.
+func (s *Script) Run(db *Database, keys []string, args ...string) (r *Response, err error) {
+	r, err = db.EvalSHA(s.sha, keys, args...)
+	if isNoScript(err) {
+		return db.Eval(s.src, keys, args...)
+	}
+
+	return r, err
+}
+
+// RunRO runs the script as read-only with EVALROSHA, falling back to
+// EVALRO if the script is not cached by the server.
+func (s *Script) RunRO(db *Database, keys []string, args ...string) (r *Response, err error) {
+	r, err = db.EvalROSHA(s.sha, keys, args...)
+	if isNoScript(err) {
+		return db.EvalRO(s.src, keys, args...)
+	}
+
+	return r, err
+}
+
+// RunNA runs the script without locking the database with EVALNASHA,
+// falling back to EVALNA if the script is not cached by the server.
+func (s *Script) RunNA(db *Database, keys []string, args ...string) (r *Response, err error) {
+	r, err = db.EvalNASHA(s.sha, keys, args...)
+	if isNoScript(err) {
+		return db.EvalNA(s.src, keys, args...)
+	}
+
+	return r, err
+}
+
+// isNoScript reports whether err indicates an unknown script hash.
+func isNoScript(err error) bool {
+	if !errors.Is(err, errResponse) {
+		return false
+	}
+
+	msg := err.Error()
+
+	return strings.Contains(msg, "NOSCRIPT") || strings.Contains(msg, "sha not found")
+}
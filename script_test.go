@@ -0,0 +1,292 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/tidwall/resp"
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// testScript is the Lua source used by the scripting tests. Its SHA1
+// hash is testScriptSHA.
+const (
+	testScript    = "return 1"
+	testScriptSHA = "e0e1f9fabfc9d4800c877a703b823ac0578ff8db"
+)
+
+// TestScripting tests the scripting commands.
+func TestScripting(t *testing.T) {
+	srv.HandleFunc("OUTPUT", srv.ReturnOkTrue)
+
+	db, err := t38c.Connect(srv.Addr, srv.Port, 1)
+	if err != nil {
+		tFatalErr(t, "Connect", err)
+	}
+
+	defer db.Close()
+
+	t.Run("Eval", func(t *testing.T) { testScriptEval(t, db) })
+	t.Run("Script Commands", func(t *testing.T) { testScriptCommands(t, db) })
+	t.Run("Script Run", func(t *testing.T) { testScriptRun(t, db) })
+	t.Run("Errors", func(t *testing.T) { testScriptErrors(t, db) })
+}
+
+// Test the EVAL family of commands.
+func testScriptEval(t *testing.T, db *t38c.Database) {
+	tests := map[string]func() (*t38c.Response, error){
+		"EVAL return 1 1 fleet a": func() (*t38c.Response, error) {
+			return db.Eval(testScript, []string{"fleet"}, "a")
+		},
+		"EVALSHA abc 0 a b": func() (*t38c.Response, error) {
+			return db.EvalSHA("abc", nil, "a", "b")
+		},
+		"EVALRO return 1 2 k1 k2": func() (*t38c.Response, error) {
+			return db.EvalRO(testScript, []string{"k1", "k2"})
+		},
+		"EVALROSHA abc 0": func() (*t38c.Response, error) {
+			return db.EvalROSHA("abc", nil)
+		},
+		"EVALNA return 1 0": func() (*t38c.Response, error) {
+			return db.EvalNA(testScript, nil)
+		},
+		"EVALNASHA abc 0": func() (*t38c.Response, error) {
+			return db.EvalNASHA("abc", nil)
+		},
+	}
+
+	for exp, f := range tests {
+		srv.HandleFunc(string(bytes.Fields([]byte(exp))[0]), srv.ReturnResult)
+		srv.ResetCommands()
+
+		r, err := f()
+		if err != nil {
+			tErrorStr(t, exp, "nil", err)
+
+			continue
+		}
+
+		if r.Result != mock.TestResult {
+			tErrorStr(t, exp, mock.TestResult, r.Result)
+		}
+
+		tCommands(t, exp, exp)
+	}
+
+	srv.HandleFunc("EVAL", srv.ReturnResult)
+
+	r, err := db.Eval(testScript, nil)
+	if err != nil {
+		tFatalErr(t, "Eval", err)
+	}
+
+	var v struct {
+		Count int
+		IDs   []string
+	}
+
+	err = r.DecodeResult(&v)
+	if err != nil {
+		tFatalErr(t, "DecodeResult", err)
+	}
+
+	if v.Count != 2 || !reflect.DeepEqual(v.IDs, []string{"a", "b"}) {
+		tErrorVal(t, "DecodeResult", mock.TestResult, v)
+	}
+}
+
+// Test the SCRIPT commands.
+func testScriptCommands(t *testing.T, db *t38c.Database) {
+	srv.HandleFunc("SCRIPT", func(c *resp.Conn, args []resp.Value) bool {
+		res := map[string]string{
+			"LOAD":   `{"ok":true,"result":"` + testScriptSHA + `"}`,
+			"EXISTS": `{"ok":true,"result":[1,0]}`,
+			"FLUSH":  `{"ok":true}`,
+		}
+
+		return c.WriteSimpleString(res[args[1].String()]) == nil
+	})
+
+	sha, err := db.ScriptLoad(testScript)
+	if err != nil {
+		tErrorStr(t, "ScriptLoad", "nil", err)
+	} else if sha != testScriptSHA {
+		tErrorStr(t, "ScriptLoad", testScriptSHA, sha)
+	}
+
+	exists, err := db.ScriptExists(testScriptSHA, "abc")
+	if err != nil {
+		tErrorStr(t, "ScriptExists", "nil", err)
+	} else if !reflect.DeepEqual(exists, []bool{true, false}) {
+		tErrorVal(t, "ScriptExists", []bool{true, false}, exists)
+	}
+
+	err = db.ScriptFlush()
+	if err != nil {
+		tErrorStr(t, "ScriptFlush", "nil", err)
+	}
+}
+
+// Test running a Script with and without the script cached.
+func testScriptRun(t *testing.T, db *t38c.Database) {
+	s := t38c.NewScript(testScript)
+
+	if s.SHA() != testScriptSHA {
+		tErrorStr(t, "SHA", testScriptSHA, s.SHA())
+	}
+
+	tests := map[string]func() (*t38c.Response, error){
+		"EVAL": func() (*t38c.Response, error) {
+			return s.Run(db, []string{"fleet"}, "a")
+		},
+		"EVALRO": func() (*t38c.Response, error) {
+			return s.RunRO(db, []string{"fleet"}, "a")
+		},
+		"EVALNA": func() (*t38c.Response, error) {
+			return s.RunNA(db, []string{"fleet"}, "a")
+		},
+	}
+
+	for cmd, f := range tests {
+		srv.HandleFunc(cmd+"SHA", srv.ReturnResult)
+		srv.ResetCommands()
+
+		_, err := f()
+		if err != nil {
+			tErrorStr(t, cmd, "nil", err)
+		}
+
+		exp := cmd + "SHA " + testScriptSHA + " 1 fleet a"
+		tCommands(t, cmd, exp)
+
+		srv.HandleFunc(cmd+"SHA", srv.ReturnNoScript)
+		srv.HandleFunc(cmd, srv.ReturnResult)
+		srv.ResetCommands()
+
+		r, err := f()
+		if err != nil {
+			tErrorStr(t, cmd, "nil", err)
+		} else if r.Result != mock.TestResult {
+			tErrorStr(t, cmd, mock.TestResult, r.Result)
+		}
+
+		tCommands(t, cmd, exp, cmd+" "+testScript+" 1 fleet a")
+	}
+
+	srv.HandleFunc("EVALSHA", srv.ReturnOkFalse)
+
+	_, err := s.Run(db, nil)
+	if err == nil || err.Error() != "received error: "+mock.TestOkFalse {
+		tErrorStr(t, "Run", "received error: "+mock.TestOkFalse, err)
+	}
+}
+
+// Test scripting errors.
+func testScriptErrors(t *testing.T, db *t38c.Database) {
+	srv.HandleFunc("EVAL", srv.ReturnOkTrue)
+
+	r, err := db.Eval(testScript, nil)
+	if err != nil {
+		tFatalErr(t, "Eval", err)
+	}
+
+	var v any
+
+	err = r.DecodeResult(&v)
+	if err == nil || err.Error() != "error decoding result: no result" {
+		tErrorStr(t, "DecodeResult", "error decoding result: no result", err)
+	}
+
+	r.Result = "{"
+
+	err = r.DecodeResult(&v)
+	if err == nil {
+		tErrorStr(t, "DecodeResult", "error", "nil")
+	}
+
+	srv.HandleFunc("SCRIPT", srv.ReturnOkFalse)
+	srv.HandleFunc("EVALSHA", srv.ReturnOkFalse)
+
+	_, err = db.ScriptLoad(testScript)
+	if err == nil {
+		tErrorStr(t, "ScriptLoad", "error", "nil")
+	}
+
+	_, err = db.ScriptExists(testScriptSHA)
+	if err == nil {
+		tErrorStr(t, "ScriptExists", "error", "nil")
+	}
+
+	_, err = db.ScriptExists()
+	if err == nil || err.Error() != "invalid arguments" {
+		tErrorStr(t, "ScriptExists", "invalid arguments", err)
+	}
+
+	err = db.ScriptFlush()
+	if err == nil {
+		tErrorStr(t, "ScriptFlush", "error", "nil")
+	}
+
+	_, err = db.EvalSHA(testScriptSHA, nil)
+	if err == nil {
+		tErrorStr(t, "EvalSHA", "error", "nil")
+	}
+
+	srv.HandleFunc("SCRIPT", srv.ReturnOkTrue)
+
+	_, err = db.ScriptLoad(testScript)
+	if err == nil {
+		tErrorStr(t, "ScriptLoad", "error", "nil")
+	}
+
+	_, err = db.ScriptExists(testScriptSHA)
+	if err == nil {
+		tErrorStr(t, "ScriptExists", "error", "nil")
+	}
+
+	udb := new(t38c.Database)
+
+	_, err = udb.Eval(testScript, nil)
+	if err == nil || err.Error() != "database not initialized" {
+		tErrorStr(t, "Eval", "database not initialized", err)
+	}
+
+	_, err = udb.ScriptLoad(testScript)
+	if err == nil || err.Error() != "database not initialized" {
+		tErrorStr(t, "ScriptLoad", "database not initialized", err)
+	}
+
+	_, err = udb.ScriptExists(testScriptSHA)
+	if err == nil || err.Error() != "database not initialized" {
+		tErrorStr(t, "ScriptExists", "database not initialized", err)
+	}
+
+	err = udb.ScriptFlush()
+	if err == nil || err.Error() != "database not initialized" {
+		tErrorStr(t, "ScriptFlush", "database not initialized", err)
+	}
+}
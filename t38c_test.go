@@ -22,7 +22,11 @@
 
 package t38c_test
 
-import "testing"
+import (
+	"slices"
+	"strings"
+	"testing"
+)
 
 func tFatalErr(t *testing.T, desc string, err error) {
 	t.Helper()
@@ -43,3 +47,18 @@ func tErrorVal(t *testing.T, desc string, exp any, act any) {
 	t.Helper()
 	t.Errorf("%s - expected: %v | received: %v", desc, exp, act)
 }
+
+func tCommands(t *testing.T, desc string, exp ...string) {
+	t.Helper()
+
+	cmds := srv.Commands()
+
+	act := make([]string, len(cmds))
+	for i, c := range cmds {
+		act[i] = strings.Join(c.Args, " ")
+	}
+
+	if !slices.Equal(exp, act) {
+		t.Errorf("%s - expected: %q | received: %q", desc, exp, act)
+	}
+}
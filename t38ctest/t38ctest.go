@@ -0,0 +1,117 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package t38ctest provides an in-memory Tile38 server for testing code
+// that uses t38c.
+//
+// Each Server listens on its own ephemeral port, so tests in different
+// packages, or parallel tests in the same package, do not interfere
+// with each other.
+package t38ctest
+
+import (
+	"testing"
+	"time"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/internal/mock"
+)
+
+// poolSize is the connection pool size used by Connect.
+const poolSize = 4
+
+// Server is an in-memory Tile38 server for testing.
+type Server struct {
+	Addr string
+	Port string
+
+	srv *mock.Server
+	emu *mock.Emulator
+}
+
+// NewServer starts a new Server on an ephemeral port. The server is
+// ready to accept connections when NewServer returns and is closed when
+// the test and all its subtests complete.
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+
+	srv := mock.NewServer()
+	if srv.Err != nil {
+		tb.Fatalf("t38ctest: starting server: %v", srv.Err)
+	}
+
+	s := &Server{
+		Addr: srv.Addr,
+		Port: srv.Port,
+		srv:  srv,
+		emu:  srv.Emulate(),
+	}
+
+	tb.Cleanup(func() {
+		s.Close()
+	})
+
+	return s
+}
+
+// NewDatabase starts a new Server and returns a Database connected to
+// it. Both are closed when the test and all its subtests complete.
+func NewDatabase(tb testing.TB, opts ...t38c.Option) *t38c.Database {
+	tb.Helper()
+
+	return NewServer(tb).Connect(tb, opts...)
+}
+
+// Connect returns a Database connected to the server, which is closed
+// when the test and all its subtests complete.
+func (s *Server) Connect(tb testing.TB, opts ...t38c.Option) *t38c.Database {
+	tb.Helper()
+
+	db, err := t38c.Connect(s.Addr, s.Port, poolSize, opts...)
+	if err != nil {
+		tb.Fatalf("t38ctest: connecting to server: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+// Do runs a command directly against the server's data, bypassing the
+// network, and returns the JSON response. It is useful for seeding data
+// and inspecting state from a test.
+func (s *Server) Do(args ...string) string {
+	return s.emu.Do(args...)
+}
+
+// SetClock replaces the function used by the server to obtain the
+// current time, allowing tests to control expirations.
+func (s *Server) SetClock(now func() time.Time) {
+	s.emu.SetClock(now)
+}
+
+// Close stops the server and closes all open connections.
+func (s *Server) Close() error {
+	return s.srv.Close()
+}
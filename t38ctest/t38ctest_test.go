@@ -0,0 +1,205 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38ctest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/t38c"
+	"kreklow.us/go/t38c/t38ctest"
+)
+
+// TestNewDatabase tests read-after-write through a connected Database.
+func TestNewDatabase(t *testing.T) {
+	db := t38ctest.NewDatabase(t)
+
+	err := db.Set("fleet", "truck1", "POINT", "33.5123", "-112.2693")
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	err = db.Set("fleet", "truck2", "POINT", "33.4626", "-112.1695")
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	r, err := db.Get("fleet", "truck1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	exp := `{"type":"Point","coordinates":[-112.2693,33.5123]}`
+	if r.Object != exp {
+		t.Errorf("Get: expected %s, received %s", exp, r.Object)
+	}
+
+	err = db.Del("fleet", "truck1")
+	if err != nil {
+		t.Fatalf("Del: %v", err)
+	}
+
+	r, err = db.Get("fleet", "truck1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	if r != nil {
+		t.Errorf("Get: expected nil, received %v", r.Object)
+	}
+}
+
+// TestHTTP tests read-after-write over the HTTP transport.
+func TestHTTP(t *testing.T) {
+	db := t38ctest.NewDatabase(t, t38c.WithHTTP(nil))
+
+	err := db.Set("fleet", "truck 1", "FIELD", "speed", "90", "POINT", "33.5123", "-112.2693")
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	r, err := db.Scan("fleet", "WHERE", "speed", "50", "100", "IDS")
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	if len(r.IDs) != 1 || r.IDs[0] != "truck 1" {
+		t.Errorf("Scan: expected [truck 1], received %v", r.IDs)
+	}
+}
+
+// TestTimeout tests queries with a server-side timeout.
+func TestTimeout(t *testing.T) {
+	db := t38ctest.NewDatabase(t)
+
+	err := db.Set("fleet", "truck1", "POINT", "33.5123", "-112.2693")
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	r, err := db.WithinQuery("fleet", t38c.NewQuery().Timeout(time.Second).Output(t38c.OutputIDs),
+		"BOUNDS", "33", "-113", "34", "-112")
+	if err != nil {
+		t.Fatalf("WithinQuery: %v", err)
+	}
+
+	if len(r.IDs) != 1 || r.IDs[0] != "truck1" {
+		t.Errorf("WithinQuery: expected [truck1], received %v", r.IDs)
+	}
+}
+
+// TestParallel tests that parallel servers do not share ports or data.
+func TestParallel(t *testing.T) {
+	ports := make(chan string, 4)
+
+	t.Run("group", func(t *testing.T) {
+		for i := range 4 {
+			t.Run(fmt.Sprint(i), func(t *testing.T) {
+				t.Parallel()
+
+				srv := t38ctest.NewServer(t)
+				ports <- srv.Port
+
+				db := srv.Connect(t)
+
+				err := db.Set("fleet", fmt.Sprint(i), "STRING", "x")
+				if err != nil {
+					t.Fatalf("Set: %v", err)
+				}
+
+				r, err := db.Scan("fleet", "IDS")
+				if err != nil {
+					t.Fatalf("Scan: %v", err)
+				}
+
+				if len(r.IDs) != 1 || r.IDs[0] != fmt.Sprint(i) {
+					t.Errorf("Scan: expected [%d], received %v", i, r.IDs)
+				}
+			})
+		}
+	})
+
+	close(ports)
+
+	seen := make(map[string]bool)
+
+	for p := range ports {
+		if seen[p] {
+			t.Errorf("port %s used by more than one server", p)
+		}
+
+		seen[p] = true
+	}
+}
+
+// TestDoAndClock tests seeding data and controlling expirations.
+func TestDoAndClock(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	srv := t38ctest.NewServer(t)
+	srv.SetClock(func() time.Time { return now })
+
+	out := srv.Do("SET", "fleet", "truck1", "EX", "10", "POINT", "33", "-112")
+	if !strings.Contains(out, `"ok":true`) {
+		t.Fatalf("Do: unexpected response %s", out)
+	}
+
+	db := srv.Connect(t)
+
+	ttl, err := db.TTL("fleet", "truck1")
+	if err != nil {
+		t.Fatalf("TTL: %v", err)
+	}
+
+	if ttl != 10 {
+		t.Errorf("TTL: expected 10, received %v", ttl)
+	}
+
+	srv.SetClock(func() time.Time { return now.Add(11 * time.Second) })
+
+	r, err := db.Get("fleet", "truck1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	if r != nil {
+		t.Errorf("Get: expected nil, received %v", r.Object)
+	}
+}
+
+// TestClose tests that a closed server refuses connections.
+func TestClose(t *testing.T) {
+	srv := t38ctest.NewServer(t)
+
+	err := srv.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	_, err = t38c.Connect(srv.Addr, srv.Port, 1)
+	if err == nil {
+		t.Fatal("Connect: expected error, received nil")
+	}
+}
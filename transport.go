@@ -0,0 +1,252 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/mediocregopher/radix/v3"
+	"github.com/mediocregopher/radix/v3/resp"
+	"github.com/mediocregopher/radix/v3/resp/resp2"
+)
+
+// Transport sends commands to the database and decodes the replies.
+// Implementations must be safe for concurrent use. By default, Connect
+// uses a Transport backed by a pool of RESP connections; NewDatabase
+// accepts any other implementation.
+type Transport interface {
+	// Do sends a command and unmarshals the JSON reply into r. If r is
+	// nil, the reply is discarded.
+	Do(r *Response, cmd string, args ...string) error
+
+	// Close releases the resources held by the Transport.
+	Close() error
+}
+
+// Streamer is implemented by Transports which can decode a reply as it
+// is read, calling fn with each element of the objects array instead of
+// collecting them in r.Objects. Replies from Transports which do not
+// implement Streamer are decoded in full first.
+type Streamer interface {
+	Stream(r *Response, fn ObjectFunc, cmd string, args ...string) error
+}
+
+// TransportFunc is a function implementing Transport, useful for
+// stubbing the database in tests. Its Close method does nothing.
+type TransportFunc func(r *Response, cmd string, args ...string) error
+
+// Do calls f(r, cmd, args...).
+func (f TransportFunc) Do(r *Response, cmd string, args ...string) error {
+	return f(r, cmd, args...)
+}
+
+// Close does nothing and returns nil.
+func (f TransportFunc) Close() error {
+	return nil
+}
+
+// poolTransport is a Transport using a pool of RESP connections.
+type poolTransport struct {
+	pool *radix.Pool
+}
+
+// newPoolTransport returns a Transport using a pool of RESP connections
+// to addr, each created by db.connectJSON.
+func newPoolTransport(db *Database, addr string, size int) (*poolTransport, error) {
+	pool, err := radix.NewPool("tcp", addr, size, radix.PoolConnFunc(db.connectJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	return &poolTransport{pool: pool}, nil
+}
+
+// Do sends a command using a connection from the pool.
+func (t *poolTransport) Do(r *Response, cmd string, args ...string) error {
+	if r == nil {
+		return t.pool.Do(radix.Cmd(nil, cmd, args...))
+	}
+
+	return t.pool.Do(radix.Cmd(r, cmd, args...))
+}
+
+// Stream sends a command using a connection from the pool, decoding the
+// reply as it is read.
+func (t *poolTransport) Stream(r *Response, fn ObjectFunc, cmd string, args ...string) error {
+	return t.pool.Do(radix.Cmd(&streamReply{r: r, fn: fn}, cmd, args...))
+}
+
+// Pipeline sends several commands using a single connection from the
+// pool.
+func (t *poolTransport) Pipeline(rs []*Response, cmds [][]string) error {
+	actions := make([]radix.CmdAction, len(cmds))
+	for i, c := range cmds {
+		actions[i] = radix.Cmd(rs[i], c[0], c[1:]...)
+	}
+
+	return t.pool.Do(radix.Pipeline(actions...))
+}
+
+// Close closes all connections in the pool.
+func (t *poolTransport) Close() error {
+	return t.pool.Close()
+}
+
+// streamReply decodes a RESP reply holding a JSON response as it is
+// read from the connection.
+type streamReply struct {
+	r  *Response
+	fn ObjectFunc
+}
+
+// UnmarshalRESP implements resp.Unmarshaler. JSON responses in bulk
+// strings are decoded directly from the connection, and simple strings
+// once read; any other reply is decoded in full. The reply is consumed
+// before returning, so decoding errors leave the connection usable.
+func (s *streamReply) UnmarshalRESP(br *bufio.Reader) error {
+	b, err := br.Peek(1)
+	if err != nil {
+		return err
+	}
+
+	switch b[0] {
+	case '$':
+		return s.bulk(br)
+	case '+':
+		line, err := br.ReadSlice('\n')
+		if err != nil {
+			return err
+		}
+
+		return discarded(decodeStream(bytes.NewReader(bytes.TrimSuffix(line[1:], []byte("\r\n"))), s.r, s.fn))
+	default:
+		err = resp2.Any{I: s.r}.UnmarshalRESP(br)
+		if err != nil {
+			return err
+		}
+
+		return discarded(streamObjects(s.r, s.fn))
+	}
+}
+
+// bulk decodes a bulk string reply.
+func (s *streamReply) bulk(br *bufio.Reader) error {
+	line, err := br.ReadSlice('\n')
+	if err != nil {
+		return err
+	}
+
+	n, err := strconv.ParseInt(string(bytes.TrimSpace(line[1:])), 10, 64)
+	if err != nil {
+		return newError(err, "invalid bulk string length")
+	}
+
+	if n < 0 {
+		return nil
+	}
+
+	lr := io.LimitReader(br, n)
+	derr := decodeStream(lr, s.r, s.fn)
+
+	_, err = io.Copy(io.Discard, lr)
+	if err == nil {
+		_, err = br.Discard(2) //nolint:mnd // trailing CRLF
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return discarded(derr)
+}
+
+// discarded wraps an error decoding a reply which has been consumed, so
+// that the connection is returned to the pool.
+func discarded(err error) error {
+	if err != nil {
+		return resp.ErrDiscarded{Err: err}
+	}
+
+	return nil
+}
+
+// WithPassword returns an Option which authenticates each RESP
+// connection with AUTH password before sending any other command. It
+// has no effect with WithHTTP or WithHTTPS.
+func WithPassword(password string) Option {
+	return func(db *Database) {
+		db.password = password
+	}
+}
+
+// connectJSON creates a connection, authenticates it if a password is
+// set and sets the output mode to JSON.
+func (db *Database) connectJSON(net, addr string) (conn radix.Conn, err error) { //nolint:ireturn // radix.Conn is passed through
+	conn, err = radix.Dial(net, addr)
+	if err != nil {
+		db.logDial(addr, err)
+
+		return nil, newError(err, "error connecting to database")
+	}
+
+	if db.password != "" {
+		err = handshake(conn, "error authenticating", "AUTH", db.password)
+	}
+
+	if err == nil {
+		err = handshake(conn, "error setting output to JSON", "OUTPUT", "json")
+	}
+
+	if err != nil {
+		conn.Close() //nolint:errcheck // Close() in error path
+
+		db.logDial(addr, err)
+
+		return nil, err
+	}
+
+	db.logDial(addr, nil)
+
+	return conn, nil
+}
+
+// handshake sends a command while setting up a connection, returning
+// an error if it fails or the server does not reply ok.
+func handshake(conn radix.Conn, msg string, cmd string, args ...string) error {
+	resp := new(Response)
+
+	err := conn.Do(radix.Cmd(resp, cmd, args...))
+	if err != nil {
+		return newError(err, msg)
+	}
+
+	if !resp.Ok {
+		return fmt.Errorf("%w: %s", errResponse, resp.Err)
+	}
+
+	return nil
+}
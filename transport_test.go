@@ -0,0 +1,83 @@
+// Copyright 2026 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package t38c_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/t38c"
+)
+
+// TestTransport tests a Database using a stub Transport.
+func TestTransport(t *testing.T) {
+	var cmds []string
+
+	stub := t38c.TransportFunc(func(r *t38c.Response, cmd string, args ...string) error {
+		cmds = append(cmds, cmd+" "+strings.Join(args, " "))
+
+		switch {
+		case len(cmds) == 1:
+			return io.EOF
+		case cmd == "GET":
+			return r.UnmarshalText([]byte(`{"ok":true,"object":"x"}`))
+		default:
+			return r.UnmarshalText([]byte(`{"ok":false,"err":"id not found"}`))
+		}
+	})
+
+	db, err := t38c.NewDatabase(stub, t38c.WithRetry(retryPolicy()))
+	if err != nil {
+		tFatalErr(t, "NewDatabase", err)
+	}
+
+	r, err := db.Get("test", "obj1")
+	if err != nil {
+		tFatalErr(t, "Get", err)
+	}
+
+	if r.Object != "x" {
+		tErrorStr(t, "Get", "x", r.Object)
+	}
+
+	err = db.Del("test", "obj2")
+	if err == nil || err.Error() != "received error: id not found" {
+		tErrorStr(t, "Del", "received error: id not found", err)
+	}
+
+	exp := []string{"GET test obj1", "GET test obj1", "DEL test obj2"}
+	if strings.Join(cmds, "|") != strings.Join(exp, "|") {
+		tErrorVal(t, "Commands", exp, cmds)
+	}
+
+	err = db.Close()
+	if err != nil {
+		tFatalErr(t, "Close", err)
+	}
+
+	_, err = t38c.NewDatabase(nil)
+	if err == nil || err.Error() != "invalid arguments" {
+		tErrorStr(t, "NewDatabase", "invalid arguments", err)
+	}
+}